@@ -42,7 +42,7 @@ func main() {
 
 	log.Info("Starting application", slog.Any("config", cfg))
 
-	application := app.New(log, cfg.GRPC.Port, cfg.PostgresUrl, cfg.TokenTTL)
+	application := app.New(log, cfg.GRPC.Port, cfg.PostgresUrl, cfg.TokenTTL, cfg.RefreshTokenTTL, cfg.KeyRotationPeriod)
 	go application.GRPCApp.MustRun()
 
 	// Init services
@@ -7,6 +7,7 @@ import (
 
 	grpcapp "github.com/coddmeistr/quizzify/backend/sso/internal/app/grpc"
 	"github.com/coddmeistr/quizzify/backend/sso/internal/services/auth"
+	"github.com/coddmeistr/quizzify/backend/sso/internal/services/keys"
 	"github.com/coddmeistr/quizzify/backend/sso/internal/services/permissions"
 	"github.com/coddmeistr/quizzify/backend/sso/internal/storage/postgres"
 )
@@ -15,7 +16,7 @@ type App struct {
 	GRPCApp *grpcapp.App
 }
 
-func New(log *slog.Logger, grpcPort int, postgresURL string, tokenTTL time.Duration) *App {
+func New(log *slog.Logger, grpcPort int, postgresURL string, tokenTTL time.Duration, refreshTokenTTL time.Duration, keyRotationPeriod time.Duration) *App {
 
 	// Init storage
 	storage, err := postgres.New(postgresURL)
@@ -23,14 +24,17 @@ func New(log *slog.Logger, grpcPort int, postgresURL string, tokenTTL time.Durat
 		panic(err)
 	}
 
+	// Init signing keys service
+	keysSrv := keys.New(log, storage, keyRotationPeriod, tokenTTL)
+
 	// Init auth service
-	authSrv := auth.New(log, storage, storage, storage, storage, tokenTTL)
+	authSrv := auth.New(log, storage, storage, storage, storage, keysSrv, storage, tokenTTL, refreshTokenTTL)
 
 	// Init permissions service
 	permSrv := permissions.New(log, storage)
 
 	// Init gRPC app
-	grpcApp := grpcapp.New(log, authSrv, permSrv, grpcPort, storage)
+	grpcApp := grpcapp.New(log, authSrv, permSrv, keysSrv, grpcPort, storage)
 
 	return &App{
 		GRPCApp: grpcApp,
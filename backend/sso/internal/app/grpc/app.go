@@ -2,6 +2,7 @@ package grpcapp
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/coddmeistr/quizzify/backend/sso/internal/storage/postgres"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
@@ -14,26 +15,32 @@ import (
 	"log/slog"
 
 	authgrpc "github.com/coddmeistr/quizzify/backend/sso/internal/grpc/auth"
+	authzgrpc "github.com/coddmeistr/quizzify/backend/sso/internal/grpc/authz"
 	permissionsgrpc "github.com/coddmeistr/quizzify/backend/sso/internal/grpc/permissions"
 	"github.com/coddmeistr/quizzify/backend/sso/internal/services/auth"
+	"github.com/coddmeistr/quizzify/backend/sso/internal/services/keys"
 	"github.com/coddmeistr/quizzify/backend/sso/internal/services/permissions"
 	"google.golang.org/grpc"
 
 	gw "github.com/coddmeistr/quizzify/backend/protos/proto/sso"
 )
 
-const gatewayPort = ":8001"
+const (
+	gatewayPort = ":8001"
+	jwksPath    = "/sso/.well-known/jwks.json"
+)
 
 type App struct {
 	log        *slog.Logger
 	authSrv    *auth.Auth
 	permSrv    *permissions.Permissions
+	keysSrv    *keys.Keys
 	gRPCServer *grpc.Server
 	port       int
 }
 
 // REST Gateway
-func run(log *slog.Logger, grpcAddr string) error {
+func run(log *slog.Logger, grpcAddr string, keysSrv *keys.Keys) error {
 	ctx := context.Background()
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -51,22 +58,48 @@ func run(log *slog.Logger, grpcAddr string) error {
 		return err
 	}
 
+	// Public keys for services verifying tokens without the app secret
+	err = mux.HandlePath(http.MethodGet, jwksPath, jwksHandler(log, keysSrv))
+	if err != nil {
+		return err
+	}
+
 	// Start HTTP server (and proxy calls to gRPC server endpoint)
 	log.Info("gRPC Gateway is listening on port " + gatewayPort)
 	handler := cors.AllowAll().Handler(mux)
 	return http.ListenAndServe(gatewayPort, handler)
 }
 
-func New(log *slog.Logger, auth *auth.Auth, perm *permissions.Permissions, port int, appProv *postgres.Storage) *App {
-	gRPCServer := grpc.NewServer()
+func jwksHandler(log *slog.Logger, keysSrv *keys.Keys) runtime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		jwks, err := keysSrv.JWKS(r.Context())
+		if err != nil {
+			log.Error("failed getting jwks", slog.String("error", err.Error()))
+			http.Error(w, "Internal error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		if err := json.NewEncoder(w).Encode(jwks); err != nil {
+			log.Error("failed writing jwks", slog.String("error", err.Error()))
+		}
+	}
+}
+
+func New(log *slog.Logger, auth *auth.Auth, perm *permissions.Permissions, keysSrv *keys.Keys, port int, appProv *postgres.Storage) *App {
+	gRPCServer := grpc.NewServer(
+		grpc.UnaryInterceptor(authzgrpc.UnaryInterceptor(auth, appProv, authzgrpc.Rules)),
+	)
 
-	authgrpc.Register(gRPCServer, auth, appProv)
+	authgrpc.Register(gRPCServer, auth)
 	permissionsgrpc.Register(gRPCServer, perm)
 
 	return &App{
 		log:        log,
 		authSrv:    auth,
 		permSrv:    perm,
+		keysSrv:    keysSrv,
 		gRPCServer: gRPCServer,
 		port:       port,
 	}
@@ -89,7 +122,7 @@ func (a *App) Run() error {
 
 	log.Info("Starting gRPC gateway")
 	go func() {
-		if err := run(log, l.Addr().String()); err != nil {
+		if err := run(log, l.Addr().String(), a.keysSrv); err != nil {
 			log.Error("FATAL: gRPC gateway error", slog.String("error", err.Error()))
 			os.Exit(1)
 		}
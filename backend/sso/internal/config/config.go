@@ -13,6 +13,10 @@ type Config struct {
 	PostgresUrl string        `env:"POSTGRES_URL"`
 	TokenTTL    time.Duration `yaml:"token_ttl" env-default:"1h"`
 	GRPC        GRPCConfig    `yaml:"grpc"`
+
+	RefreshTokenTTL   time.Duration `yaml:"refresh_token_ttl" env-default:"720h"`   // Lifetime of the session without refreshing
+	KeyRotationPeriod time.Duration `yaml:"key_rotation_period" env-default:"720h"` // Age of the signing key after which it's replaced
+
 }
 
 type GRPCConfig struct {
@@ -1,7 +1,8 @@
 package models
 
 type App struct {
-	ID     uint64
-	Name   string
-	Secret string
+	ID         uint64
+	Name       string
+	Secret     string
+	SigningAlg string // HS256 signs tokens with the Secret, RS256 and EdDSA with the app's SigningKey
 }
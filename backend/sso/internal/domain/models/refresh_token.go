@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// RefreshToken is the stored refresh token of the user's session
+// Only hash of the token is stored, token itself is given to the user once
+// Each refresh replaces the token with the new one of the same family, so family is the session
+type RefreshToken struct {
+	Hash      []byte
+	UserID    uint64
+	AppID     uint64
+	FamilyID  string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+}
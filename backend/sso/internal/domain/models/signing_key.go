@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// SigningKey is the asymmetric key the app's tokens are signed with
+// App has one active key at a time, retired keys are kept to verify tokens issued before rotation
+type SigningKey struct {
+	ID         string // Put into the kid header of the token
+	AppID      uint64
+	Alg        string
+	PrivateKey []byte // PKCS #8, DER encoded
+	PublicKey  []byte // PKIX, DER encoded
+	CreatedAt  time.Time
+	RetiredAt  *time.Time
+}
@@ -3,10 +3,10 @@ package authgrpc
 import (
 	"context"
 	"errors"
-	"fmt"
 	"github.com/coddmeistr/quizzify/backend/sso/internal/domain/models"
+	authzgrpc "github.com/coddmeistr/quizzify/backend/sso/internal/grpc/authz"
+	appjwt "github.com/coddmeistr/quizzify/backend/sso/internal/lib/jwt"
 	"github.com/coddmeistr/quizzify/backend/sso/internal/storage"
-	"github.com/golang-jwt/jwt/v5"
 
 	ssov1 "github.com/coddmeistr/quizzify/backend/protos/proto/sso"
 	"github.com/coddmeistr/quizzify/backend/sso/internal/services/auth"
@@ -16,16 +16,16 @@ import (
 )
 
 type Auth interface {
-	Login(ctx context.Context, login string, email string, password string, appID int) (token string, err error)
+	Login(ctx context.Context, login string, email string, password string, appID int) (token string, refreshToken string, err error)
+	Refresh(ctx context.Context, refreshToken string) (token string, newRefreshToken string, err error)
+	Logout(ctx context.Context, refreshToken string) error
+	RevokeAllSessions(ctx context.Context, refreshToken string) error
 	Register(ctx context.Context, login string, email string, password string) (userID uint64, err error)
 	IsAdmin(ctx context.Context, userID uint64) (bool, error)
 	UserInfo(ctx context.Context, userID uint64) (models.User, []int, error)
 	DeleteAccount(ctx context.Context, userID uint64) error
 	AccountsList(ctx context.Context) ([]models.User, error)
-}
-
-type AppProvider interface {
-	App(ctx context.Context, appID int) (models.App, error)
+	VerifyToken(ctx context.Context, token string) (appjwt.Claims, error)
 }
 
 const (
@@ -34,12 +34,11 @@ const (
 
 type serverAPI struct {
 	ssov1.UnimplementedAuthServer
-	auth        Auth
-	appProvider AppProvider
+	auth Auth
 }
 
-func Register(gRPC *grpc.Server, auth Auth, appProvider AppProvider) {
-	ssov1.RegisterAuthServer(gRPC, &serverAPI{auth: auth, appProvider: appProvider})
+func Register(gRPC *grpc.Server, auth Auth) {
+	ssov1.RegisterAuthServer(gRPC, &serverAPI{auth: auth})
 }
 
 func (s *serverAPI) DeleteAccount(ctx context.Context, req *ssov1.DeleteAccountRequest) (*ssov1.DeleteAccountResponse, error) {
@@ -54,6 +53,11 @@ func (s *serverAPI) DeleteAccount(ctx context.Context, req *ssov1.DeleteAccountR
 }
 
 func (s *serverAPI) ListAccounts(ctx context.Context, req *ssov1.ListAccountsRequest) (*ssov1.ListAccountsResponse, error) {
+	// Accounts are listed for the app of the caller's token
+	caller, ok := authzgrpc.CallerFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "caller is not authenticated")
+	}
 
 	users, err := s.auth.AccountsList(ctx)
 	if err != nil {
@@ -66,17 +70,17 @@ func (s *serverAPI) ListAccounts(ctx context.Context, req *ssov1.ListAccountsReq
 		if err != nil {
 			return nil, status.Error(codes.Internal, err.Error())
 		}
-		pbPerms := make([]int32, 0)
-		for _, p := range perms {
-			pbPerms = append(pbPerms, int32(p))
+		isAdmin, err := s.auth.IsAdmin(ctx, user.ID)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
 		}
 		accounts = append(accounts, &ssov1.AccountInfoResponse{
 			UserId:      int64(user.ID),
 			Login:       user.Login,
 			Email:       user.Email,
-			IsAdmin:     false,
-			Permissions: pbPerms,
-			AppId:       int32(1),
+			IsAdmin:     isAdmin,
+			Permissions: toPbPermissions(perms),
+			AppId:       int32(caller.AppID),
 		})
 	}
 
@@ -86,53 +90,39 @@ func (s *serverAPI) ListAccounts(ctx context.Context, req *ssov1.ListAccountsReq
 }
 
 func (s *serverAPI) AccountInfo(ctx context.Context, req *ssov1.AccountInfoRequest) (*ssov1.AccountInfoResponse, error) {
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.Unauthenticated, "token is empty")
+	}
 
-	app, err := s.appProvider.App(ctx, 1)
+	// Token is verified by the app it was issued for
+	claims, err := s.auth.VerifyToken(ctx, req.GetToken())
 	if err != nil {
-		if errors.Is(err, storage.ErrAppNotFound) {
-			return nil, status.Error(codes.Internal, err.Error())
-		} else {
-			return nil, status.Error(codes.Internal, err.Error())
+		if errors.Is(err, auth.ErrInvalidAccessToken) {
+			return nil, status.Error(codes.Unauthenticated, "Invalid or expired token")
 		}
+		return nil, status.Error(codes.Internal, "Internal error")
 	}
 
-	token, err := jwt.Parse(req.Token, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(app.Secret), nil
-	})
+	user, perms, err := s.auth.UserInfo(ctx, claims.UID)
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
-	}
-
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok || !token.Valid {
-		return nil, status.Error(codes.Internal, "invalid claims or token")
-	}
-
-	appID := int(claims["app_id"].(float64))
-	if appID != 1 {
-		return nil, status.Error(codes.Internal, "app ID is not 1(quizzify)")
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, status.Error(codes.Unauthenticated, "User of the token not found")
+		}
+		return nil, status.Error(codes.Internal, "Internal error")
 	}
 
-	uid := uint64(claims["uid"].(float64))
-	user, perms, err := s.auth.UserInfo(ctx, uid)
+	isAdmin, err := s.auth.IsAdmin(ctx, user.ID)
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, status.Error(codes.Internal, "Internal error")
 	}
 
-	pbPerms := make([]int32, 0)
-	for _, p := range perms {
-		pbPerms = append(pbPerms, int32(p))
-	}
 	return &ssov1.AccountInfoResponse{
 		UserId:      int64(user.ID),
 		Login:       user.Login,
 		Email:       user.Email,
-		IsAdmin:     false,
-		Permissions: pbPerms,
-		AppId:       int32(app.ID),
+		IsAdmin:     isAdmin,
+		Permissions: toPbPermissions(perms),
+		AppId:       int32(claims.AppID),
 	}, nil
 }
 
@@ -159,7 +149,7 @@ func (s *serverAPI) Login(ctx context.Context, req *ssov1.LoginRequest) (*ssov1.
 		return nil, err
 	}
 
-	token, err := s.auth.Login(ctx, req.GetLogin(), req.GetEmail(), req.GetPassword(), int(req.GetAppId()))
+	token, refreshToken, err := s.auth.Login(ctx, req.GetLogin(), req.GetEmail(), req.GetPassword(), int(req.GetAppId()))
 	if err != nil {
 		if errors.Is(err, auth.ErrInvalidCredentials) { // Not sure if we should take errors directly from Auth
 			return nil, status.Error(codes.InvalidArgument, "Invalid credentials or user not exists")
@@ -171,10 +161,65 @@ func (s *serverAPI) Login(ctx context.Context, req *ssov1.LoginRequest) (*ssov1.
 	}
 
 	return &ssov1.LoginResponse{
-		Token: token,
+		Token:        token,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+func (s *serverAPI) Refresh(ctx context.Context, req *ssov1.RefreshRequest) (*ssov1.RefreshResponse, error) {
+	if err := validateRefreshToken(req.GetRefreshToken()); err != nil {
+		return nil, err
+	}
+
+	token, refreshToken, err := s.auth.Refresh(ctx, req.GetRefreshToken())
+	if err != nil {
+		return nil, sessionError(err)
+	}
+
+	return &ssov1.RefreshResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
 	}, nil
 }
 
+func (s *serverAPI) Logout(ctx context.Context, req *ssov1.LogoutRequest) (*ssov1.LogoutResponse, error) {
+	if err := validateRefreshToken(req.GetRefreshToken()); err != nil {
+		return nil, err
+	}
+
+	if err := s.auth.Logout(ctx, req.GetRefreshToken()); err != nil {
+		return nil, sessionError(err)
+	}
+
+	return &ssov1.LogoutResponse{
+		LoggedOut: true,
+	}, nil
+}
+
+func (s *serverAPI) RevokeAllSessions(ctx context.Context, req *ssov1.RevokeAllSessionsRequest) (*ssov1.RevokeAllSessionsResponse, error) {
+	if err := validateRefreshToken(req.GetRefreshToken()); err != nil {
+		return nil, err
+	}
+
+	if err := s.auth.RevokeAllSessions(ctx, req.GetRefreshToken()); err != nil {
+		return nil, sessionError(err)
+	}
+
+	return &ssov1.RevokeAllSessionsResponse{
+		Revoked: true,
+	}, nil
+}
+
+func sessionError(err error) error {
+	if errors.Is(err, auth.ErrInvalidToken) {
+		return status.Error(codes.Unauthenticated, "Invalid or expired refresh token")
+	}
+	if errors.Is(err, auth.ErrTokenReused) {
+		return status.Error(codes.Unauthenticated, "Refresh token was already used, session is revoked")
+	}
+	return status.Error(codes.Internal, "Internal error")
+}
+
 func (s *serverAPI) IsAdmin(ctx context.Context, req *ssov1.IsAdminRequest) (*ssov1.IsAdminResponse, error) {
 	if err := validateIsAdmin(req); err != nil {
 		return nil, err
@@ -193,6 +238,14 @@ func (s *serverAPI) IsAdmin(ctx context.Context, req *ssov1.IsAdminRequest) (*ss
 	}, nil
 }
 
+func toPbPermissions(perms []int) []int32 {
+	pbPerms := make([]int32, 0, len(perms))
+	for _, p := range perms {
+		pbPerms = append(pbPerms, int32(p))
+	}
+	return pbPerms
+}
+
 func validateRegister(req *ssov1.RegisterRequest) error {
 	if req.GetLogin() == "" {
 		return status.Error(codes.InvalidArgument, "login is empty")
@@ -225,6 +278,14 @@ func validateLogin(req *ssov1.LoginRequest) error {
 	return nil
 }
 
+func validateRefreshToken(refreshToken string) error {
+	if refreshToken == "" {
+		return status.Error(codes.InvalidArgument, "refresh_token is empty")
+	}
+
+	return nil
+}
+
 func validateIsAdmin(req *ssov1.IsAdminRequest) error {
 	if req.GetUserId() == emptyValue {
 		return status.Error(codes.InvalidArgument, "user_id is zero")
@@ -0,0 +1,144 @@
+package authzgrpc
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	ssov1 "github.com/coddmeistr/quizzify/backend/protos/proto/sso"
+	appjwt "github.com/coddmeistr/quizzify/backend/sso/internal/lib/jwt"
+	"github.com/coddmeistr/quizzify/backend/sso/internal/services/auth"
+	"github.com/coddmeistr/quizzify/backend/sso/internal/storage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Permission ids from the permissions table
+const (
+	PermissionCreator       = 1
+	PermissionAdministrator = 2
+	PermissionModerator     = 3
+)
+
+const (
+	authorizationKey = "authorization"
+	bearerPrefix     = "Bearer "
+)
+
+type TokenVerifier interface {
+	VerifyToken(ctx context.Context, token string) (appjwt.Claims, error)
+}
+
+// CallerProvider gives current rights of the caller
+// Rights are read from the storage instead of the token claims, so revoked rights take effect before token expires
+type CallerProvider interface {
+	UserPermissions(ctx context.Context, userID int64) ([]int, error)
+	IsAdmin(ctx context.Context, userID uint64) (bool, error)
+}
+
+// Caller is the authenticated user who calls the method
+type Caller struct {
+	UserID      uint64
+	AppID       int
+	Permissions []int
+	IsAdmin     bool
+}
+
+// Administrator checks if caller can manage other users
+func (c Caller) Administrator() bool {
+	return c.IsAdmin || slices.Contains(c.Permissions, PermissionAdministrator)
+}
+
+// Rule checks if the caller is allowed to make the request
+type Rule func(caller Caller, req any) bool
+
+// Rules are requirements of the methods which need authenticated caller, other methods are public
+var Rules = map[string]Rule{
+	ssov1.Auth_DeleteAccount_FullMethodName: func(caller Caller, req any) bool {
+		// Users can delete their own accounts
+		r, ok := req.(*ssov1.DeleteAccountRequest)
+		return caller.Administrator() || ok && uint64(r.GetId()) == caller.UserID
+	},
+	ssov1.Auth_ListAccounts_FullMethodName:           adminOnly,
+	ssov1.Permission_AddPermission_FullMethodName:    adminOnly,
+	ssov1.Permission_RemovePermission_FullMethodName: adminOnly,
+}
+
+func adminOnly(caller Caller, _ any) bool {
+	return caller.Administrator()
+}
+
+type callerKey struct{}
+
+// CallerFromContext returns caller authenticated by the interceptor
+func CallerFromContext(ctx context.Context) (Caller, bool) {
+	caller, ok := ctx.Value(callerKey{}).(Caller)
+	return caller, ok
+}
+
+// UnaryInterceptor authenticates caller of the methods having rules by the bearer token from metadata
+// Returns Unauthenticated for missing or invalid token and PermissionDenied if the rule rejects the request
+func UnaryInterceptor(verifier TokenVerifier, callers CallerProvider, rules map[string]Rule) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		rule, ok := rules[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		token, ok := bearerToken(ctx)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "Authorization token is required")
+		}
+
+		claims, err := verifier.VerifyToken(ctx, token)
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidAccessToken) {
+				return nil, status.Error(codes.Unauthenticated, "Invalid or expired token")
+			}
+			return nil, status.Error(codes.Internal, "Internal error")
+		}
+
+		perms, err := callers.UserPermissions(ctx, int64(claims.UID))
+		if err != nil {
+			return nil, status.Error(codes.Internal, "Internal error")
+		}
+		isAdmin, err := callers.IsAdmin(ctx, claims.UID)
+		if err != nil {
+			if errors.Is(err, storage.ErrUserNotFound) {
+				// Token of the deleted user
+				return nil, status.Error(codes.Unauthenticated, "Invalid or expired token")
+			}
+			return nil, status.Error(codes.Internal, "Internal error")
+		}
+
+		caller := Caller{
+			UserID:      claims.UID,
+			AppID:       claims.AppID,
+			Permissions: perms,
+			IsAdmin:     isAdmin,
+		}
+		if !rule(caller, req) {
+			return nil, status.Error(codes.PermissionDenied, "Not enough rights")
+		}
+
+		return handler(context.WithValue(ctx, callerKey{}, caller), req)
+	}
+}
+
+func bearerToken(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	for _, v := range md.Get(authorizationKey) {
+		if token, ok := strings.CutPrefix(v, bearerPrefix); ok && token != "" {
+			return token, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,90 @@
+package authzgrpc
+
+import (
+	"context"
+	"testing"
+
+	ssov1 "github.com/coddmeistr/quizzify/backend/protos/proto/sso"
+	appjwt "github.com/coddmeistr/quizzify/backend/sso/internal/lib/jwt"
+	"github.com/coddmeistr/quizzify/backend/sso/internal/services/auth"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// stubVerifier maps accepted tokens to ids of their users
+type stubVerifier map[string]uint64
+
+func (v stubVerifier) VerifyToken(_ context.Context, token string) (appjwt.Claims, error) {
+	uid, ok := v[token]
+	if !ok {
+		return appjwt.Claims{}, auth.ErrInvalidAccessToken
+	}
+	return appjwt.Claims{UID: uid, AppID: 1}, nil
+}
+
+type stubCallers struct {
+	perms  map[uint64][]int
+	admins map[uint64]bool
+}
+
+func (c stubCallers) UserPermissions(_ context.Context, userID int64) ([]int, error) {
+	return c.perms[uint64(userID)], nil
+}
+
+func (c stubCallers) IsAdmin(_ context.Context, userID uint64) (bool, error) {
+	return c.admins[userID], nil
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+	verifier := stubVerifier{"user": 1, "administrator": 2, "admin": 3}
+	callers := stubCallers{
+		perms:  map[uint64][]int{2: {PermissionCreator, PermissionAdministrator}, 1: {PermissionModerator}},
+		admins: map[uint64]bool{3: true},
+	}
+	interceptor := UnaryInterceptor(verifier, callers, Rules)
+
+	tc := []struct {
+		name   string
+		method string
+		token  string
+		req    any
+		code   codes.Code
+	}{
+		{name: "public method", method: ssov1.Auth_Login_FullMethodName, req: &ssov1.LoginRequest{}, code: codes.OK},
+		{name: "no token", method: ssov1.Auth_ListAccounts_FullMethodName, req: &ssov1.ListAccountsRequest{}, code: codes.Unauthenticated},
+		{name: "invalid token", method: ssov1.Auth_ListAccounts_FullMethodName, token: "forged", req: &ssov1.ListAccountsRequest{}, code: codes.Unauthenticated},
+		{name: "user lists accounts", method: ssov1.Auth_ListAccounts_FullMethodName, token: "user", req: &ssov1.ListAccountsRequest{}, code: codes.PermissionDenied},
+		{name: "administrator lists accounts", method: ssov1.Auth_ListAccounts_FullMethodName, token: "administrator", req: &ssov1.ListAccountsRequest{}, code: codes.OK},
+		{name: "admin flag lists accounts", method: ssov1.Auth_ListAccounts_FullMethodName, token: "admin", req: &ssov1.ListAccountsRequest{}, code: codes.OK},
+		{name: "user deletes own account", method: ssov1.Auth_DeleteAccount_FullMethodName, token: "user", req: &ssov1.DeleteAccountRequest{Id: 1}, code: codes.OK},
+		{name: "user deletes other account", method: ssov1.Auth_DeleteAccount_FullMethodName, token: "user", req: &ssov1.DeleteAccountRequest{Id: 2}, code: codes.PermissionDenied},
+		{name: "admin deletes other account", method: ssov1.Auth_DeleteAccount_FullMethodName, token: "admin", req: &ssov1.DeleteAccountRequest{Id: 1}, code: codes.OK},
+		{name: "user grants permission to self", method: ssov1.Permission_AddPermission_FullMethodName, token: "user", req: &ssov1.AddPermissionRequest{UserId: 1, PermissionId: PermissionAdministrator}, code: codes.PermissionDenied},
+		{name: "administrator removes permission", method: ssov1.Permission_RemovePermission_FullMethodName, token: "administrator", req: &ssov1.RemovePermissionRequest{UserId: 1, PermissionId: PermissionModerator}, code: codes.OK},
+	}
+
+	for _, tt := range tc {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			if tt.token != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+tt.token))
+			}
+
+			called := false
+			_, err := interceptor(ctx, tt.req, &grpc.UnaryServerInfo{FullMethod: tt.method}, func(ctx context.Context, req any) (any, error) {
+				called = true
+				return nil, nil
+			})
+
+			assert.Equal(t, tt.code, status.Code(err))
+			assert.Equal(t, tt.code == codes.OK, called)
+		})
+	}
+}
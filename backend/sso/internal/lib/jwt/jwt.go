@@ -1,14 +1,43 @@
 package appjwt
 
 import (
+	"crypto/x509"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/coddmeistr/quizzify/backend/sso/internal/domain/models"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func NewToken(user models.User, perms []int, app models.App, duration time.Duration) (string, error) {
-	token := jwt.New(jwt.SigningMethodHS256)
+// Supported signing algorithms
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+var ErrUnsupportedAlg = errors.New("unsupported signing algorithm")
+
+// NewToken creates token for the user of the app
+// Token is signed with the app's secret if key is nil, otherwise with the key, which id is put into the kid header
+func NewToken(user models.User, perms []int, app models.App, key *models.SigningKey, duration time.Duration) (string, error) {
+	method := jwt.SigningMethod(jwt.SigningMethodHS256)
+	var signingKey interface{} = []byte(app.Secret)
+	if key != nil {
+		var err error
+		if method, err = signingMethod(key.Alg); err != nil {
+			return "", err
+		}
+		if signingKey, err = x509.ParsePKCS8PrivateKey(key.PrivateKey); err != nil {
+			return "", fmt.Errorf("failed to parse private key: %w", err)
+		}
+	}
+
+	token := jwt.New(method)
+	if key != nil {
+		token.Header["kid"] = key.ID
+	}
 
 	claims := token.Claims.(jwt.MapClaims)
 	claims["uid"] = user.ID
@@ -17,10 +46,58 @@ func NewToken(user models.User, perms []int, app models.App, duration time.Durat
 	claims["app_id"] = app.ID
 	claims["permissions"] = perms
 
-	tokenString, err := token.SignedString([]byte(app.Secret))
+	tokenString, err := token.SignedString(signingKey)
 	if err != nil {
 		return "", err
 	}
 
 	return tokenString, nil
 }
+
+func signingMethod(alg string) (jwt.SigningMethod, error) {
+	switch alg {
+	case AlgRS256:
+		return jwt.SigningMethodRS256, nil
+	case AlgEdDSA:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlg, alg)
+	}
+}
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims are the claims of the token created by NewToken
+type Claims struct {
+	UID         uint64 `json:"uid"`
+	Login       string `json:"login"`
+	AppID       int    `json:"app_id"`
+	Permissions []int  `json:"permissions"`
+	jwt.RegisteredClaims
+}
+
+// KeyFunc returns algorithm and key the token of the app with given key id must be verified with
+// Key id is empty for tokens signed with the app secret
+type KeyFunc func(appID int, kid string) (alg string, key interface{}, err error)
+
+// ParseToken verifies signature and expiration of the token and returns its claims
+// Token must be signed with exactly the algorithm returned by keyFunc, so the key of one type can't be used as another
+func ParseToken(tokenString string, keyFunc KeyFunc) (Claims, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		alg, key, err := keyFunc(claims.AppID, kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != alg {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Method.Alg())
+		}
+		return key, nil
+	}, jwt.WithValidMethods([]string{AlgHS256, AlgRS256, AlgEdDSA}), jwt.WithExpirationRequired())
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,81 @@
+package appjwt
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/coddmeistr/quizzify/backend/sso/internal/domain/models"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewToken_SigningKey(t *testing.T) {
+	user := models.User{ID: 7, Login: "user"}
+	app := models.App{ID: 1, Secret: "secret"}
+
+	for _, alg := range []string{AlgRS256, AlgEdDSA} {
+		alg := alg
+
+		t.Run(alg, func(t *testing.T) {
+			t.Parallel()
+
+			kid, private, public, err := GenerateKey(alg)
+			require.NoError(t, err)
+			key := &models.SigningKey{ID: kid, AppID: app.ID, Alg: alg, PrivateKey: private, PublicKey: public}
+
+			tokenString, err := NewToken(user, []int{1}, app, key, time.Hour)
+			require.NoError(t, err)
+
+			token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+				assert.Equal(t, kid, token.Header["kid"])
+				return x509.ParsePKIXPublicKey(public)
+			}, jwt.WithValidMethods([]string{alg}))
+			require.NoError(t, err)
+			assert.Equal(t, float64(7), token.Claims.(jwt.MapClaims)["uid"])
+
+			// Token must not be accepted as signed with the app secret
+			_, err = jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+				return []byte(app.Secret), nil
+			}, jwt.WithValidMethods([]string{AlgHS256}))
+			assert.Error(t, err)
+
+			jwk, err := PublicJWK(kid, alg, public)
+			require.NoError(t, err)
+			assert.Equal(t, kid, jwk.Kid)
+			assert.Equal(t, alg, jwk.Alg)
+			assert.Equal(t, "sig", jwk.Use)
+		})
+	}
+}
+
+func TestPublicJWK_RSA(t *testing.T) {
+	_, _, public, err := GenerateKey(AlgRS256)
+	require.NoError(t, err)
+
+	jwk, err := PublicJWK("kid", AlgRS256, public)
+	require.NoError(t, err)
+
+	assert.Equal(t, "RSA", jwk.Kty)
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 0, 1}, e) // 65537
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	require.NoError(t, err)
+	assert.Len(t, n, rsaKeyBits/8)
+}
+
+func TestNewToken_Secret(t *testing.T) {
+	app := models.App{ID: 1, Secret: "secret"}
+
+	tokenString, err := NewToken(models.User{ID: 7}, nil, app, nil, time.Hour)
+	require.NoError(t, err)
+
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return []byte(app.Secret), nil
+	}, jwt.WithValidMethods([]string{AlgHS256}))
+	require.NoError(t, err)
+	assert.NotContains(t, token.Header, "kid")
+}
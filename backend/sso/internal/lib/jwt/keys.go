@@ -0,0 +1,99 @@
+package appjwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+)
+
+const rsaKeyBits = 2048
+
+// JWK is the public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// OKP
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS is the set of public keys tokens can be verified with
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// GenerateKey generates new key pair for the algorithm
+// Returns key id, PKCS #8 encoded private key and PKIX encoded public key
+func GenerateKey(alg string) (kid string, private []byte, public []byte, err error) {
+	var priv crypto.Signer
+	switch alg {
+	case AlgRS256:
+		priv, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgEdDSA:
+		_, priv, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return "", nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedAlg, alg)
+	}
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	if private, err = x509.MarshalPKCS8PrivateKey(priv); err != nil {
+		return "", nil, nil, err
+	}
+	if public, err = x509.MarshalPKIXPublicKey(priv.Public()); err != nil {
+		return "", nil, nil, err
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", nil, nil, err
+	}
+
+	return hex.EncodeToString(id), private, public, nil
+}
+
+// PublicJWK converts PKIX encoded public key to JWK
+func PublicJWK(kid string, alg string, public []byte) (JWK, error) {
+	pub, err := x509.ParsePKIXPublicKey(public)
+	if err != nil {
+		return JWK{}, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	b64 := base64.RawURLEncoding
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			Alg: alg,
+			N:   b64.EncodeToString(key.N.Bytes()),
+			E:   b64.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Kid: kid,
+			Use: "sig",
+			Alg: alg,
+			Crv: "Ed25519",
+			X:   b64.EncodeToString(key),
+		}, nil
+	default:
+		return JWK{}, fmt.Errorf("%w: %T", ErrUnsupportedAlg, pub)
+	}
+}
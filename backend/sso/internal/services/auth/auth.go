@@ -9,6 +9,7 @@ import (
 
 	"github.com/coddmeistr/quizzify/backend/sso/internal/domain/models"
 	appjwt "github.com/coddmeistr/quizzify/backend/sso/internal/lib/jwt"
+	"github.com/coddmeistr/quizzify/backend/sso/internal/lib/refresh"
 	"github.com/coddmeistr/quizzify/backend/sso/internal/storage"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -34,11 +35,27 @@ type AppProvider interface {
 	App(ctx context.Context, appID int) (models.App, error)
 }
 
+type KeyProvider interface {
+	SigningKey(ctx context.Context, app models.App) (*models.SigningKey, error)
+	VerificationKey(ctx context.Context, appID int, kid string) (alg string, key interface{}, err error)
+}
+
+type SessionStorage interface {
+	SaveRefreshToken(ctx context.Context, token models.RefreshToken) error
+	RefreshToken(ctx context.Context, hash []byte) (models.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, usedHash []byte, token models.RefreshToken) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	RevokeUserRefreshTokens(ctx context.Context, userID uint64) error
+}
+
 var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrUserNotFound       = errors.New("user not found")
 	ErrUserExists         = errors.New("user exists")
 	ErrAppNotFound        = errors.New("app not found")
+	ErrInvalidToken       = errors.New("invalid refresh token")
+	ErrTokenReused        = errors.New("refresh token reused")
+	ErrInvalidAccessToken = errors.New("invalid access token")
 )
 
 type Auth struct {
@@ -47,7 +64,10 @@ type Auth struct {
 	usrProvider   UserProvider
 	permsProvider PermissionsProvider
 	appProvider   AppProvider
+	keyProvider   KeyProvider
+	sessions      SessionStorage
 	tokenTTL      time.Duration
+	refreshTTL    time.Duration
 }
 
 func New(
@@ -56,14 +76,20 @@ func New(
 	usrProvider UserProvider,
 	permsProvider PermissionsProvider,
 	appProvider AppProvider,
-	tokenTTL time.Duration) *Auth {
+	keyProvider KeyProvider,
+	sessions SessionStorage,
+	tokenTTL time.Duration,
+	refreshTTL time.Duration) *Auth {
 	return &Auth{
 		log:           log,
 		usrSaver:      usrSaver,
 		usrProvider:   usrProvider,
 		permsProvider: permsProvider,
 		appProvider:   appProvider,
+		keyProvider:   keyProvider,
+		sessions:      sessions,
 		tokenTTL:      tokenTTL,
+		refreshTTL:    refreshTTL,
 	}
 }
 
@@ -113,7 +139,9 @@ func (a *Auth) UserInfo(ctx context.Context, userID uint64) (models.User, []int,
 	return user, perms, nil
 }
 
-func (a *Auth) Login(ctx context.Context, login string, email string, password string, appID int) (token string, err error) {
+// Login checks credentials of the user and starts new session in the app
+// Returns authorization token and refresh token of the session
+func (a *Auth) Login(ctx context.Context, login string, email string, password string, appID int) (token string, refreshToken string, err error) {
 	const op = "auth.Login"
 	log := a.log.With(
 		slog.String("op", op),
@@ -131,49 +159,274 @@ func (a *Auth) Login(ctx context.Context, login string, email string, password s
 	if err != nil {
 		if errors.Is(err, storage.ErrUserNotFound) {
 			log.Warn("user not found", slog.String("error", err.Error()))
-			return "", fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+			return "", "", fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
 		}
 
 		log.Error("failed to get user", slog.String("error", err.Error()))
-		return "", fmt.Errorf("%s: %w", op, err)
+		return "", "", fmt.Errorf("%s: %w", op, err)
 	}
 
 	if err := bcrypt.CompareHashAndPassword(user.PassHash, []byte(password)); err != nil {
 		log.Warn("invalid credentials", slog.String("error", err.Error()))
 
-		return "", fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+		return "", "", fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
 	}
 
 	app, err := a.appProvider.App(ctx, appID)
 	if err != nil {
 		if errors.Is(err, storage.ErrAppNotFound) {
 			log.Warn("app not found", slog.String("error", err.Error()))
-			return "", fmt.Errorf("%s: %w", op, err)
+			return "", "", fmt.Errorf("%s: %w", op, err)
 		} else {
 			log.Error("failed getting current app", slog.String("error", err.Error()))
-			return "", fmt.Errorf("%s: %w", op, err)
+			return "", "", fmt.Errorf("%s: %w", op, err)
 		}
 	}
 
 	log.Info("user logged in successfully")
 
-	perms, err := a.permsProvider.UserPermissions(ctx, int64(user.ID))
+	token, err = a.newToken(ctx, user, app)
 	if err != nil {
-		log.Error("failed getting user permissions", slog.String("error", err.Error()))
+		log.Error("failed generating new token", slog.String("error", err.Error()))
 
-		return "", fmt.Errorf("%s: %w", op, err)
+		return "", "", fmt.Errorf("%s: %w", op, err)
 	}
 
-	token, err = appjwt.NewToken(user, perms, app, a.tokenTTL)
+	familyID, err := refresh.NewFamilyID()
 	if err != nil {
-		log.Error("failed generating new token", slog.String("error", err.Error()))
+		log.Error("failed generating session id", slog.String("error", err.Error()))
+
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+	refreshToken, stored, err := a.newRefreshToken(user, app, familyID)
+	if err != nil {
+		log.Error("failed generating refresh token", slog.String("error", err.Error()))
 
-		return "", fmt.Errorf("%s: %w", op, err)
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+	if err := a.sessions.SaveRefreshToken(ctx, stored); err != nil {
+		log.Error("failed saving refresh token", slog.String("error", err.Error()))
+
+		return "", "", fmt.Errorf("%s: %w", op, err)
 	}
 
 	log.Info("token was generated")
 
-	return token, nil
+	return token, refreshToken, nil
+}
+
+// Refresh exchanges refresh token for the new pair of tokens, used refresh token is revoked
+// Using revoked token means it was stolen or leaked, so whole session is revoked and ErrTokenReused is returned
+func (a *Auth) Refresh(ctx context.Context, refreshToken string) (token string, newRefreshToken string, err error) {
+	const op = "auth.Refresh"
+	log := a.log.With(
+		slog.String("op", op),
+	)
+	log.Info("refreshing token")
+
+	used, err := a.activeRefreshToken(ctx, refreshToken)
+	if err != nil {
+		if errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrTokenReused) {
+			log.Warn("refresh token rejected", slog.String("error", err.Error()))
+		} else {
+			log.Error("failed getting refresh token", slog.String("error", err.Error()))
+		}
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	user, err := a.usrProvider.UserByID(ctx, used.UserID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Warn("user of the session not found", slog.String("error", err.Error()))
+			return "", "", fmt.Errorf("%s: %w", op, ErrInvalidToken)
+		}
+		log.Error("failed to get user", slog.String("error", err.Error()))
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	app, err := a.appProvider.App(ctx, int(used.AppID))
+	if err != nil {
+		log.Error("failed getting session app", slog.String("error", err.Error()))
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	newRefreshToken, stored, err := a.newRefreshToken(user, app, used.FamilyID)
+	if err != nil {
+		log.Error("failed generating refresh token", slog.String("error", err.Error()))
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+	if err := a.sessions.RotateRefreshToken(ctx, used.Hash, stored); err != nil {
+		if errors.Is(err, storage.ErrTokenRevoked) {
+			// Token was used by concurrent request between reading and rotation
+			log.Warn("refresh token reused concurrently", slog.String("family_id", used.FamilyID))
+			return "", "", fmt.Errorf("%s: %w", op, a.revokeReused(ctx, used))
+		}
+		log.Error("failed rotating refresh token", slog.String("error", err.Error()))
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	token, err = a.newToken(ctx, user, app)
+	if err != nil {
+		log.Error("failed generating new token", slog.String("error", err.Error()))
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("token was refreshed")
+
+	return token, newRefreshToken, nil
+}
+
+// Logout ends the session the refresh token belongs to
+func (a *Auth) Logout(ctx context.Context, refreshToken string) error {
+	const op = "auth.Logout"
+	log := a.log.With(
+		slog.String("op", op),
+	)
+	log.Info("ending session")
+
+	used, err := a.activeRefreshToken(ctx, refreshToken)
+	if err != nil {
+		log.Warn("refresh token rejected", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.sessions.RevokeRefreshTokenFamily(ctx, used.FamilyID); err != nil {
+		log.Error("failed revoking session", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("session ended")
+	return nil
+}
+
+// RevokeAllSessions ends all sessions of the user the refresh token belongs to
+func (a *Auth) RevokeAllSessions(ctx context.Context, refreshToken string) error {
+	const op = "auth.RevokeAllSessions"
+	log := a.log.With(
+		slog.String("op", op),
+	)
+	log.Info("ending all sessions of the user")
+
+	used, err := a.activeRefreshToken(ctx, refreshToken)
+	if err != nil {
+		log.Warn("refresh token rejected", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.sessions.RevokeUserRefreshTokens(ctx, used.UserID); err != nil {
+		log.Error("failed revoking sessions", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("all sessions ended", slog.Int("user_id", int(used.UserID)))
+	return nil
+}
+
+// activeRefreshToken returns stored refresh token if it can be used
+// Reused token revokes its whole session
+func (a *Auth) activeRefreshToken(ctx context.Context, refreshToken string) (models.RefreshToken, error) {
+	stored, err := a.sessions.RefreshToken(ctx, refresh.Hash(refreshToken))
+	if err != nil {
+		if errors.Is(err, storage.ErrTokenNotFound) {
+			return models.RefreshToken{}, ErrInvalidToken
+		}
+		return models.RefreshToken{}, err
+	}
+
+	if stored.RevokedAt != nil {
+		return models.RefreshToken{}, a.revokeReused(ctx, stored)
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return models.RefreshToken{}, ErrInvalidToken
+	}
+
+	return stored, nil
+}
+
+// revokeReused revokes session of the reused token and returns ErrTokenReused
+func (a *Auth) revokeReused(ctx context.Context, reused models.RefreshToken) error {
+	a.log.Warn("revoking session of reused refresh token",
+		slog.Int("user_id", int(reused.UserID)),
+		slog.String("family_id", reused.FamilyID),
+	)
+
+	if err := a.sessions.RevokeRefreshTokenFamily(ctx, reused.FamilyID); err != nil {
+		return err
+	}
+
+	return ErrTokenReused
+}
+
+func (a *Auth) newToken(ctx context.Context, user models.User, app models.App) (string, error) {
+	perms, err := a.permsProvider.UserPermissions(ctx, int64(user.ID))
+	if err != nil {
+		return "", err
+	}
+
+	key, err := a.keyProvider.SigningKey(ctx, app)
+	if err != nil {
+		return "", err
+	}
+
+	return appjwt.NewToken(user, perms, app, key, a.tokenTTL)
+}
+
+func (a *Auth) newRefreshToken(user models.User, app models.App, familyID string) (string, models.RefreshToken, error) {
+	token, hash, err := refresh.NewToken()
+	if err != nil {
+		return "", models.RefreshToken{}, err
+	}
+
+	now := time.Now().UTC()
+	return token, models.RefreshToken{
+		Hash:      hash,
+		UserID:    user.ID,
+		AppID:     app.ID,
+		FamilyID:  familyID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(a.refreshTTL),
+	}, nil
+}
+
+// VerifyToken checks that the token was issued by the app it claims and returns its claims
+// Tokens with the kid header are verified with the app's signing key, others with the app's secret
+// Returns ErrInvalidAccessToken if the token can't be trusted
+func (a *Auth) VerifyToken(ctx context.Context, token string) (appjwt.Claims, error) {
+	const op = "auth.VerifyToken"
+
+	// Failures of the storage are returned by the parser as invalid token, so they are kept aside
+	var lookupErr error
+	claims, err := appjwt.ParseToken(token, func(appID int, kid string) (string, interface{}, error) {
+		app, err := a.appProvider.App(ctx, appID)
+		if err != nil {
+			if !errors.Is(err, storage.ErrAppNotFound) {
+				lookupErr = err
+			}
+			return "", nil, err
+		}
+
+		if kid == "" {
+			if app.SigningAlg != "" && app.SigningAlg != appjwt.AlgHS256 {
+				return "", nil, fmt.Errorf("app %d doesn't sign tokens with secret", appID)
+			}
+			return appjwt.AlgHS256, []byte(app.Secret), nil
+		}
+
+		alg, key, err := a.keyProvider.VerificationKey(ctx, appID, kid)
+		if err != nil && !errors.Is(err, storage.ErrKeyNotFound) {
+			lookupErr = err
+		}
+		return alg, key, err
+	})
+	if lookupErr != nil {
+		a.log.Error("failed getting token key", slog.String("op", op), slog.String("error", lookupErr.Error()))
+		return appjwt.Claims{}, fmt.Errorf("%s: %w", op, lookupErr)
+	}
+	if err != nil {
+		return appjwt.Claims{}, fmt.Errorf("%s: %w", op, ErrInvalidAccessToken)
+	}
+
+	return claims, nil
 }
 
 func (a *Auth) Register(ctx context.Context, login string, email string, password string) (userID uint64, err error) {
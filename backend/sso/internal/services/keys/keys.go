@@ -0,0 +1,143 @@
+package keys
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/coddmeistr/quizzify/backend/sso/internal/domain/models"
+	appjwt "github.com/coddmeistr/quizzify/backend/sso/internal/lib/jwt"
+	"github.com/coddmeistr/quizzify/backend/sso/internal/storage"
+)
+
+type KeyStorage interface {
+	ActiveSigningKey(ctx context.Context, appID int) (models.SigningKey, error)
+	SigningKeyByID(ctx context.Context, kid string) (models.SigningKey, error)
+	RotateSigningKey(ctx context.Context, key models.SigningKey) error
+	SigningKeys(ctx context.Context, retiredAfter time.Time) ([]models.SigningKey, error)
+}
+
+// Keys manages asymmetric signing keys of the apps
+// Keys are created on first use and rotated when active key becomes older than rotation period
+// Retired key stays published while tokens signed with it can be valid
+type Keys struct {
+	log            *slog.Logger
+	storage        KeyStorage
+	rotationPeriod time.Duration
+	tokenTTL       time.Duration
+}
+
+func New(log *slog.Logger, storage KeyStorage, rotationPeriod time.Duration, tokenTTL time.Duration) *Keys {
+	return &Keys{
+		log:            log,
+		storage:        storage,
+		rotationPeriod: rotationPeriod,
+		tokenTTL:       tokenTTL,
+	}
+}
+
+// SigningKey returns key the app's tokens must be signed with
+// Returns nil if the app signs tokens with its secret
+func (k *Keys) SigningKey(ctx context.Context, app models.App) (*models.SigningKey, error) {
+	const op = "keys.SigningKey"
+	log := k.log.With(
+		slog.String("op", op),
+		slog.Int("app_id", int(app.ID)),
+	)
+
+	if app.SigningAlg == "" || app.SigningAlg == appjwt.AlgHS256 {
+		return nil, nil
+	}
+
+	key, err := k.storage.ActiveSigningKey(ctx, int(app.ID))
+	if err != nil && !errors.Is(err, storage.ErrKeyNotFound) {
+		log.Error("failed getting active signing key", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err == nil && key.Alg == app.SigningAlg && time.Since(key.CreatedAt) < k.rotationPeriod {
+		return &key, nil
+	}
+
+	log.Info("rotating signing key", slog.String("alg", app.SigningAlg))
+
+	kid, private, public, err := appjwt.GenerateKey(app.SigningAlg)
+	if err != nil {
+		log.Error("failed generating signing key", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	key = models.SigningKey{
+		ID:         kid,
+		AppID:      app.ID,
+		Alg:        app.SigningAlg,
+		PrivateKey: private,
+		PublicKey:  public,
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	if err := k.storage.RotateSigningKey(ctx, key); err != nil {
+		if !errors.Is(err, storage.ErrKeyExists) {
+			log.Error("failed saving signing key", slog.String("error", err.Error()))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		// Key was rotated by concurrent request, using its key
+		log.Warn("signing key was rotated concurrently")
+		if key, err = k.storage.ActiveSigningKey(ctx, int(app.ID)); err != nil {
+			log.Error("failed getting active signing key", slog.String("error", err.Error()))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	log.Info("signing key rotated", slog.String("kid", key.ID))
+	return &key, nil
+}
+
+// VerificationKey returns public key of the app the token with given key id must be verified with
+// Returns storage.ErrKeyNotFound if there is no such key of the app
+func (k *Keys) VerificationKey(ctx context.Context, appID int, kid string) (alg string, key interface{}, err error) {
+	const op = "keys.VerificationKey"
+
+	signingKey, err := k.storage.SigningKeyByID(ctx, kid)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if int(signingKey.AppID) != appID {
+		return "", nil, fmt.Errorf("%s: %w", op, storage.ErrKeyNotFound)
+	}
+
+	public, err := x509.ParsePKIXPublicKey(signingKey.PublicKey)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return signingKey.Alg, public, nil
+}
+
+// JWKS returns public keys of all apps which tokens can still be valid
+func (k *Keys) JWKS(ctx context.Context) (appjwt.JWKS, error) {
+	const op = "keys.JWKS"
+	log := k.log.With(
+		slog.String("op", op),
+	)
+
+	keys, err := k.storage.SigningKeys(ctx, time.Now().Add(-k.tokenTTL))
+	if err != nil {
+		log.Error("failed getting signing keys", slog.String("error", err.Error()))
+		return appjwt.JWKS{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	jwks := appjwt.JWKS{Keys: make([]appjwt.JWK, 0, len(keys))}
+	for _, key := range keys {
+		jwk, err := appjwt.PublicJWK(key.ID, key.Alg, key.PublicKey)
+		if err != nil {
+			log.Error("failed converting signing key", slog.String("kid", key.ID), slog.String("error", err.Error()))
+			return appjwt.JWKS{}, fmt.Errorf("%s: %w", op, err)
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return jwks, nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
 )
 
 type Storage struct {
@@ -218,7 +219,7 @@ func (s *Storage) App(ctx context.Context, appID int) (models.App, error) {
 	const op = "storage.postgres.App"
 
 	app := models.App{}
-	if err := s.db.QueryRow(ctx, "SELECT id, name, secret FROM apps WHERE id = $1", appID).Scan(&app.ID, &app.Name, &app.Secret); err != nil {
+	if err := s.db.QueryRow(ctx, "SELECT id, name, secret, signing_alg FROM apps WHERE id = $1", appID).Scan(&app.ID, &app.Name, &app.Secret, &app.SigningAlg); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return app, fmt.Errorf("%s: %w", op, storage.ErrAppNotFound)
 		}
@@ -227,3 +228,166 @@ func (s *Storage) App(ctx context.Context, appID int) (models.App, error) {
 
 	return app, nil
 }
+
+func (s *Storage) ActiveSigningKey(ctx context.Context, appID int) (models.SigningKey, error) {
+	const op = "storage.postgres.ActiveSigningKey"
+
+	key := models.SigningKey{}
+	if err := s.db.QueryRow(ctx, "SELECT kid, app_id, alg, private_key, public_key, created_at, retired_at FROM signing_keys WHERE app_id = $1 AND retired_at IS NULL", appID).
+		Scan(&key.ID, &key.AppID, &key.Alg, &key.PrivateKey, &key.PublicKey, &key.CreatedAt, &key.RetiredAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return key, fmt.Errorf("%s: %w", op, storage.ErrKeyNotFound)
+		}
+		return key, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return key, nil
+}
+
+func (s *Storage) SigningKeyByID(ctx context.Context, kid string) (models.SigningKey, error) {
+	const op = "storage.postgres.SigningKeyByID"
+
+	key := models.SigningKey{}
+	if err := s.db.QueryRow(ctx, "SELECT kid, app_id, alg, public_key, created_at, retired_at FROM signing_keys WHERE kid = $1", kid).
+		Scan(&key.ID, &key.AppID, &key.Alg, &key.PublicKey, &key.CreatedAt, &key.RetiredAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return key, fmt.Errorf("%s: %w", op, storage.ErrKeyNotFound)
+		}
+		return key, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return key, nil
+}
+
+// RotateSigningKey retires active key of the app, if the app has one, and saves the new active key
+// Returns storage.ErrKeyExists if the key was rotated concurrently
+func (s *Storage) RotateSigningKey(ctx context.Context, key models.SigningKey) error {
+	const op = "storage.postgres.RotateSigningKey"
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "UPDATE signing_keys SET retired_at = now() WHERE app_id = $1 AND retired_at IS NULL", key.AppID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := tx.Exec(ctx, "INSERT INTO signing_keys(kid, app_id, alg, private_key, public_key, created_at) VALUES($1, $2, $3, $4, $5, $6)",
+		key.ID, key.AppID, key.Alg, key.PrivateKey, key.PublicKey, key.CreatedAt); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // Unique violation
+			return fmt.Errorf("%s: %w", op, storage.ErrKeyExists)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// SigningKeys returns active keys of all apps and keys retired after given time
+func (s *Storage) SigningKeys(ctx context.Context, retiredAfter time.Time) ([]models.SigningKey, error) {
+	const op = "storage.postgres.SigningKeys"
+
+	rows, err := s.db.Query(ctx, "SELECT kid, app_id, alg, public_key, created_at, retired_at FROM signing_keys WHERE retired_at IS NULL OR retired_at > $1 ORDER BY created_at DESC", retiredAfter)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	keys := make([]models.SigningKey, 0)
+	for rows.Next() {
+		var key models.SigningKey
+		if err := rows.Scan(&key.ID, &key.AppID, &key.Alg, &key.PublicKey, &key.CreatedAt, &key.RetiredAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return keys, nil
+}
+
+func (s *Storage) SaveRefreshToken(ctx context.Context, token models.RefreshToken) error {
+	const op = "storage.postgres.SaveRefreshToken"
+
+	if _, err := s.db.Exec(ctx, "INSERT INTO refresh_tokens(token_hash, user_id, app_id, family_id, created_at, expires_at) VALUES($1, $2, $3, $4, $5, $6)",
+		token.Hash, token.UserID, token.AppID, token.FamilyID, token.CreatedAt, token.ExpiresAt); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) RefreshToken(ctx context.Context, hash []byte) (models.RefreshToken, error) {
+	const op = "storage.postgres.RefreshToken"
+
+	token := models.RefreshToken{}
+	if err := s.db.QueryRow(ctx, "SELECT token_hash, user_id, app_id, family_id, created_at, expires_at, revoked_at FROM refresh_tokens WHERE token_hash = $1", hash).
+		Scan(&token.Hash, &token.UserID, &token.AppID, &token.FamilyID, &token.CreatedAt, &token.ExpiresAt, &token.RevokedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return token, fmt.Errorf("%s: %w", op, storage.ErrTokenNotFound)
+		}
+		return token, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return token, nil
+}
+
+// RotateRefreshToken revokes the used token and saves the new one in one transaction
+// Returns storage.ErrTokenRevoked if the used token was already revoked, e.g. by concurrent refresh
+func (s *Storage) RotateRefreshToken(ctx context.Context, usedHash []byte, token models.RefreshToken) error {
+	const op = "storage.postgres.RotateRefreshToken"
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, "UPDATE refresh_tokens SET revoked_at = now() WHERE token_hash = $1 AND revoked_at IS NULL", usedHash)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrTokenRevoked)
+	}
+
+	if _, err := tx.Exec(ctx, "INSERT INTO refresh_tokens(token_hash, user_id, app_id, family_id, created_at, expires_at) VALUES($1, $2, $3, $4, $5, $6)",
+		token.Hash, token.UserID, token.AppID, token.FamilyID, token.CreatedAt, token.ExpiresAt); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	const op = "storage.postgres.RevokeRefreshTokenFamily"
+
+	if _, err := s.db.Exec(ctx, "UPDATE refresh_tokens SET revoked_at = now() WHERE family_id = $1 AND revoked_at IS NULL", familyID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) RevokeUserRefreshTokens(ctx context.Context, userID uint64) error {
+	const op = "storage.postgres.RevokeUserRefreshTokens"
+
+	if _, err := s.db.Exec(ctx, "UPDATE refresh_tokens SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL", userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -8,4 +8,8 @@ var (
 	ErrAppNotFound            = errors.New("app not found")
 	ErrPermissionAlreadyExist = errors.New("permission already exist")
 	ErrNoPermission           = errors.New("user don't have this permission")
+	ErrKeyNotFound            = errors.New("signing key not found")
+	ErrKeyExists              = errors.New("active signing key already exist")
+	ErrTokenNotFound          = errors.New("refresh token not found")
+	ErrTokenRevoked           = errors.New("refresh token revoked")
 )
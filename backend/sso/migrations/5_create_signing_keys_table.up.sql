@@ -0,0 +1,15 @@
+ALTER TABLE apps ADD COLUMN IF NOT EXISTS signing_alg VARCHAR(10) NOT NULL DEFAULT 'HS256';
+
+CREATE TABLE IF NOT EXISTS signing_keys
+(
+    id SERIAL PRIMARY KEY,
+    kid VARCHAR(64) NOT NULL UNIQUE,
+    app_id INT NOT NULL REFERENCES apps(id),
+    alg VARCHAR(10) NOT NULL,
+    private_key BYTEA NOT NULL,
+    public_key BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    retired_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_signing_keys_active ON signing_keys (app_id) WHERE retired_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_signing_keys_retired_at ON signing_keys (retired_at);
@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v6"
+	ssov1 "github.com/coddmeistr/quizzify/backend/protos/proto/sso"
+	"github.com/coddmeistr/quizzify/backend/sso/tests/suits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const secondAppID = 2
+
+func TestAccountInfo_SecondApp(t *testing.T) {
+	ctx, st := suits.NewDefault(t)
+
+	login := gofakeit.Username()
+	pass := randomPassword()
+	respReg, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Login: login, Email: gofakeit.Email(), Password: pass})
+	require.NoError(t, err)
+
+	respLogin, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Login: login, Password: pass, AppId: secondAppID})
+	require.NoError(t, err)
+
+	resp, err := st.AuthClient.AccountInfo(ctx, &ssov1.AccountInfoRequest{Token: respLogin.GetToken()})
+	require.NoError(t, err)
+	assert.Equal(t, respReg.GetUserId(), resp.GetUserId())
+	assert.Equal(t, login, resp.GetLogin())
+	assert.Equal(t, int32(secondAppID), resp.GetAppId())
+	assert.False(t, resp.GetIsAdmin())
+}
+
+func TestAccountInfo_Admin(t *testing.T) {
+	ctx, st := suits.NewDefault(t)
+
+	respLogin, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Login: "test-admin", Password: "test-admin-password", AppId: appID})
+	require.NoError(t, err)
+
+	resp, err := st.AuthClient.AccountInfo(ctx, &ssov1.AccountInfoRequest{Token: respLogin.GetToken()})
+	require.NoError(t, err)
+	assert.True(t, resp.GetIsAdmin())
+	assert.Equal(t, int32(appID), resp.GetAppId())
+
+	list, err := st.AuthClient.ListAccounts(st.AdminContext(ctx), &ssov1.ListAccountsRequest{})
+	require.NoError(t, err)
+	for _, account := range list.GetAccounts() {
+		// Accounts are listed for the app of the admin's token
+		assert.Equal(t, int32(appID), account.GetAppId())
+		if account.GetUserId() == resp.GetUserId() {
+			assert.True(t, account.GetIsAdmin())
+		}
+	}
+}
+
+func TestAccountInfo_InvalidToken(t *testing.T) {
+	ctx, st := suits.NewDefault(t)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "Empty token", token: ""},
+		{name: "Malformed token", token: "not-a-token"},
+		{name: "Wrong signature", token: registerAndLogin(ctx, t, st).GetToken() + "x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := st.AuthClient.AccountInfo(ctx, &ssov1.AccountInfoRequest{Token: tt.token})
+			assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		})
+	}
+}
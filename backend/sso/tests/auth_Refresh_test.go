@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v6"
+	ssov1 "github.com/coddmeistr/quizzify/backend/protos/proto/sso"
+	"github.com/coddmeistr/quizzify/backend/sso/tests/suits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRefresh_Rotation(t *testing.T) {
+	ctx, st := suits.NewDefault(t)
+
+	respLogin := registerAndLogin(ctx, t, st)
+	require.NotEmpty(t, respLogin.GetRefreshToken())
+
+	respRefresh, err := st.AuthClient.Refresh(ctx, &ssov1.RefreshRequest{RefreshToken: respLogin.GetRefreshToken()})
+	require.NoError(t, err)
+	assert.NotEmpty(t, respRefresh.GetToken())
+	assert.NotEqual(t, respLogin.GetRefreshToken(), respRefresh.GetRefreshToken())
+
+	// Reusing rotated token revokes the whole session
+	_, err = st.AuthClient.Refresh(ctx, &ssov1.RefreshRequest{RefreshToken: respLogin.GetRefreshToken()})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = st.AuthClient.Refresh(ctx, &ssov1.RefreshRequest{RefreshToken: respRefresh.GetRefreshToken()})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestRefresh_Logout(t *testing.T) {
+	ctx, st := suits.NewDefault(t)
+
+	respLogin := registerAndLogin(ctx, t, st)
+
+	respLogout, err := st.AuthClient.Logout(ctx, &ssov1.LogoutRequest{RefreshToken: respLogin.GetRefreshToken()})
+	require.NoError(t, err)
+	assert.True(t, respLogout.GetLoggedOut())
+
+	_, err = st.AuthClient.Refresh(ctx, &ssov1.RefreshRequest{RefreshToken: respLogin.GetRefreshToken()})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestRefresh_RevokeAllSessions(t *testing.T) {
+	ctx, st := suits.NewDefault(t)
+
+	login := gofakeit.Username()
+	pass := randomPassword()
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Login: login, Email: gofakeit.Email(), Password: pass})
+	require.NoError(t, err)
+
+	sessions := make([]string, 0, 2)
+	for i := 0; i < 2; i++ {
+		respLogin, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Login: login, Password: pass, AppId: appID})
+		require.NoError(t, err)
+		sessions = append(sessions, respLogin.GetRefreshToken())
+	}
+
+	respRevoke, err := st.AuthClient.RevokeAllSessions(ctx, &ssov1.RevokeAllSessionsRequest{RefreshToken: sessions[0]})
+	require.NoError(t, err)
+	assert.True(t, respRevoke.GetRevoked())
+
+	for _, refreshToken := range sessions {
+		_, err = st.AuthClient.Refresh(ctx, &ssov1.RefreshRequest{RefreshToken: refreshToken})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	}
+}
+
+func TestRefresh_InvalidToken(t *testing.T) {
+	ctx, st := suits.NewDefault(t)
+
+	_, err := st.AuthClient.Refresh(ctx, &ssov1.RefreshRequest{RefreshToken: ""})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = st.AuthClient.Refresh(ctx, &ssov1.RefreshRequest{RefreshToken: "not-issued-token"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func registerAndLogin(ctx context.Context, t *testing.T, st *suits.Suite) *ssov1.LoginResponse {
+	t.Helper()
+
+	login := gofakeit.Username()
+	pass := randomPassword()
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Login: login, Email: gofakeit.Email(), Password: pass})
+	require.NoError(t, err)
+
+	respLogin, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Login: login, Password: pass, AppId: appID})
+	require.NoError(t, err)
+
+	return respLogin
+}
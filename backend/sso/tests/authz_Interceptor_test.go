@@ -0,0 +1,69 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	ssov1 "github.com/coddmeistr/quizzify/backend/protos/proto/sso"
+	"github.com/coddmeistr/quizzify/backend/sso/tests/suits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthz_NoToken(t *testing.T) {
+	ctx, st := suits.NewDefault(t)
+
+	_, err := st.AuthClient.ListAccounts(ctx, &ssov1.ListAccountsRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = st.AuthClient.ListAccounts(withToken(ctx, "not-a-token"), &ssov1.ListAccountsRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestAuthz_UserRights(t *testing.T) {
+	ctx, st := suits.NewDefault(t)
+
+	other := registerAndLogin(ctx, t, st)
+	otherID := accountID(ctx, t, st, other.GetToken())
+	user := registerAndLogin(ctx, t, st)
+	userCtx := withToken(ctx, user.GetToken())
+	userID := accountID(ctx, t, st, user.GetToken())
+
+	_, err := st.PermsClient.AddPermission(userCtx, &ssov1.AddPermissionRequest{UserId: userID, PermissionId: 2})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = st.AuthClient.ListAccounts(userCtx, &ssov1.ListAccountsRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = st.AuthClient.DeleteAccount(userCtx, &ssov1.DeleteAccountRequest{Id: otherID})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Own account can be deleted
+	resp, err := st.AuthClient.DeleteAccount(userCtx, &ssov1.DeleteAccountRequest{Id: userID})
+	require.NoError(t, err)
+	assert.True(t, resp.GetDeleted())
+}
+
+func TestAuthz_Admin(t *testing.T) {
+	ctx, st := suits.NewDefault(t)
+
+	resp, err := st.AuthClient.ListAccounts(st.AdminContext(ctx), &ssov1.ListAccountsRequest{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.GetAccounts())
+}
+
+func withToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+func accountID(ctx context.Context, t *testing.T, st *suits.Suite, token string) int64 {
+	t.Helper()
+
+	resp, err := st.AuthClient.AccountInfo(ctx, &ssov1.AccountInfoRequest{Token: token})
+	require.NoError(t, err)
+
+	return resp.GetUserId()
+}
@@ -0,0 +1 @@
+INSERT INTO users (login, email, pass_hash, is_admin) VALUES ('test-admin', 'test-admin@quizzify.local', convert_to('$2a$10$bDOXMf5QaeHEIDycYLOslOCvTSj8jJLJ/VmGVZjFPRAwJCLSr3gKi', 'UTF8'), TRUE)
@@ -0,0 +1 @@
+INSERT INTO apps (id, name, secret, signing_alg) VALUES (2, 'test-second', 'test-second-secret', 'RS256')
\ No newline at end of file
@@ -14,6 +14,7 @@ import (
 
 func TestAddPermission_OK(t *testing.T) {
 	ctx, st := suits.NewDefault(t)
+	adminCtx := st.AdminContext(ctx)
 
 	// Register new test user
 	login := gofakeit.Username()
@@ -57,13 +58,13 @@ func TestAddPermission_OK(t *testing.T) {
 	assert.Equal(t, 0, len(permsInterface))
 
 	// Adding permissions to this user
-	respPerms, err := st.PermsClient.AddPermission(ctx, &ssov1.AddPermissionRequest{
+	respPerms, err := st.PermsClient.AddPermission(adminCtx, &ssov1.AddPermissionRequest{
 		UserId:       respReg.GetUserId(),
 		PermissionId: 2,
 	})
 	require.NoError(t, err)
 	assert.True(t, respPerms.GetGranted())
-	respPerms, err = st.PermsClient.AddPermission(ctx, &ssov1.AddPermissionRequest{
+	respPerms, err = st.PermsClient.AddPermission(adminCtx, &ssov1.AddPermissionRequest{
 		UserId:       respReg.GetUserId(),
 		PermissionId: 3,
 	})
@@ -95,6 +96,7 @@ func TestAddPermission_OK(t *testing.T) {
 
 func TestRemovePermission_OK(t *testing.T) {
 	ctx, st := suits.NewDefault(t)
+	adminCtx := st.AdminContext(ctx)
 
 	// Register new test user
 	login := gofakeit.Username()
@@ -129,13 +131,13 @@ func TestRemovePermission_OK(t *testing.T) {
 	assert.Equal(t, 0, len(permsInterface))                 // New user don't have any permissions
 
 	// Add new permissions to this user
-	respPerms, err := st.PermsClient.AddPermission(ctx, &ssov1.AddPermissionRequest{
+	respPerms, err := st.PermsClient.AddPermission(adminCtx, &ssov1.AddPermissionRequest{
 		UserId:       respReg.GetUserId(),
 		PermissionId: 2,
 	})
 	require.NoError(t, err)
 	assert.True(t, respPerms.GetGranted())
-	respPerms, err = st.PermsClient.AddPermission(ctx, &ssov1.AddPermissionRequest{
+	respPerms, err = st.PermsClient.AddPermission(adminCtx, &ssov1.AddPermissionRequest{
 		UserId:       respReg.GetUserId(),
 		PermissionId: 3,
 	})
@@ -165,13 +167,13 @@ func TestRemovePermission_OK(t *testing.T) {
 	assert.Equal(t, []interface{}{2.0, 3.0}, claims["permissions"].([]interface{})) // Checking right permissions
 
 	// Removing permissions from this user
-	respRemPerms, err := st.PermsClient.RemovePermission(ctx, &ssov1.RemovePermissionRequest{
+	respRemPerms, err := st.PermsClient.RemovePermission(adminCtx, &ssov1.RemovePermissionRequest{
 		UserId:       respReg.GetUserId(),
 		PermissionId: 3,
 	})
 	require.NoError(t, err)
 	assert.True(t, respRemPerms.GetRemoved())
-	respRemPerms, err = st.PermsClient.RemovePermission(ctx, &ssov1.RemovePermissionRequest{
+	respRemPerms, err = st.PermsClient.RemovePermission(adminCtx, &ssov1.RemovePermissionRequest{
 		UserId:       respReg.GetUserId(),
 		PermissionId: 2,
 	})
@@ -10,10 +10,16 @@ import (
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 )
 
 const (
 	grpcHost = "localhost"
+
+	// Admin user created by test migrations
+	adminLogin    = "test-admin"
+	adminPassword = "test-admin-password"
+	adminAppID    = 1
 )
 
 type Suite struct {
@@ -52,3 +58,19 @@ func NewDefault(t *testing.T) (context.Context, *Suite) {
 func grpcAddress(cfg *config.Config) string {
 	return net.JoinHostPort(grpcHost, fmt.Sprint(cfg.GRPC.Port))
 }
+
+// AdminContext returns context authorized as admin user created by test migrations
+func (s *Suite) AdminContext(ctx context.Context) context.Context {
+	s.Helper()
+
+	resp, err := s.AuthClient.Login(ctx, &ssov1.LoginRequest{
+		Login:    adminLogin,
+		Password: adminPassword,
+		AppId:    adminAppID,
+	})
+	if err != nil {
+		s.Fatalf("admin login failed: %v", err)
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+resp.GetToken())
+}
@@ -6,6 +6,7 @@ toolchain go1.22.0
 
 require (
 	github.com/brianvoe/gofakeit/v7 v7.0.1
+	github.com/golang-jwt/jwt/v5 v5.2.0
 	github.com/golang-migrate/migrate/v4 v4.17.0
 	github.com/google/uuid v1.6.0
 	github.com/gorilla/mux v1.8.1
@@ -14,6 +15,7 @@ require (
 	github.com/stretchr/testify v1.8.3
 	go.mongodb.org/mongo-driver v1.14.0
 	golang.org/x/exp v0.0.0-20230315142452-642cacee5cc0
+	golang.org/x/text v0.14.0
 )
 
 require (
@@ -50,6 +52,5 @@ require (
 	golang.org/x/crypto v0.17.0 // indirect
 	golang.org/x/net v0.18.0 // indirect
 	golang.org/x/sys v0.15.0 // indirect
-	golang.org/x/text v0.14.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -78,13 +78,26 @@ func (a *App) Stop() error {
 func (a *App) createServer() *http.Server {
 	a.log.Info("creating rest api server")
 
+	if a.cfg.Auth.Secret == "" {
+		a.log.Warn("no signing key for tokens, bearer tokens signed with app secret will be rejected")
+	}
+	var keys *user.KeySet
+	if a.cfg.Auth.JWKSURL != "" {
+		keys = user.NewKeySet(a.cfg.Auth.JWKSURL, a.cfg.Auth.JWKSCacheTTL)
+	} else {
+		a.log.Warn("no jwks url, bearer tokens signed with sso keys will be rejected")
+	}
+	if a.cfg.Auth.TrustProxyHeader {
+		a.log.Warn("trusting Auth-User-Info header, service must be reachable only through the proxy which sets it")
+	}
+
 	router := mux.NewRouter()
 	router.Use(
 		cors.Middleware,
 		logging.RequestLogger(a.log),
-		paginate.Middleware(a.cfg.Other.DefaultPage, a.cfg.Other.DefaultPerPage),
+		paginate.Middleware(a.cfg.Other.DefaultPage, a.cfg.Other.DefaultPerPage, a.cfg.Other.MaxPerPage),
 		sort.Middleware(a.cfg.Other.DefaultSortField, a.cfg.Other.DefaultSortOrder),
-		user.Middleware(),
+		user.Middleware(user.NewAuthenticator(a.cfg.Auth.AppID, a.cfg.Auth.Secret, keys, a.cfg.Auth.TrustProxyHeader)),
 	)
 
 	// Registering metrics endpoints
@@ -94,7 +107,8 @@ func (a *App) createServer() *http.Server {
 	apiRouter := router.PathPrefix("/api").Subrouter()
 	a.testHandlers.Register(apiRouter)
 
-	headersOk := handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization", "Auth-User-Info"})
+	headersOk := handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization", "Auth-User-Info", "If-Match"})
+	exposedOk := handlers.ExposedHeaders([]string{"ETag"})
 	originsOk := handlers.AllowedOrigins([]string{"*"})
 	methodsOk := handlers.AllowedMethods([]string{"GET", "POST", "PUT", "HEAD", "OPTIONS", "DELETE"})
 
@@ -104,7 +118,7 @@ func (a *App) createServer() *http.Server {
 		WriteTimeout: a.cfg.HTTPServer.Timeout,
 		ReadTimeout:  a.cfg.HTTPServer.Timeout,
 		IdleTimeout:  a.cfg.HTTPServer.IdleTimeout,
-		Handler:      handlers.CORS(headersOk, originsOk, methodsOk)(router),
+		Handler:      handlers.CORS(headersOk, exposedOk, originsOk, methodsOk)(router),
 	}
 	a.server = srv
 
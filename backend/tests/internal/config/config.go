@@ -14,6 +14,7 @@ type Config struct {
 	HTTPServer HTTPServer `yaml:"http-server" env-required:"true"`
 	MongoDB    MongoDB    `yaml:"mongodb" env-required:"true"`
 	Service    Service    `yaml:"service" env-required:"true"`
+	Auth       Auth       `yaml:"auth"`
 	Other      Other      `yaml:"other" env-required:"true"`
 }
 
@@ -32,9 +33,18 @@ type MongoDB struct {
 	DatabaseName  string `yaml:"database-name" env-default:"quizzify-tests"`
 }
 
+type Auth struct {
+	AppID            int           `yaml:"app-id" env-default:"1"`                 // SSO app the accepted tokens are issued for
+	Secret           string        `env:"SIGNING_KEY"`                             // Secret of the SSO app, tokens without kid are rejected if empty
+	JWKSURL          string        `yaml:"jwks-url" env:"JWKS_URL"`                // SSO key set, tokens with kid are rejected if empty
+	JWKSCacheTTL     time.Duration `yaml:"jwks-cache-ttl" env-default:"5m"`        // How long fetched key set is used before refetching
+	TrustProxyHeader bool          `yaml:"trust-proxy-header" env-default:"false"` // Accept Auth-User-Info header set by trusted proxy
+}
+
 type Other struct {
 	DefaultPage      int    `yaml:"default_page" env-default:"1"`
 	DefaultPerPage   int    `yaml:"default_per_page" env-default:"5"`
+	MaxPerPage       int    `yaml:"max_per_page" env-default:"100"`
 	DefaultSortField string `yaml:"default_sort_field" env-default:""`
 	DefaultSortOrder string `yaml:"default_sort_order" env-default:"ASC"`
 }
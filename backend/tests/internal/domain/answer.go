@@ -5,14 +5,30 @@ package domain
 // If question with specific type need only one correct answer it gets CorrectID
 // If question has many correct answer it gets CorrectIDs from slice
 // If question requires user input, then correct answer should be stored in CorrectText field e.t.
+// Manual input may accept several texts and compare them loosely, see TextMatching
+// Ordering question stores field ids in correct order, matching question stores correct pairs
+// Numeric answer is correct if it differs from CorrectNumber not more than Tolerance
 type AnswerModel struct {
-	CorrectID   *int                `json:"correct_id" bson:"correct_id"`
-	CorrectIDs  *[]int              `json:"correct_ids" bson:"correct_ids"`
-	CorrectText *string             `json:"correct_text" bson:"correct_text"`
-	FlexParams  *[]*FlexParamsModel `json:"params,omitempty" bson:"params"`
+	CorrectID        *int                `json:"correct_id" bson:"correct_id"`
+	CorrectIDs       *[]int              `json:"correct_ids" bson:"correct_ids"`
+	CorrectText      *string             `json:"correct_text" bson:"correct_text"`
+	AlternativeTexts *[]string           `json:"alternative_texts,omitempty" bson:"alternative_texts"`
+	TextMatching     *TextMatching       `json:"text_matching,omitempty" bson:"text_matching"`
+	CorrectOrder     *[]int              `json:"correct_order,omitempty" bson:"correct_order"`
+	CorrectPairs     *[]*MatchPair       `json:"correct_pairs,omitempty" bson:"correct_pairs"`
+	CorrectNumber    *float64            `json:"correct_number,omitempty" bson:"correct_number"`
+	Tolerance        *float64            `json:"tolerance,omitempty" bson:"tolerance"`
+	CorrectBool      *bool               `json:"correct_bool,omitempty" bson:"correct_bool"`
+	FlexParams       *[]*FlexParamsModel `json:"params,omitempty" bson:"params"`
 	// Add other possible answer fields
 }
 
+// MatchPair links field from the left column of matching question with field from the right column
+type MatchPair struct {
+	LeftID  int `json:"left_id" bson:"left_id"`
+	RightID int `json:"right_id" bson:"right_id"`
+}
+
 // FlexParamsModel represents flex parameters for test type 'test'
 // Each field in variants must have it's params to calculate answers based on user's choices
 // Each field numerically increasing or decreasing one or more parameters and
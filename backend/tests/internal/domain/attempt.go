@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// Attempt represents user's session of answering the test
+// All timestamps are set by the server, so the client can't forge the duration
+type Attempt struct {
+	ID          string     `json:"id" bson:"_id"`
+	TestID      string     `json:"test_id" bson:"test_id"`
+	UserID      int        `json:"user_id" bson:"user_id"`
+	StartedAt   time.Time  `json:"started_at" bson:"started_at"`
+	Deadline    *time.Time `json:"deadline" bson:"deadline"`         // Nil if test has no time limit
+	SubmittedAt *time.Time `json:"submitted_at" bson:"submitted_at"` // Nil while attempt is in progress
+	ResultID    *string    `json:"result_id" bson:"result_id"`       // Result saved on submission
+
+	Layout  []*QuestionLayout `json:"layout,omitempty" bson:"layout"` // Drawn questions and their order. Nil if test is not randomized
+	Answers []UserAnswerModel `json:"answers" bson:"answers"`         // Answers saved while attempt is in progress
+}
+
+// IsLate checks if the attempt is submitted after its deadline
+func (a *Attempt) IsLate(submittedAt time.Time) bool {
+	return a.Deadline != nil && submittedAt.After(*a.Deadline)
+}
+
+// MergeAnswers puts given answers over already saved ones, replacing answers on the same questions
+// Answers are kept ordered by question id
+func (a *Attempt) MergeAnswers(answers map[int]UserAnswerModel) {
+	merged := make(map[int]UserAnswerModel, len(a.Answers)+len(answers))
+	for _, v := range a.Answers {
+		merged[v.QuestionID] = v
+	}
+	for id, v := range answers {
+		merged[id] = v
+	}
+
+	a.Answers = make([]UserAnswerModel, 0, len(merged))
+	for _, v := range merged {
+		a.Answers = append(a.Answers, v)
+	}
+	sort.Slice(a.Answers, func(i, j int) bool {
+		return a.Answers[i].QuestionID < a.Answers[j].QuestionID
+	})
+}
@@ -0,0 +1,110 @@
+package domain
+
+// QuestionBank is a reusable set of questions owned by its creator
+// Tests reference bank questions instead of keeping copies, so the question is edited in one place
+type QuestionBank struct {
+	ID          *string      `json:"id" bson:"_id"`
+	UserID      *int         `json:"creator_id" bson:"creator_id"`
+	Title       *string      `json:"title" bson:"title"`
+	Description *string      `json:"description" bson:"description"`
+	Questions   *[]*Question `json:"questions" bson:"questions"`
+	Revision    int          `json:"revision" bson:"revision"` // Incremented on every update, results keep revisions they were taken against
+}
+
+// BankRef points to the question in the question bank
+type BankRef struct {
+	BankID     string `json:"bank_id" bson:"bank_id"`
+	QuestionID int    `json:"question_id" bson:"question_id"`
+}
+
+// BankIDs returns unique ids of all banks referenced by questions of the test
+func (t *Test) BankIDs() []string {
+	if t.Questions == nil {
+		return nil
+	}
+
+	met := make(map[string]struct{})
+	ids := make([]string, 0)
+	for _, q := range *t.Questions {
+		if q.BankRef == nil {
+			continue
+		}
+		if _, ok := met[q.BankRef.BankID]; ok {
+			continue
+		}
+		met[q.BankRef.BankID] = struct{}{}
+		ids = append(ids, q.BankRef.BankID)
+	}
+
+	return ids
+}
+
+// ExpandBankQuestions replaces bank references with the content of referenced questions
+// Question keeps its id within the test, its BankRef, and Points and Required if they were set in the test
+// Revisions of the banks questions were taken from are put to BankRevisions
+// References to missing banks or questions are dropped from the test, false is returned in such case
+func (t *Test) ExpandBankQuestions(banks []*QuestionBank) bool {
+	if t.Questions == nil {
+		return true
+	}
+
+	bankQuestions := make(map[BankRef]*Question)
+	revisions := make(map[string]int)
+	for _, b := range banks {
+		if b.ID == nil || b.Questions == nil {
+			continue
+		}
+		revisions[*b.ID] = b.Revision
+		for _, q := range *b.Questions {
+			bankQuestions[BankRef{BankID: *b.ID, QuestionID: q.ID}] = q
+		}
+	}
+
+	resolved := true
+	questions := make([]*Question, 0, len(*t.Questions))
+	for _, q := range *t.Questions {
+		if q.BankRef == nil {
+			questions = append(questions, q)
+			continue
+		}
+
+		bq, ok := bankQuestions[*q.BankRef]
+		if !ok {
+			resolved = false
+			continue
+		}
+
+		if t.BankRevisions == nil {
+			t.BankRevisions = make(map[string]int)
+		}
+		t.BankRevisions[q.BankRef.BankID] = revisions[q.BankRef.BankID]
+
+		expanded := *bq
+		expanded.ID = q.ID
+		expanded.BankRef = q.BankRef
+		expanded.Required = expanded.Required || q.Required
+		if q.Points != nil {
+			expanded.Points = q.Points
+		}
+		questions = append(questions, &expanded)
+	}
+	t.Questions = &questions
+
+	return resolved
+}
+
+// BankQuestions returns questions of the test that were expanded from question banks
+func (t *Test) BankQuestions() []*Question {
+	if t.Questions == nil {
+		return nil
+	}
+
+	questions := make([]*Question, 0)
+	for _, q := range *t.Questions {
+		if q.BankRef != nil {
+			questions = append(questions, q)
+		}
+	}
+
+	return questions
+}
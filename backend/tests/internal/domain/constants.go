@@ -6,7 +6,34 @@ const (
 	TestTypeTest       = "test"
 	TestTypeStrictTest = "strict_test"
 
+	TestStatusDraft     = "draft"
+	TestStatusPublished = "published"
+	TestStatusArchived  = "archived"
+
 	QuestionTypeSingleChoice   = "single_choice"
 	QuestionTypeMultipleChoice = "multiple_choice"
 	QuestionTypeManualInput    = "manual_input"
+	QuestionTypeOrdering       = "ordering"
+	QuestionTypeMatching       = "matching"
+	QuestionTypeNumeric        = "numeric"
+	QuestionTypeTrueFalse      = "true_false"
+
+	OutcomeOperatorLess         = "lt"
+	OutcomeOperatorLessEqual    = "lte"
+	OutcomeOperatorGreater      = "gt"
+	OutcomeOperatorGreaterEqual = "gte"
+	OutcomeOperatorEqual        = "eq"
+
+	QuestionResultCorrect = "correct"
+	QuestionResultPartial = "partial"
+	QuestionResultWrong   = "wrong"
+	QuestionResultPending = "pending"
+
+	ScoringAllOrNothing = "all_or_nothing"
+	ScoringProportional = "proportional"
+	ScoringNegative     = "negative"
+
+	ScoreModeBest    = "best"
+	ScoreModeLast    = "last"
+	ScoreModeAverage = "average"
 )
@@ -0,0 +1,153 @@
+package domain
+
+import "sort"
+
+// QuestionLayout describes how the question was shown to the user within one attempt
+// Questions of the attempt are kept in the order they were shown
+// VariantIDs contain field ids of choice variants in the shown order. Nil if variants were not shuffled
+type QuestionLayout struct {
+	QuestionID int   `json:"question_id" bson:"question_id"`
+	VariantIDs []int `json:"variant_ids,omitempty" bson:"variant_ids"`
+}
+
+// IsRandomized checks if the test is shown differently in each attempt
+func (t *Test) IsRandomized() bool {
+	return t.ShuffleQuestions || t.ShuffleVariants || t.QuestionsPool != nil
+}
+
+// NewLayout draws and shuffles questions of the test according to its randomization options
+// shuffle has the same signature as rand.Shuffle, so the caller decides about the source of randomness
+// Returns nil if the test is not randomized
+func (t *Test) NewLayout(shuffle func(n int, swap func(i, j int))) []*QuestionLayout {
+	if !t.IsRandomized() || t.Questions == nil {
+		return nil
+	}
+
+	questions := make([]*Question, len(*t.Questions))
+	copy(questions, *t.Questions)
+
+	// Pool is drawn from the shuffled questions, but shown in the declared order unless questions are shuffled too
+	if t.QuestionsPool != nil && *t.QuestionsPool < len(questions) {
+		positions := make(map[int]int, len(questions))
+		for i, q := range questions {
+			positions[q.ID] = i
+		}
+		shuffle(len(questions), func(i, j int) { questions[i], questions[j] = questions[j], questions[i] })
+		questions = questions[:*t.QuestionsPool]
+		if !t.ShuffleQuestions {
+			sortByPositions(questions, positions)
+		}
+	} else if t.ShuffleQuestions {
+		shuffle(len(questions), func(i, j int) { questions[i], questions[j] = questions[j], questions[i] })
+	}
+
+	layout := make([]*QuestionLayout, 0, len(questions))
+	for _, q := range questions {
+		l := &QuestionLayout{QuestionID: q.ID}
+		if t.ShuffleVariants {
+			if fields := q.choiceFields(); fields != nil {
+				l.VariantIDs = make([]int, 0, len(fields))
+				for _, f := range fields {
+					l.VariantIDs = append(l.VariantIDs, f.FieldID)
+				}
+				shuffle(len(l.VariantIDs), func(i, j int) { l.VariantIDs[i], l.VariantIDs[j] = l.VariantIDs[j], l.VariantIDs[i] })
+			}
+		}
+		layout = append(layout, l)
+	}
+
+	return layout
+}
+
+// ApplyLayout leaves only questions of the layout and puts them and their variants in the shown order
+// Questions and variants are copied, so the test can be safely shared between attempts
+// Scoring still matches answers by question and field ids, so it doesn't depend on the layout
+func (t *Test) ApplyLayout(layout []*QuestionLayout) {
+	if layout == nil || t.Questions == nil {
+		return
+	}
+
+	byID := make(map[int]*Question, len(*t.Questions))
+	for _, q := range *t.Questions {
+		byID[q.ID] = q
+	}
+
+	questions := make([]*Question, 0, len(layout))
+	for _, l := range layout {
+		q, ok := byID[l.QuestionID]
+		if !ok {
+			continue
+		}
+		if l.VariantIDs != nil {
+			q = q.withVariantsOrder(l.VariantIDs)
+		}
+		questions = append(questions, q)
+	}
+	t.Questions = &questions
+}
+
+// choiceFields returns variant fields that can be shuffled: choices and ordering items, nil for others
+func (q *Question) choiceFields() []*CommonField {
+	if q.Variants == nil || q.Type == nil {
+		return nil
+	}
+	switch *q.Type {
+	case QuestionTypeSingleChoice:
+		if q.Variants.SingleChoice != nil && q.Variants.SingleChoice.Fields != nil {
+			return *q.Variants.SingleChoice.Fields
+		}
+	case QuestionTypeMultipleChoice:
+		if q.Variants.MultipleChoice != nil && q.Variants.MultipleChoice.Fields != nil {
+			return *q.Variants.MultipleChoice.Fields
+		}
+	case QuestionTypeOrdering:
+		if q.Variants.Ordering != nil && q.Variants.Ordering.Fields != nil {
+			return *q.Variants.Ordering.Fields
+		}
+	}
+	return nil
+}
+
+// withVariantsOrder returns copy of the question with choice variants in the given order
+func (q *Question) withVariantsOrder(ids []int) *Question {
+	fields := q.choiceFields()
+	if fields == nil {
+		return q
+	}
+
+	byID := make(map[int]*CommonField, len(fields))
+	for _, f := range fields {
+		byID[f.FieldID] = f
+	}
+	ordered := make([]*CommonField, 0, len(fields))
+	for _, id := range ids {
+		if f, ok := byID[id]; ok {
+			ordered = append(ordered, f)
+		}
+	}
+
+	cp := *q
+	variants := *q.Variants
+	switch *q.Type {
+	case QuestionTypeSingleChoice:
+		sc := *variants.SingleChoice
+		sc.Fields = &ordered
+		variants.SingleChoice = &sc
+	case QuestionTypeMultipleChoice:
+		mc := *variants.MultipleChoice
+		mc.Fields = &ordered
+		variants.MultipleChoice = &mc
+	case QuestionTypeOrdering:
+		o := *variants.Ordering
+		o.Fields = &ordered
+		variants.Ordering = &o
+	}
+	cp.Variants = &variants
+	return &cp
+}
+
+func sortByPositions(questions []*Question, positions map[int]int) {
+	sort.Slice(questions, func(i, j int) bool {
+		return positions[questions[i].ID] < positions[questions[j].ID]
+	})
+}
@@ -0,0 +1,54 @@
+package domain
+
+// Outcome represents one of the final results for test type 'test'
+// After user applies the test, all flex params are summed up and outcomes are checked in declared order
+// First outcome which conditions are all met is chosen as final result
+// Outcome without conditions always matches, so it can be used as a fallback at the end of the list
+type Outcome struct {
+	ID         int                  `json:"id" bson:"id"`
+	Title      *string              `json:"title" bson:"title"`
+	Text       *string              `json:"text" bson:"text"`
+	Image      *Image               `json:"image,omitempty" bson:"image"`
+	Conditions *[]*OutcomeCondition `json:"conditions,omitempty" bson:"conditions"`
+}
+
+// OutcomeCondition compares summed value of the parameter with the Value using Operator
+// For example: {name: "energy", operator: "lt", value: 0} means energy < 0
+type OutcomeCondition struct {
+	ParamName *string `json:"name" bson:"name"`
+	Operator  *string `json:"operator" bson:"operator"`
+	Value     *int    `json:"value" bson:"value"`
+}
+
+// Matches checks if all conditions of the outcome are met by the given parameters
+// Parameters that were never affected by user's choices are treated as zero
+func (o *Outcome) Matches(params map[string]int) bool {
+	if o.Conditions == nil {
+		return true
+	}
+
+	for _, c := range *o.Conditions {
+		if !c.Matches(params[*c.ParamName]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *OutcomeCondition) Matches(v int) bool {
+	switch *c.Operator {
+	case OutcomeOperatorLess:
+		return v < *c.Value
+	case OutcomeOperatorLessEqual:
+		return v <= *c.Value
+	case OutcomeOperatorGreater:
+		return v > *c.Value
+	case OutcomeOperatorGreaterEqual:
+		return v >= *c.Value
+	case OutcomeOperatorEqual:
+		return v == *c.Value
+	default:
+		return false
+	}
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// MergePatch is a JSON Merge Patch document (RFC 7396), keys are json names of the fields
+// Null value deletes the field, object value is merged into the field, any other value replaces the field
+type MergePatch map[string]json.RawMessage
+
+// Deletes checks if patch deletes the field
+func (p MergePatch) Deletes(key string) bool {
+	v, ok := p[key]
+	return ok && IsJSONNull(v)
+}
+
+// IsJSONNull checks if raw json value is null
+func IsJSONNull(v json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(v), []byte("null"))
+}
+
+// Apply applies the patch to json representation of the value, v must be a pointer
+// It gives the same result as applying the patch to the stored document
+func (p MergePatch) Apply(v any) error {
+	current, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	patch, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	merged, err := mergeJSON(current, patch)
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v).Elem()
+	rv.Set(reflect.Zero(rv.Type()))
+	return json.Unmarshal(merged, v)
+}
+
+// mergeJSON merges patch into target following RFC 7396
+func mergeJSON(target json.RawMessage, patch json.RawMessage) (json.RawMessage, error) {
+	var patchObj map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &patchObj); err != nil || patchObj == nil {
+		return patch, nil
+	}
+
+	var targetObj map[string]json.RawMessage
+	if err := json.Unmarshal(target, &targetObj); err != nil || targetObj == nil {
+		targetObj = make(map[string]json.RawMessage, len(patchObj))
+	}
+
+	for k, v := range patchObj {
+		if IsJSONNull(v) {
+			delete(targetObj, k)
+			continue
+		}
+		merged, err := mergeJSON(targetObj[k], v)
+		if err != nil {
+			return nil, err
+		}
+		targetObj[k] = merged
+	}
+
+	return json.Marshal(targetObj)
+}
@@ -0,0 +1,43 @@
+package domain
+
+// TestsSortFields contains fields that tests list can be sorted by
+var TestsSortFields = []string{"id", "title", "type", "creator_id"}
+
+// TestsQuery describes which page of tests should be returned, in which order and with which filters
+// Nil or empty filters are not applied
+type TestsQuery struct {
+	Page      int
+	PerPage   int
+	SortField string
+	SortOrder string
+
+	Type      *string
+	Tags      []string
+	CreatorID *int
+	Title     *string // Case-insensitive substring of the title
+	Status    *string
+
+	// Visibility
+	HideDrafts bool // Drafts are not returned, except drafts created by the viewer
+	ViewerID   *int // Nil if tests are requested by anonymous user
+}
+
+// ResultsQuery describes which page of results should be returned
+// Nil filters are not applied
+type ResultsQuery struct {
+	Page    int
+	PerPage int
+
+	TestID  *string
+	UserID  *int
+	Pending *bool
+}
+
+// BanksQuery describes which page of question banks should be returned
+// Nil filters are not applied
+type BanksQuery struct {
+	Page    int
+	PerPage int
+
+	CreatorID *int
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"github.com/coddmeistr/quizzify/backend/tests/pkg/slice"
+	"math"
 )
 
 type Question struct {
@@ -12,12 +13,20 @@ type Question struct {
 	Required  bool           `json:"required" bson:"required"`
 	Variants  *VariantsModel `json:"variants" bson:"variants"`
 	Answers   *AnswerModel   `json:"answers,omitempty" bson:"answers"`
+	BankRef   *BankRef       `json:"bank_ref,omitempty" bson:"bank_ref"` // Set if question content is taken from the question bank
 
 	// Strict Test
-	Points *int `json:"points" bson:"points"`
+	Points      *int    `json:"points" bson:"points"`
+	Scoring     *string `json:"scoring,omitempty" bson:"scoring"` // Multiple choice scoring strategy. Proportional by default
+	NeedsReview bool    `json:"needs_review" bson:"needs_review"` // Manual input answer is graded by the test creator or moderator
 }
 
+// ComparePreciseResults returns percentage of credit for the user answer
+// Question without answers gives no credit
 func (q *Question) ComparePreciseResults(ua UserAnswerModel) int {
+	if q.Answers == nil {
+		return 0
+	}
 	qa := *q.Answers
 	switch *q.Type {
 	case QuestionTypeSingleChoice:
@@ -26,15 +35,45 @@ func (q *Question) ComparePreciseResults(ua UserAnswerModel) int {
 		}
 		return 0
 	case QuestionTypeMultipleChoice:
+		return q.compareMultipleChoice(*ua.ChosenIDs)
+	case QuestionTypeManualInput:
+		if qa.MatchText(*ua.WritedText) {
+			return 100
+		}
+		return 0
+	case QuestionTypeOrdering:
+		// Each item on its correct position gives equal part of credit
 		count := 0
-		for _, v := range *qa.CorrectIDs {
-			if slice.Contains(*ua.ChosenIDs, v) {
+		for i, v := range *qa.CorrectOrder {
+			if i < len(*ua.OrderedIDs) && (*ua.OrderedIDs)[i] == v {
 				count++
 			}
 		}
-		return int((float64(count) / float64(len(*qa.CorrectIDs))) * 100)
-	case QuestionTypeManualInput:
-		if *ua.WritedText == *qa.CorrectText {
+		return int((float64(count) / float64(len(*qa.CorrectOrder))) * 100)
+	case QuestionTypeMatching:
+		// Each correct pair gives equal part of credit
+		chosen := make(map[int]int, len(*ua.Pairs))
+		for _, v := range *ua.Pairs {
+			chosen[v.LeftID] = v.RightID
+		}
+		count := 0
+		for _, v := range *qa.CorrectPairs {
+			if right, ok := chosen[v.LeftID]; ok && right == v.RightID {
+				count++
+			}
+		}
+		return int((float64(count) / float64(len(*qa.CorrectPairs))) * 100)
+	case QuestionTypeNumeric:
+		tolerance := 0.0
+		if qa.Tolerance != nil {
+			tolerance = *qa.Tolerance
+		}
+		if math.Abs(*ua.Number-*qa.CorrectNumber) <= tolerance {
+			return 100
+		}
+		return 0
+	case QuestionTypeTrueFalse:
+		if *ua.ChosenBool == *qa.CorrectBool {
 			return 100
 		}
 		return 0
@@ -42,3 +81,89 @@ func (q *Question) ComparePreciseResults(ua UserAnswerModel) int {
 		return 0
 	}
 }
+
+// compareMultipleChoice scores chosen ids using scoring strategy of the question
+//   - all_or_nothing: 100 only if exactly all correct ids are chosen
+//   - proportional: each correct pick adds its share, each wrong pick takes share of wrong options,
+//     so choosing every option gives 0. Result is never below 0
+//   - negative: each wrong pick takes the same share as correct pick gives. Result can be down to -100,
+//     so wrong guesses lower points of the whole test, which is floored at 0
+func (q *Question) compareMultipleChoice(chosen []int) int {
+	correct := *q.Answers.CorrectIDs
+
+	right, wrong := 0, 0
+	for _, v := range chosen {
+		if slice.Contains(correct, v) {
+			right++
+		} else {
+			wrong++
+		}
+	}
+
+	scoring := ScoringProportional
+	if q.Scoring != nil {
+		scoring = *q.Scoring
+	}
+
+	switch scoring {
+	case ScoringAllOrNothing:
+		if right == len(correct) && wrong == 0 {
+			return 100
+		}
+		return 0
+	case ScoringNegative:
+		return max(-100, int(float64(right-wrong)/float64(len(correct))*100))
+	default:
+		score := float64(right) / float64(len(correct))
+		if options := q.optionsCount(); options > len(correct) {
+			score -= float64(wrong) / float64(options-len(correct))
+		}
+		return max(0, int(score*100))
+	}
+}
+
+// optionsCount returns number of multiple choice variants, 0 if variants are unknown
+func (q *Question) optionsCount() int {
+	if q.Variants == nil || q.Variants.MultipleChoice == nil || q.Variants.MultipleChoice.Fields == nil {
+		return 0
+	}
+	return len(*q.Variants.MultipleChoice.Fields)
+}
+
+// CollectFlexParams adds effects of all fields chosen by user to the params map
+// Used for test type 'test', where each chosen field increases or decreases some parameters
+func (q *Question) CollectFlexParams(ua UserAnswerModel, params map[string]int) {
+	if q.Answers == nil || q.Answers.FlexParams == nil {
+		return
+	}
+
+	var chosen []int
+	switch *q.Type {
+	case QuestionTypeSingleChoice:
+		if ua.ChosenID != nil {
+			chosen = []int{*ua.ChosenID}
+		}
+	case QuestionTypeMultipleChoice:
+		if ua.ChosenIDs != nil {
+			chosen = *ua.ChosenIDs
+		}
+	default:
+		return
+	}
+
+	for _, fp := range *q.Answers.FlexParams {
+		if fp == nil || fp.Params == nil || !slice.Contains(chosen, fp.FieldID) {
+			continue
+		}
+		for _, v := range *fp.Params {
+			if v == nil || v.ParamName == nil || v.Effect == nil {
+				continue
+			}
+			effect := *v.Effect
+			if v.IsNegative {
+				effect = -effect
+			}
+			params[*v.ParamName] += effect
+		}
+	}
+}
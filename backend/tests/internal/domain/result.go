@@ -1,9 +1,115 @@
 package domain
 
+import "time"
+
 type Result struct {
-	TestID      string            `json:"test_id" bson:"test_id"`           // Test id
-	UserID      int               `json:"user_id" bson:"user_id"`           // User id
-	UserAnswers []UserAnswerModel `json:"user_answers" bson:"user_answers"` // Storing all user chooses
-	ResultID    *int              `json:"result_id" bson:"result_id"`       // For test. Test contains result with this id
-	Percentage  *int              `json:"percentage" bson:"percentage"`     // For strict-test. Percents of right answers
+	ID              string             `json:"id" bson:"_id"`                                      // Result id
+	CreatedAt       time.Time          `json:"created_at" bson:"created_at"`                       // Time when user submitted answers
+	StartedAt       *time.Time         `json:"started_at" bson:"started_at"`                       // Time when user started the attempt
+	Attempt         int                `json:"attempt" bson:"attempt"`                             // Number of user's attempt for this test, starting from 1
+	AttemptID       *string            `json:"attempt_id" bson:"attempt_id"`                       // Attempt session in which the result was submitted
+	Duration        *int               `json:"duration" bson:"duration"`                           // Seconds spent from the start to the submission
+	Late            bool               `json:"late" bson:"late"`                                   // Submitted after the time limit
+	LatePenalty     int                `json:"late_penalty" bson:"late_penalty"`                   // Percents of points taken for late submission by the test rule at submission time
+	TestID          string             `json:"test_id" bson:"test_id"`                             // Test id
+	TestVersion     int                `json:"test_version" bson:"test_version"`                   // Version of the test the result was taken against
+	UserID          int                `json:"user_id" bson:"user_id"`                             // User id
+	UserAnswers     []UserAnswerModel  `json:"user_answers" bson:"user_answers"`                   // Storing all user chooses
+	Layout          []*QuestionLayout  `json:"layout,omitempty" bson:"layout"`                     // Questions and variants exactly as user saw them in randomized test
+	ResultID        *int               `json:"result_id" bson:"result_id"`                         // For test. Test contains result with this id
+	Params          *map[string]int    `json:"params,omitempty" bson:"params"`                     // For test. Summed up flex params
+	Percentage      *int               `json:"percentage" bson:"percentage"`                       // For strict-test. Percents of right answers
+	FinalPercentage *int               `json:"final_percentage,omitempty" bson:"final_percentage"` // For strict-test. Percentage that counts among all attempts
+	Points          *int               `json:"points,omitempty" bson:"points"`                     // For strict-test. Earned points
+	MaxPoints       *int               `json:"max_points,omitempty" bson:"max_points"`             // For strict-test. Max possible points
+	Questions       *[]*QuestionResult `json:"questions,omitempty" bson:"questions"`               // For strict-test. Per question breakdown
+	BankQuestions   []*Question        `json:"bank_questions,omitempty" bson:"bank_questions"`     // Bank questions as they were at submission time
+	BankRevisions   map[string]int     `json:"bank_revisions,omitempty" bson:"bank_revisions"`     // Revisions of question banks the result was taken against
+	Pending         bool               `json:"pending" bson:"pending"`                             // For strict-test. Some answers wait for manual grading, score is not final
+}
+
+// QuestionResult represents how user answered on the specific question of the strict test
+// CorrectAnswers are provided only if test author allowed to reveal them
+type QuestionResult struct {
+	QuestionID     int          `json:"question_id" bson:"question_id"`
+	Status         string       `json:"status" bson:"status"`
+	Points         int          `json:"points" bson:"points"`
+	MaxPoints      int          `json:"max_points" bson:"max_points"`
+	CorrectAnswers *AnswerModel `json:"correct_answers,omitempty" bson:"correct_answers"`
+
+	// Manual grading
+	Pending  bool    `json:"pending,omitempty" bson:"pending"`     // Answer waits for manual grading
+	Graded   bool    `json:"graded,omitempty" bson:"graded"`       // Points were assigned by reviewer, but result may be not finalized yet
+	Comment  *string `json:"comment,omitempty" bson:"comment"`     // Reviewer's comment for the user
+	GradedBy *int    `json:"graded_by,omitempty" bson:"graded_by"` // Reviewer id
+}
+
+// Grade is points and comment assigned by reviewer to the answer that needs manual grading
+type Grade struct {
+	QuestionID int
+	Points     int
+	Comment    *string
+}
+
+// Score sums points of the breakdown and sets Points, MaxPoints and Percentage of the result
+// Negative marking can take points below zero, but the test score can't be negative
+// LatePenalty of the result is taken from points, so rescoring doesn't depend on later edits of the test
+func (r *Result) Score() {
+	points, maxPoints := 0, 0
+	if r.Questions != nil {
+		for _, q := range *r.Questions {
+			points += q.Points
+			maxPoints += q.MaxPoints
+		}
+	}
+
+	points = max(0, points)
+	points = points * (100 - r.LatePenalty) / 100
+	percentage := 0
+	if maxPoints > 0 {
+		percentage = int(float64(points) / float64(maxPoints) * 100.0)
+	}
+
+	r.Points = &points
+	r.MaxPoints = &maxPoints
+	r.Percentage = &percentage
+}
+
+// QuestionResultStatus returns status of the answer by percentage of its correctness
+func QuestionResultStatus(percentage int) string {
+	switch {
+	case percentage >= 100:
+		return QuestionResultCorrect
+	case percentage > 0:
+		return QuestionResultPartial
+	default:
+		return QuestionResultWrong
+	}
+}
+
+// FinalPercentage returns percentage that counts among all attempts depending on the score mode
+// Percentages must be ordered from the first attempt to the last one
+func FinalPercentage(mode string, percentages []int) int {
+	if len(percentages) == 0 {
+		return 0
+	}
+
+	switch mode {
+	case ScoreModeBest:
+		best := percentages[0]
+		for _, v := range percentages {
+			if v > best {
+				best = v
+			}
+		}
+		return best
+	case ScoreModeAverage:
+		sum := 0
+		for _, v := range percentages {
+			sum += v
+		}
+		return sum / len(percentages)
+	default:
+		return percentages[len(percentages)-1]
+	}
 }
@@ -1,9 +1,13 @@
 package domain
 
 type Test struct {
-	ID     *string `json:"id" bson:"_id"`
-	UserID *int    `json:"creator_id" bson:"creator_id"`
-	Type   *string `json:"type" bson:"type"`
+	ID      *string `json:"id" bson:"_id"`
+	UserID  *int    `json:"creator_id" bson:"creator_id"`
+	Type    *string `json:"type" bson:"type"`
+	Status  *string `json:"status" bson:"status"`   // Draft, published or archived. Only published tests can be applied
+	Version int     `json:"version" bson:"version"` // Incremented on every change of the test content, starting from 1
+
+	Revision int `json:"revision" bson:"revision"` // Incremented on every write of the test, used to detect concurrent changes
 
 	Title     *string `json:"title" bson:"title"`
 	ShortText *string `json:"short_text" bson:"short_text"`
@@ -12,4 +16,64 @@ type Test struct {
 
 	Questions *[]*Question `json:"questions" bson:"questions"`
 	Tags      *[]string    `json:"tags" bson:"tags"`
+
+	BankRevisions map[string]int `json:"-" bson:"-"` // Revisions of question banks the questions were expanded from. Not stored
+
+	TimeLimit   *int `json:"time_limit" bson:"time_limit"`     // Seconds given for the attempt. Nil if test is not timed
+	LatePenalty *int `json:"late_penalty" bson:"late_penalty"` // Percents of points taken for late submission. Nil if late submissions are rejected
+
+	// Randomization
+	ShuffleQuestions bool `json:"shuffle_questions" bson:"shuffle_questions"` // Show questions in random order in each attempt
+	ShuffleVariants  bool `json:"shuffle_variants" bson:"shuffle_variants"`   // Show choice variants in random order in each attempt
+	QuestionsPool    *int `json:"questions_pool" bson:"questions_pool"`       // Number of questions randomly drawn for each attempt. Nil to show all
+
+	// Retakes
+	MaxAttempts    *int    `json:"max_attempts" bson:"max_attempts"`       // Nil if attempts are unlimited
+	RetakeCooldown *int    `json:"retake_cooldown" bson:"retake_cooldown"` // Seconds user must wait after previous submission
+	ScoreMode      *string `json:"score_mode" bson:"score_mode"`           // Which score counts: best, last or average. Last by default
+
+	// Strict Test
+	RevealAnswers bool `json:"reveal_answers" bson:"reveal_answers"` // Show correct answers in results
+
+	// Test
+	Params   *[]string   `json:"params,omitempty" bson:"params"`     // Declared names of flex parameters
+	Outcomes *[]*Outcome `json:"outcomes,omitempty" bson:"outcomes"` // Possible final results
+}
+
+// TestStatus returns lifecycle status of the test
+// Tests created before statuses were introduced have no status and are treated as published
+func (t *Test) TestStatus() string {
+	if t.Status == nil {
+		return TestStatusPublished
+	}
+	return *t.Status
+}
+
+// CanTransitionTo checks if test can be moved from its current status to the given one
+// Drafts and archived tests can be published, only published tests can be archived
+func (t *Test) CanTransitionTo(status string) bool {
+	switch status {
+	case TestStatusPublished:
+		return t.TestStatus() == TestStatusDraft || t.TestStatus() == TestStatusArchived
+	case TestStatusArchived:
+		return t.TestStatus() == TestStatusPublished
+	default:
+		return false
+	}
+}
+
+// ChooseOutcome returns first outcome that matches given parameters
+// Returns nil if test has no outcomes or none of them matched
+func (t *Test) ChooseOutcome(params map[string]int) *Outcome {
+	if t.Outcomes == nil {
+		return nil
+	}
+
+	for _, o := range *t.Outcomes {
+		if o.Matches(params) {
+			return o
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"github.com/coddmeistr/quizzify/backend/tests/pkg/strs"
+	"golang.org/x/text/unicode/norm"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// TextMatching describes how user's text is compared with accepted answers of manual input question
+// Without options texts must be exactly equal
+type TextMatching struct {
+	CaseInsensitive  bool    `json:"case_insensitive" bson:"case_insensitive"`   // "Paris" equals "paris"
+	IgnoreWhitespace bool    `json:"ignore_whitespace" bson:"ignore_whitespace"` // Leading, trailing and repeated spaces are ignored
+	NormalizeUnicode bool    `json:"normalize_unicode" bson:"normalize_unicode"` // Texts are compared in NFKC form
+	Pattern          *string `json:"pattern,omitempty" bson:"pattern"`           // Regular expression the whole text must match
+	MaxDistance      *int    `json:"max_distance,omitempty" bson:"max_distance"` // Number of typos allowed by Levenshtein distance
+}
+
+// AcceptedTexts returns all texts that are accepted as correct answer
+func (a *AnswerModel) AcceptedTexts() []string {
+	texts := make([]string, 0, 1)
+	if a.CorrectText != nil {
+		texts = append(texts, *a.CorrectText)
+	}
+	if a.AlternativeTexts != nil {
+		texts = append(texts, *a.AlternativeTexts...)
+	}
+	return texts
+}
+
+// ShortestAcceptedTextLen returns length in runes of the shortest accepted text, as it is compared with user's text
+// Returns 0 if there are no accepted texts
+func (a *AnswerModel) ShortestAcceptedTextLen() int {
+	m := a.TextMatching
+	if m == nil {
+		m = &TextMatching{}
+	}
+
+	shortest := 0
+	for i, v := range a.AcceptedTexts() {
+		l := utf8.RuneCountInString(m.normalize(v))
+		if i == 0 || l < shortest {
+			shortest = l
+		}
+	}
+	return shortest
+}
+
+// MatchText checks if user's text matches any of accepted texts or the pattern
+func (a *AnswerModel) MatchText(text string) bool {
+	m := a.TextMatching
+	if m == nil {
+		m = &TextMatching{}
+	}
+
+	text = m.normalize(text)
+	for _, v := range a.AcceptedTexts() {
+		v = m.normalize(v)
+		if text == v {
+			return true
+		}
+		// Empty text isn't a typo of any accepted text
+		if m.MaxDistance != nil && text != "" && strs.Levenshtein(text, v) <= *m.MaxDistance {
+			return true
+		}
+	}
+
+	if m.Pattern != nil {
+		re, err := m.compile()
+		if err == nil && re.MatchString(text) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *TextMatching) normalize(s string) string {
+	if m.NormalizeUnicode {
+		s = norm.NFKC.String(s)
+	}
+	if m.IgnoreWhitespace {
+		s = strings.Join(strings.Fields(s), " ")
+	}
+	if m.CaseInsensitive {
+		s = strings.ToLower(s)
+	}
+	return s
+}
+
+// Compile checks that the pattern is valid regular expression
+func (m *TextMatching) Compile() error {
+	if m.Pattern == nil {
+		return nil
+	}
+	_, err := m.compile()
+	return err
+}
+
+// compile anchors the pattern, so it has to match the whole text, not its part
+func (m *TextMatching) compile() (*regexp.Regexp, error) {
+	flags := ""
+	if m.CaseInsensitive {
+		flags = "(?i)"
+	}
+	return regexp.Compile(flags + "^(?:" + *m.Pattern + ")$")
+}
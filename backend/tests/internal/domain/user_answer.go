@@ -5,4 +5,9 @@ type UserAnswerModel struct {
 	ChosenID   *int    `json:"chosen_id" bson:"chosen_id"`
 	ChosenIDs  *[]int  `json:"chosen_ids" bson:"chosen_ids"`
 	WritedText *string `json:"writed_text" bson:"writed_text"`
+
+	OrderedIDs *[]int        `json:"ordered_ids,omitempty" bson:"ordered_ids"` // Ordering. All field ids in user's order
+	Pairs      *[]*MatchPair `json:"pairs,omitempty" bson:"pairs"`             // Matching. Pairs made by user
+	Number     *float64      `json:"number,omitempty" bson:"number"`           // Numeric
+	ChosenBool *bool         `json:"chosen_bool,omitempty" bson:"chosen_bool"` // True/false
 }
@@ -9,6 +9,30 @@ type MultipleChoice struct {
 	Fields     *[]*CommonField `json:"fields" bson:"fields"`
 }
 
+// Ordering contains items that user must put in the correct order
+// Items are shown in the stored order unless the test shuffles variants
+type Ordering struct {
+	Fields *[]*CommonField `json:"fields" bson:"fields"`
+}
+
+// Matching contains two columns of items. User matches each left item with one of the right items
+// Right column may contain more items than left, extra items are distractors
+type Matching struct {
+	Left  *[]*CommonField `json:"left" bson:"left"`
+	Right *[]*CommonField `json:"right" bson:"right"`
+}
+
+// Numeric has no variants to choose from, user writes a number. Unit is only shown near the input
+type Numeric struct {
+	Unit *string `json:"unit,omitempty" bson:"unit"`
+}
+
+// TrueFalse may have custom labels for both options, e.g. "Yes" and "No"
+type TrueFalse struct {
+	TrueText  *string `json:"true_text,omitempty" bson:"true_text"`
+	FalseText *string `json:"false_text,omitempty" bson:"false_text"`
+}
+
 // CommonField basic answer field, that contains information that shows to user.
 // User allowed to choose between CommonFields.
 // FieldID contains unique numeric value for each CommonField in VariantModel.
@@ -26,4 +50,8 @@ type CommonField struct {
 type VariantsModel struct {
 	SingleChoice   *SingleChoice   `json:"single_choice,omitempty" bson:"single_choice"`
 	MultipleChoice *MultipleChoice `json:"multiple_choice,omitempty" bson:"multiple_choice"`
+	Ordering       *Ordering       `json:"ordering,omitempty" bson:"ordering"`
+	Matching       *Matching       `json:"matching,omitempty" bson:"matching"`
+	Numeric        *Numeric        `json:"numeric,omitempty" bson:"numeric"`
+	TrueFalse      *TrueFalse      `json:"true_false,omitempty" bson:"true_false"`
 }
@@ -0,0 +1,113 @@
+package domain
+
+import "time"
+
+// TestVersion is an immutable snapshot of the test saved on every change of its content
+// Results keep the version they were taken against, so old results can be explained after the test is edited
+type TestVersion struct {
+	TestID    string    `json:"test_id" bson:"test_id"`
+	Version   int       `json:"version" bson:"version"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	CreatedBy int       `json:"created_by" bson:"created_by"` // User who made the change
+	Test      *Test     `json:"test,omitempty" bson:"test"`   // Test as it was stored, with answers and bank references
+}
+
+// NewVersion returns snapshot of the current version of the test
+func (t *Test) NewVersion(createdBy int, createdAt time.Time) TestVersion {
+	snapshot := *t
+	if t.Questions != nil {
+		questions := make([]*Question, len(*t.Questions))
+		copy(questions, *t.Questions)
+		snapshot.Questions = &questions
+	}
+
+	return TestVersion{
+		TestID:    *t.ID,
+		Version:   t.Version,
+		CreatedAt: createdAt,
+		CreatedBy: createdBy,
+		Test:      &snapshot,
+	}
+}
+
+// AddQuestion appends question to the end of the test
+// Returns false if test already has question with the same id
+func (t *Test) AddQuestion(q *Question) bool {
+	if t.Questions == nil {
+		t.Questions = &[]*Question{}
+	}
+	if t.questionIndex(q.ID) >= 0 {
+		return false
+	}
+
+	questions := append(*t.Questions, q)
+	t.Questions = &questions
+	return true
+}
+
+// ReplaceQuestion puts question in place of the question with the same id
+// Returns false if there is no such question
+func (t *Test) ReplaceQuestion(q *Question) bool {
+	i := t.questionIndex(q.ID)
+	if i < 0 {
+		return false
+	}
+
+	questions := make([]*Question, len(*t.Questions))
+	copy(questions, *t.Questions)
+	questions[i] = q
+	t.Questions = &questions
+	return true
+}
+
+// RemoveQuestion removes question with given id from the test
+// Returns false if there is no such question
+func (t *Test) RemoveQuestion(questionID int) bool {
+	i := t.questionIndex(questionID)
+	if i < 0 {
+		return false
+	}
+
+	questions := make([]*Question, 0, len(*t.Questions)-1)
+	questions = append(questions, (*t.Questions)[:i]...)
+	questions = append(questions, (*t.Questions)[i+1:]...)
+	t.Questions = &questions
+	return true
+}
+
+// ReorderQuestions puts questions in order of given ids
+// Returns false if ids are not a permutation of the test question ids
+func (t *Test) ReorderQuestions(ids []int) bool {
+	if t.Questions == nil || len(ids) != len(*t.Questions) {
+		return false
+	}
+
+	byID := make(map[int]*Question, len(ids))
+	for _, q := range *t.Questions {
+		byID[q.ID] = q
+	}
+
+	questions := make([]*Question, 0, len(ids))
+	for _, id := range ids {
+		q, ok := byID[id]
+		if !ok {
+			return false
+		}
+		delete(byID, id)
+		questions = append(questions, q)
+	}
+	t.Questions = &questions
+	return true
+}
+
+func (t *Test) questionIndex(questionID int) int {
+	if t.Questions == nil {
+		return -1
+	}
+	for i, q := range *t.Questions {
+		if q.ID == questionID {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,150 @@
+package user
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	jwksRequestTimeout = 5 * time.Second
+	jwksMinRefresh     = 10 * time.Second // Unknown key ids can't make the key set be fetched more often
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// jwk is the public key in JSON Web Key format (RFC 7517) served by SSO service
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// OKP
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+}
+
+type publicKey struct {
+	alg string
+	key interface{}
+}
+
+// KeySet is the cache of public keys SSO service signs tokens with
+// Keys are fetched from JWKS endpoint of SSO and refetched after ttl or when the token is signed with unknown key
+type KeySet struct {
+	url    string
+	ttl    time.Duration
+	client *http.Client
+
+	mu          sync.Mutex
+	keys        map[string]publicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+func NewKeySet(url string, ttl time.Duration) *KeySet {
+	return &KeySet{
+		url:    url,
+		ttl:    ttl,
+		client: &http.Client{Timeout: jwksRequestTimeout},
+	}
+}
+
+// Key returns algorithm and public key with given key id
+// Returns ErrUnknownKey if SSO service doesn't serve such key
+func (s *KeySet) Key(kid string) (string, interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[kid]
+	expired := time.Since(s.fetchedAt) > s.ttl
+	if (!ok || expired) && time.Since(s.attemptedAt) > jwksMinRefresh {
+		s.attemptedAt = time.Now()
+		keys, err := s.fetch()
+		if err != nil {
+			// Cached keys are still used if SSO service is unavailable
+			if !ok {
+				return "", nil, err
+			}
+		} else {
+			s.keys, s.fetchedAt = keys, time.Now()
+			key, ok = s.keys[kid]
+		}
+	}
+	if !ok {
+		return "", nil, fmt.Errorf("%w: %s", ErrUnknownKey, kid)
+	}
+
+	return key.alg, key.key, nil
+}
+
+func (s *KeySet) fetch() (map[string]publicKey, error) {
+	resp, err := s.client.Get(s.url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch jwks: unexpected status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode jwks: %w", err)
+	}
+
+	keys := make(map[string]publicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		key, err := k.publicKey()
+		if err != nil {
+			// Keys of unsupported types are skipped, tokens signed with them are rejected
+			continue
+		}
+		keys[k.Kid] = publicKey{alg: k.Alg, key: key}
+	}
+
+	return keys, nil
+}
+
+// publicKey decodes the key, only algorithms used by SSO service are supported
+func (k jwk) publicKey() (interface{}, error) {
+	b64 := base64.RawURLEncoding
+	switch {
+	case k.Kty == "RSA" && k.Alg == jwt.SigningMethodRS256.Alg():
+		n, err := b64.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := b64.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case k.Kty == "OKP" && k.Crv == "Ed25519" && k.Alg == jwt.SigningMethodEdDSA.Alg():
+		x, err := b64.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s with algorithm %s", k.Kty, k.Alg)
+	}
+}
@@ -3,8 +3,12 @@ package user
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/coddmeistr/quizzify/backend/tests/pkg/slice"
+	"github.com/golang-jwt/jwt/v5"
 	"net/http"
+	"strings"
 )
 
 const (
@@ -13,6 +17,8 @@ const (
 )
 
 const (
+	authorizationHeader   = "Authorization"
+	bearerPrefix          = "Bearer "
 	authUserInfoHeader    = "Auth-User-Info"
 	subjectUserInfoHeader = "Subject-User-Info" // Deprecated
 )
@@ -22,29 +28,145 @@ type Info struct {
 	Permissions []int `json:"permissions"`
 }
 
+// HasAny checks if user has at least one of given permissions
+// Permission ids don't form privilege order, so every accepted permission must be listed
+func (i Info) HasAny(perms ...int) bool {
+	for _, p := range perms {
+		if slice.Contains(i.Permissions, p) {
+			return true
+		}
+	}
+	return false
+}
+
 // List of permissions and their ids
 // Ids are coming from SSO service
 // So they should be synchronized with SSO service permission ids
 const (
 	Creator   = 1
-	Moderator = 2
-	Admin     = 3
+	Admin     = 2
+	Moderator = 3
+)
+
+var (
+	ErrNoCredentials = errors.New("no credentials in request")
+	ErrInvalidToken  = errors.New("invalid token")
 )
 
-func AuthMiddleware(lowestRoleForIndependentAccess int) func(http.Handler) http.Handler {
+// Authenticator resolves identity of the request user
+// Identity is taken from the bearer token issued by SSO service for the configured app
+// Tokens with kid header are verified with the public key from SSO key set, tokens without it with the app secret
+// Auth-User-Info header is used only if trustProxy is set, so the service must be deployed behind the proxy which sets it
+type Authenticator struct {
+	appID      int
+	secret     []byte
+	keys       *KeySet
+	trustProxy bool
+}
+
+// NewAuthenticator creates authenticator, keys can be nil, then only tokens signed with the secret are accepted
+func NewAuthenticator(appID int, secret string, keys *KeySet, trustProxy bool) *Authenticator {
+	return &Authenticator{
+		appID:      appID,
+		secret:     []byte(secret),
+		keys:       keys,
+		trustProxy: trustProxy,
+	}
+}
+
+// claims are the claims of the token issued by SSO service
+type claims struct {
+	UID         int   `json:"uid"`
+	AppID       int   `json:"app_id"`
+	Permissions []int `json:"permissions"`
+	jwt.RegisteredClaims
+}
+
+// Authenticate returns identity of the request user
+// Returns ErrNoCredentials if request has no identity and ErrInvalidToken if given token can't be trusted
+func (a *Authenticator) Authenticate(r *http.Request) (Info, error) {
+	if header := r.Header.Get(authorizationHeader); header != "" {
+		raw, ok := strings.CutPrefix(header, bearerPrefix)
+		if !ok {
+			return Info{}, ErrInvalidToken
+		}
+		return a.ParseToken(raw)
+	}
+
+	if a.trustProxy && r.Header.Get(authUserInfoHeader) != "" {
+		var userInfo Info
+		if err := json.Unmarshal([]byte(r.Header.Get(authUserInfoHeader)), &userInfo); err != nil || userInfo.ID == 0 {
+			return Info{}, ErrInvalidToken
+		}
+		return userInfo, nil
+	}
+
+	return Info{}, ErrNoCredentials
+}
+
+// ParseToken checks signature, expiration and app of the token and returns user identity from its claims
+func (a *Authenticator) ParseToken(raw string) (Info, error) {
+	var c claims
+	_, err := jwt.ParseWithClaims(raw, &c, a.keyFunc, jwt.WithValidMethods([]string{
+		jwt.SigningMethodHS256.Alg(),
+		jwt.SigningMethodRS256.Alg(),
+		jwt.SigningMethodEdDSA.Alg(),
+	}), jwt.WithExpirationRequired())
+	if err != nil {
+		return Info{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	if c.AppID != a.appID || c.UID == 0 {
+		return Info{}, ErrInvalidToken
+	}
+
+	return Info{
+		ID:          c.UID,
+		Permissions: c.Permissions,
+	}, nil
+}
+
+// keyFunc returns the key the token must be verified with
+// Token must be signed with exactly the algorithm of the key, so the public key can't be used as HS256 secret
+func (a *Authenticator) keyFunc(token *jwt.Token) (interface{}, error) {
+	alg, key, err := a.key(token)
+	if err != nil {
+		return nil, err
+	}
+	if token.Method.Alg() != alg {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Method.Alg())
+	}
+	return key, nil
+}
+
+func (a *Authenticator) key(token *jwt.Token) (string, interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid != "" {
+		if a.keys == nil {
+			return "", nil, ErrUnknownKey
+		}
+		return a.keys.Key(kid)
+	}
+
+	// Empty key would accept tokens signed with empty secret
+	if len(a.secret) == 0 {
+		return "", nil, ErrUnknownKey
+	}
+	return jwt.SigningMethodHS256.Alg(), a.secret, nil
+}
+
+// AuthMiddleware rejects requests without authenticated user or, if permissions are given, without any of them
+// Must be used after Middleware, which authenticates the user
+func AuthMiddleware(perms ...int) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-
-			var (
-				authUserInfo = Info{}
-			)
-			authUserJson := r.Header.Get(authUserInfoHeader)
-			if err := json.Unmarshal([]byte(authUserJson), &authUserInfo); err != nil {
+			authUserInfo, ok := AuthUserFromContext(r.Context())
+			if !ok {
 				w.WriteHeader(http.StatusUnauthorized)
 				return
 			}
 
-			if authUserInfo.ID == 0 || slice.MaxInt(authUserInfo.Permissions) < lowestRoleForIndependentAccess {
+			if len(perms) > 0 && !authUserInfo.HasAny(perms...) {
 				w.WriteHeader(http.StatusForbidden)
 				return
 			}
@@ -54,40 +176,25 @@ func AuthMiddleware(lowestRoleForIndependentAccess int) func(http.Handler) http.
 	}
 }
 
-func Middleware() func(http.Handler) http.Handler {
+// Middleware writes authenticated user to the request context
+// Requests without credentials are passed as anonymous, requests with invalid credentials are rejected
+func Middleware(auth *Authenticator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			r = writeAuthUserInfo(r)
+			userInfo, err := auth.Authenticate(r)
+			if err != nil && !errors.Is(err, ErrNoCredentials) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if err == nil {
+				r = r.WithContext(context.WithValue(r.Context(), AuthInfoKey, userInfo))
+			}
 
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
-func writeAuthUserInfo(r *http.Request) *http.Request {
-	ctx := r.Context()
-
-	var (
-		userInfo = Info{}
-		wasError = false
-	)
-	userJson := r.Header.Get(authUserInfoHeader)
-	if err := json.Unmarshal([]byte(userJson), &userInfo); err != nil {
-		wasError = true
-	}
-
-	if userInfo.ID == 0 {
-		wasError = true
-	}
-
-	if !wasError {
-		ctx = context.WithValue(ctx, AuthInfoKey, userInfo)
-		r = r.WithContext(ctx)
-	}
-
-	return r
-}
-
 // writeSubjectUserInfo TODO: Remove this method, no longer needed
 func writeSubjectUserInfo(r *http.Request) *http.Request {
 	ctx := r.Context()
@@ -0,0 +1,330 @@
+package user
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMiddleware(t *testing.T) {
+	const (
+		appID  = 1
+		secret = "test-secret"
+	)
+	newToken := func(secret string, appID int, exp time.Time) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"uid":         42,
+			"login":       "user",
+			"exp":         exp.Unix(),
+			"app_id":      appID,
+			"permissions": []int{Moderator},
+		})
+		s, err := token.SignedString([]byte(secret))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	valid := newToken(secret, appID, time.Now().Add(time.Hour))
+
+	tc := []struct {
+		name       string
+		trustProxy bool
+		headers    map[string]string
+		wantStatus int
+		wantUser   *Info
+	}{
+		{
+			name:       "valid token",
+			headers:    map[string]string{"Authorization": "Bearer " + valid},
+			wantStatus: http.StatusOK,
+			wantUser:   &Info{ID: 42, Permissions: []int{Moderator}},
+		},
+		{
+			name:       "anonymous",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "expired token",
+			headers:    map[string]string{"Authorization": "Bearer " + newToken(secret, appID, time.Now().Add(-time.Hour))},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "token of other app",
+			headers:    map[string]string{"Authorization": "Bearer " + newToken(secret, 2, time.Now().Add(time.Hour))},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "token with wrong signature",
+			headers:    map[string]string{"Authorization": "Bearer " + newToken("other-secret", appID, time.Now().Add(time.Hour))},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "not bearer authorization",
+			headers:    map[string]string{"Authorization": "Basic dXNlcjpwYXNz"},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "user info header without trusted proxy",
+			headers:    map[string]string{"Auth-User-Info": `{"id": 1, "permissions": [3]}`},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "user info header from trusted proxy",
+			trustProxy: true,
+			headers:    map[string]string{"Auth-User-Info": `{"id": 1, "permissions": [3]}`},
+			wantStatus: http.StatusOK,
+			wantUser:   &Info{ID: 1, Permissions: []int{Moderator}},
+		},
+	}
+
+	for _, tt := range tc {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				got    Info
+				gotSet bool
+			)
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, gotSet = AuthUserFromContext(r.Context())
+			})
+			h := Middleware(NewAuthenticator(appID, secret, nil, tt.trustProxy))(next)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantUser != nil {
+				assert.True(t, gotSet)
+				assert.Equal(t, *tt.wantUser, got)
+			} else {
+				assert.False(t, gotSet)
+			}
+		})
+	}
+}
+
+func TestAuthenticator_EmptySecret(t *testing.T) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"uid":    1,
+		"exp":    time.Now().Add(time.Hour).Unix(),
+		"app_id": 1,
+	})
+	s, _ := token.SignedString([]byte{})
+
+	_, err := NewAuthenticator(1, "", nil, false).ParseToken(s)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestAuthenticator_KeySet(t *testing.T) {
+	const (
+		appID  = 1
+		secret = "test-secret"
+	)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, unknownKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	b64 := base64.RawURLEncoding
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA", "kid": "rsa", "use": "sig", "alg": "RS256",
+				"n": b64.EncodeToString(rsaKey.N.Bytes()),
+				"e": b64.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+			{
+				"kty": "OKP", "kid": "ed", "use": "sig", "alg": "EdDSA",
+				"crv": "Ed25519", "x": b64.EncodeToString(edPub),
+			},
+		},
+	}
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_ = json.NewEncoder(w).Encode(jwks)
+	}))
+	defer srv.Close()
+
+	newToken := func(method jwt.SigningMethod, kid string, key interface{}) string {
+		token := jwt.NewWithClaims(method, jwt.MapClaims{
+			"uid":         42,
+			"exp":         time.Now().Add(time.Hour).Unix(),
+			"app_id":      appID,
+			"permissions": []int{Creator},
+		})
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		s, err := token.SignedString(key)
+		require.NoError(t, err)
+		return s
+	}
+	// Public key used as HMAC secret must not verify the token
+	rsaPub, err := json.Marshal(jwks["keys"].([]map[string]string)[0])
+	require.NoError(t, err)
+
+	tc := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{
+			name:  "rs256 key",
+			token: newToken(jwt.SigningMethodRS256, "rsa", rsaKey),
+		},
+		{
+			name:  "eddsa key",
+			token: newToken(jwt.SigningMethodEdDSA, "ed", crypto.Signer(edKey)),
+		},
+		{
+			name:  "hs256 without kid",
+			token: newToken(jwt.SigningMethodHS256, "", []byte(secret)),
+		},
+		{
+			name:    "hs256 with kid",
+			token:   newToken(jwt.SigningMethodHS256, "rsa", rsaPub),
+			wantErr: true,
+		},
+		{
+			name:    "algorithm doesn't match the key",
+			token:   newToken(jwt.SigningMethodEdDSA, "rsa", crypto.Signer(edKey)),
+			wantErr: true,
+		},
+		{
+			name:    "unknown kid",
+			token:   newToken(jwt.SigningMethodEdDSA, "other", crypto.Signer(unknownKey)),
+			wantErr: true,
+		},
+		{
+			name:    "known kid with wrong signature",
+			token:   newToken(jwt.SigningMethodEdDSA, "ed", crypto.Signer(unknownKey)),
+			wantErr: true,
+		},
+	}
+
+	auth := NewAuthenticator(appID, secret, NewKeySet(srv.URL, time.Hour), false)
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := auth.ParseToken(tt.token)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidToken)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, Info{ID: 42, Permissions: []int{Creator}}, got)
+		})
+	}
+
+	// Key set is cached, unknown kid doesn't make it be fetched again right away
+	assert.Equal(t, 1, requests)
+}
+
+func TestAuthenticator_KeyWithoutKeySet(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+		"uid":    1,
+		"exp":    time.Now().Add(time.Hour).Unix(),
+		"app_id": 1,
+	})
+	token.Header["kid"] = "ed"
+	s, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	_, err = NewAuthenticator(1, "secret", nil, false).ParseToken(s)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestAuthMiddleware_SSOPermissions(t *testing.T) {
+	const appID = 1
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "OKP", "kid": "ed", "use": "sig", "alg": "EdDSA",
+				"crv": "Ed25519", "x": base64.RawURLEncoding.EncodeToString(pub),
+			}},
+		})
+	}))
+	defer srv.Close()
+
+	// Token is issued by SSO, where permission ids are 1 - creator, 2 - administrator, 3 - moderator
+	newToken := func(perms []int) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+			"uid":         42,
+			"exp":         time.Now().Add(time.Hour).Unix(),
+			"app_id":      appID,
+			"permissions": perms,
+		})
+		token.Header["kid"] = "ed"
+		s, err := token.SignedString(key)
+		require.NoError(t, err)
+		return s
+	}
+
+	tc := []struct {
+		name       string
+		perms      []int
+		wantAdmin  bool
+		wantStatus int
+	}{
+		{
+			name:       "moderator",
+			perms:      []int{3},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "administrator",
+			perms:      []int{2},
+			wantAdmin:  true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "creator and moderator",
+			perms:      []int{1, 3},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	auth := NewAuthenticator(appID, "", NewKeySet(srv.URL, time.Hour), false)
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			token := newToken(tt.perms)
+
+			info, err := auth.ParseToken(token)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAdmin, info.HasAny(Admin))
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			h := Middleware(auth)(AuthMiddleware(Admin)(next))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package testsservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/coddmeistr/quizzify/backend/tests/internal/domain"
+	"github.com/coddmeistr/quizzify/backend/tests/internal/helpers/user"
+	"github.com/coddmeistr/quizzify/backend/tests/internal/storage"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// canManageBank checks if user is the bank creator or moderator
+// Same rule is used to reference bank questions in tests
+func canManageBank(authUser user.Info, bank *domain.QuestionBank) bool {
+	return (bank.UserID != nil && authUser.ID == *bank.UserID) || authUser.HasAny(user.Moderator, user.Admin)
+}
+
+func (s *Service) CreateQuestionBank(ctx context.Context, bank domain.QuestionBank) (string, error) {
+	const op = "service.testsservice.CreateQuestionBank"
+	log := s.log.With(zap.String("op", op))
+	log.Info("creating new question bank")
+
+	authUser, ok := user.AuthUserFromContext(ctx)
+	if !ok {
+		log.Error("forbidden action")
+		return "", fmt.Errorf("%s: %w", op, ErrNoRights)
+	}
+
+	id := uuid.New().String()
+	bank.ID = &id
+	bank.UserID = &authUser.ID
+	bank.Revision = 1
+
+	if err := s.validation.ValidateQuestionBank(bank); err != nil {
+		log.Error("failed to validate question bank", zap.Error(err))
+		return "", fmt.Errorf("%s: %w", op, ErrFailedTestValidation)
+	}
+
+	if err := s.storage.CreateQuestionBank(ctx, bank); err != nil {
+		log.Error("failed to create question bank", zap.Error(err))
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("question bank was created successfully")
+	return id, nil
+}
+
+// GetQuestionBanks returns banks of the authorized user. Moderators get banks of all users
+func (s *Service) GetQuestionBanks(ctx context.Context, query domain.BanksQuery) ([]*domain.QuestionBank, int64, error) {
+	const op = "service.testsservice.GetQuestionBanks"
+	log := s.log.With(zap.String("op", op))
+	log.Info("getting question banks")
+
+	authUser, ok := user.AuthUserFromContext(ctx)
+	if !ok {
+		log.Error("forbidden action")
+		return nil, 0, fmt.Errorf("%s: %w", op, ErrNoRights)
+	}
+	if !authUser.HasAny(user.Moderator, user.Admin) {
+		query.CreatorID = &authUser.ID
+	}
+
+	banks, total, err := s.storage.GetQuestionBanks(ctx, query)
+	if err != nil {
+		log.Error("failed to get question banks", zap.Error(err))
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("question banks were gotten successfully")
+	return banks, total, nil
+}
+
+func (s *Service) GetQuestionBankByID(ctx context.Context, bankID string) (*domain.QuestionBank, error) {
+	const op = "service.testsservice.GetQuestionBankByID"
+	log := s.log.With(zap.String("op", op))
+	log.Info("getting question bank")
+
+	bank, err := s.getManagedBank(ctx, bankID)
+	if err != nil {
+		log.Error("failed to get question bank", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("question bank was gotten successfully")
+	return bank, nil
+}
+
+// UpdateQuestionBank replaces content of the bank
+// Tests that reference the bank start showing updated questions, already saved results keep their snapshots
+// Update is rejected if any of these tests becomes invalid with updated questions
+func (s *Service) UpdateQuestionBank(ctx context.Context, bankID string, update domain.QuestionBank) error {
+	const op = "service.testsservice.UpdateQuestionBank"
+	log := s.log.With(zap.String("op", op))
+	log.Info("updating question bank")
+
+	bank, err := s.getManagedBank(ctx, bankID)
+	if err != nil {
+		log.Error("failed to get question bank", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	update.ID = bank.ID
+	update.UserID = bank.UserID
+	if err := s.validation.ValidateQuestionBank(update); err != nil {
+		log.Error("failed to validate question bank", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, ErrFailedTestValidation)
+	}
+
+	if err := s.checkTestsUsingBank(ctx, update); err != nil {
+		if errors.Is(err, ErrBankBreaksTests) {
+			log.Warn("question bank update breaks tests using it", zap.Error(err))
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		log.Error("failed to check tests using question bank", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.storage.UpdateQuestionBank(ctx, bankID, update); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("question bank not found")
+			return fmt.Errorf("%s: %w", op, ErrNotFound)
+		}
+		log.Error("failed to update question bank", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("question bank was updated successfully")
+	return nil
+}
+
+// DeleteQuestionBank deletes the bank only if no test references it
+func (s *Service) DeleteQuestionBank(ctx context.Context, bankID string) error {
+	const op = "service.testsservice.DeleteQuestionBank"
+	log := s.log.With(zap.String("op", op))
+	log.Info("deleting question bank")
+
+	if _, err := s.getManagedBank(ctx, bankID); err != nil {
+		log.Error("failed to get question bank", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	used, err := s.storage.CountTestsUsingBank(ctx, bankID)
+	if err != nil {
+		log.Error("failed to count tests using question bank", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if used > 0 {
+		log.Warn("question bank is used by tests", zap.Int64("tests", used))
+		return fmt.Errorf("%s: %w", op, ErrBankInUse)
+	}
+
+	if err := s.storage.DeleteQuestionBank(ctx, bankID); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("question bank not found")
+			return fmt.Errorf("%s: %w", op, ErrNotFound)
+		}
+		log.Error("failed to delete question bank", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("question bank was deleted successfully")
+	return nil
+}
+
+// getManagedBank returns bank with answers if the authorized user can manage it
+func (s *Service) getManagedBank(ctx context.Context, bankID string) (*domain.QuestionBank, error) {
+	bank, err := s.storage.GetQuestionBankByID(ctx, bankID, true)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	authUser, ok := user.AuthUserFromContext(ctx)
+	if !ok || !canManageBank(authUser, bank) {
+		return nil, ErrNoRights
+	}
+
+	return bank, nil
+}
+
+// expandBankQuestions resolves bank references of the new test, so it can be validated as a whole
+// Creator of the test must be able to manage every referenced bank
+func (s *Service) expandBankQuestions(ctx context.Context, test *domain.Test) error {
+	ids := test.BankIDs()
+	if len(ids) == 0 {
+		return nil
+	}
+
+	banks, err := s.storage.GetQuestionBanksByIDs(ctx, ids, true)
+	if err != nil {
+		return err
+	}
+
+	authUser, ok := user.AuthUserFromContext(ctx)
+	if !ok {
+		return ErrNoRights
+	}
+	for _, b := range banks {
+		if !canManageBank(authUser, b) {
+			return ErrNoRights
+		}
+	}
+
+	if !test.ExpandBankQuestions(banks) {
+		return ErrFailedTestValidation
+	}
+
+	return nil
+}
+
+// checkTestsUsingBank validates every test that references the bank, as if the bank was already updated
+// Returns ErrBankBreaksTests if referenced question is removed or test becomes invalid with updated questions
+func (s *Service) checkTestsUsingBank(ctx context.Context, update domain.QuestionBank) error {
+	tests, err := s.storage.GetTestsUsingBank(ctx, *update.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, test := range tests {
+		ids := make([]string, 0)
+		for _, id := range test.BankIDs() {
+			if id != *update.ID {
+				ids = append(ids, id)
+			}
+		}
+
+		banks := []*domain.QuestionBank{&update}
+		if len(ids) > 0 {
+			others, err := s.storage.GetQuestionBanksByIDs(ctx, ids, true)
+			if err != nil {
+				return err
+			}
+			banks = append(banks, others...)
+		}
+
+		if !test.ExpandBankQuestions(banks) {
+			return fmt.Errorf("test %s: %w", *test.ID, ErrBankBreaksTests)
+		}
+		if err := s.validation.ValidateTest(*test); err != nil {
+			return fmt.Errorf("test %s: %w: %s", *test.ID, ErrBankBreaksTests, err.Error())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package testsservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/coddmeistr/quizzify/backend/tests/internal/domain"
+	"github.com/coddmeistr/quizzify/backend/tests/internal/helpers/user"
+	"github.com/coddmeistr/quizzify/backend/tests/internal/storage"
+	p "github.com/coddmeistr/quizzify/backend/tests/pkg/pointer"
+	"go.uber.org/zap"
+)
+
+// GradeAnswers assigns points and comments to answers that wait for manual grading
+// Score of the result is recalculated, but it stays pending until it is finalized
+func (s *Service) GradeAnswers(ctx context.Context, resultID string, grades []domain.Grade) (*domain.Result, error) {
+	const op = "service.testsservice.GradeAnswers"
+	log := s.log.With(zap.String("op", op))
+	log.Info("grading answers")
+
+	result, _, authUser, err := s.getReviewedResult(ctx, resultID)
+	if err != nil {
+		log.Error("failed to get result for review", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	questions := make(map[int]*domain.QuestionResult, len(*result.Questions))
+	for _, q := range *result.Questions {
+		questions[q.QuestionID] = q
+	}
+	for _, g := range grades {
+		q, ok := questions[g.QuestionID]
+		if !ok || !q.Pending {
+			log.Error("answer doesn't wait for grading", zap.Int("question_id", g.QuestionID))
+			return nil, fmt.Errorf("%s: %w", op, ErrFailedTestValidation)
+		}
+		if g.Points < 0 || g.Points > q.MaxPoints {
+			log.Error("points are out of range", zap.Int("question_id", g.QuestionID))
+			return nil, fmt.Errorf("%s: %w", op, ErrFailedTestValidation)
+		}
+		q.Points = g.Points
+		q.Comment = g.Comment
+		q.Graded = true
+		q.GradedBy = &authUser.ID
+	}
+
+	result.Score()
+	if err := s.storage.UpdateResultScore(ctx, *result); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("result was already finalized")
+			return nil, fmt.Errorf("%s: %w", op, ErrResultNotPending)
+		}
+		log.Error("failed to save grades", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("answers were graded successfully")
+	return result, nil
+}
+
+// FinalizeResult makes score of the result final after all pending answers are graded
+// Percentage and final percentage are recalculated
+func (s *Service) FinalizeResult(ctx context.Context, resultID string) (*domain.Result, error) {
+	const op = "service.testsservice.FinalizeResult"
+	log := s.log.With(zap.String("op", op))
+	log.Info("finalizing result")
+
+	result, test, _, err := s.getReviewedResult(ctx, resultID)
+	if err != nil {
+		log.Error("failed to get result for review", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, q := range *result.Questions {
+		if !q.Pending {
+			continue
+		}
+		if !q.Graded {
+			log.Warn("answer is not graded", zap.Int("question_id", q.QuestionID))
+			return nil, fmt.Errorf("%s: %w", op, ErrNotGraded)
+		}
+		q.Pending = false
+		percentage := 0
+		if q.MaxPoints > 0 {
+			percentage = q.Points * 100 / q.MaxPoints
+		}
+		q.Status = domain.QuestionResultStatus(percentage)
+	}
+
+	previous, err := s.storage.GetUserResults(ctx, result.TestID, result.UserID)
+	if err != nil {
+		log.Error("failed to get user results", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	earlier := make([]*domain.Result, 0, len(previous))
+	for _, r := range previous {
+		if r.Attempt < result.Attempt {
+			earlier = append(earlier, r)
+		}
+	}
+
+	result.Pending = false
+	result.Score()
+	result.FinalPercentage = p.Int(finalPercentage(test, earlier, *result.Percentage))
+
+	if err := s.storage.UpdateResultScore(ctx, *result); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("result was already finalized")
+			return nil, fmt.Errorf("%s: %w", op, ErrResultNotPending)
+		}
+		log.Error("failed to save result", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("result was finalized successfully")
+	return result, nil
+}
+
+// getReviewedResult returns pending result and its test if the authorized user is the test creator or moderator
+func (s *Service) getReviewedResult(ctx context.Context, resultID string) (*domain.Result, *domain.Test, user.Info, error) {
+	authUser, ok := user.AuthUserFromContext(ctx)
+	if !ok {
+		return nil, nil, authUser, ErrNoRights
+	}
+
+	result, err := s.storage.GetResultByID(ctx, resultID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, authUser, ErrNotFound
+		}
+		return nil, nil, authUser, err
+	}
+
+	test, err := s.storage.GetTestByID(ctx, result.TestID, false)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, authUser, ErrNotFound
+		}
+		return nil, nil, authUser, err
+	}
+
+	if authUser.ID != *test.UserID && !authUser.HasAny(user.Moderator, user.Admin) {
+		return nil, nil, authUser, ErrNoRights
+	}
+	if !result.Pending || result.Questions == nil {
+		return nil, nil, authUser, ErrResultNotPending
+	}
+
+	return result, test, authUser, nil
+}
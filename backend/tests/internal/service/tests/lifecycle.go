@@ -0,0 +1,105 @@
+package testsservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/coddmeistr/quizzify/backend/tests/internal/domain"
+	"github.com/coddmeistr/quizzify/backend/tests/internal/helpers/user"
+	"github.com/coddmeistr/quizzify/backend/tests/internal/storage"
+	"go.uber.org/zap"
+)
+
+// PublishTest makes draft or archived test visible to everyone and open for submissions
+// If revision is not nil, test is published only if it wasn't changed since this revision
+func (s *Service) PublishTest(ctx context.Context, testID string, revision *int) error {
+	const op = "service.testsservice.PublishTest"
+	log := s.log.With(zap.String("op", op))
+	log.Info("publishing test")
+
+	if err := s.changeTestStatus(ctx, testID, domain.TestStatusPublished, revision); err != nil {
+		log.Error("failed to publish test", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("test was published successfully")
+	return nil
+}
+
+// ArchiveTest closes published test for submissions, but it stays visible with all its results
+// If revision is not nil, test is archived only if it wasn't changed since this revision
+func (s *Service) ArchiveTest(ctx context.Context, testID string, revision *int) error {
+	const op = "service.testsservice.ArchiveTest"
+	log := s.log.With(zap.String("op", op))
+	log.Info("archiving test")
+
+	if err := s.changeTestStatus(ctx, testID, domain.TestStatusArchived, revision); err != nil {
+		log.Error("failed to archive test", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("test was archived successfully")
+	return nil
+}
+
+// changeTestStatus moves test to the given status, allowed for test creator and admins
+func (s *Service) changeTestStatus(ctx context.Context, testID string, status string, revision *int) error {
+	test, err := s.storage.GetTestByID(ctx, testID, false)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	authUser, ok := user.AuthUserFromContext(ctx)
+	if !ok || (authUser.ID != *test.UserID && !authUser.HasAny(user.Admin)) {
+		if !canSeeTest(ctx, test) {
+			return ErrNotFound
+		}
+		return ErrNoRights
+	}
+
+	if err := checkRevision(test, revision); err != nil {
+		return err
+	}
+	if !test.CanTransitionTo(status) {
+		return ErrInvalidTransition
+	}
+
+	if err := s.storage.UpdateTestStatus(ctx, testID, status, test.Revision); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return ErrNotFound
+		}
+		if errors.Is(err, storage.ErrConflict) {
+			return ErrConflict
+		}
+		return err
+	}
+
+	return nil
+}
+
+// canSeeTest checks if the authorized user can see the test
+// Drafts are visible only to their creators and admins
+func canSeeTest(ctx context.Context, test *domain.Test) bool {
+	if test.TestStatus() != domain.TestStatusDraft {
+		return true
+	}
+
+	authUser, ok := user.AuthUserFromContext(ctx)
+	return ok && (authUser.ID == *test.UserID || authUser.HasAny(user.Admin))
+}
+
+// hideDrafts restricts tests query to the tests that the authorized user can see
+func hideDrafts(ctx context.Context, query *domain.TestsQuery) {
+	authUser, ok := user.AuthUserFromContext(ctx)
+	if ok && authUser.HasAny(user.Admin) {
+		return
+	}
+
+	query.HideDrafts = true
+	if ok {
+		query.ViewerID = &authUser.ID
+	}
+}
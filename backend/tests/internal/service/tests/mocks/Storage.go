@@ -0,0 +1,795 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/coddmeistr/quizzify/backend/tests/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// Storage is an autogenerated mock type for the Storage type
+type Storage struct {
+	mock.Mock
+}
+
+// CountTestsUsingBank provides a mock function with given fields: ctx, bankID
+func (_m *Storage) CountTestsUsingBank(ctx context.Context, bankID string) (int64, error) {
+	ret := _m.Called(ctx, bankID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountTestsUsingBank")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int64, error)); ok {
+		return rf(ctx, bankID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int64); ok {
+		r0 = rf(ctx, bankID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, bankID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateAttempt provides a mock function with given fields: ctx, attempt
+func (_m *Storage) CreateAttempt(ctx context.Context, attempt domain.Attempt) error {
+	ret := _m.Called(ctx, attempt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAttempt")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Attempt) error); ok {
+		r0 = rf(ctx, attempt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateQuestionBank provides a mock function with given fields: ctx, bank
+func (_m *Storage) CreateQuestionBank(ctx context.Context, bank domain.QuestionBank) error {
+	ret := _m.Called(ctx, bank)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateQuestionBank")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.QuestionBank) error); ok {
+		r0 = rf(ctx, bank)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateTest provides a mock function with given fields: ctx, test
+func (_m *Storage) CreateTest(ctx context.Context, test domain.Test) error {
+	ret := _m.Called(ctx, test)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateTest")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Test) error); ok {
+		r0 = rf(ctx, test)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateTestVersion provides a mock function with given fields: ctx, version
+func (_m *Storage) CreateTestVersion(ctx context.Context, version domain.TestVersion) error {
+	ret := _m.Called(ctx, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateTestVersion")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.TestVersion) error); ok {
+		r0 = rf(ctx, version)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteQuestionBank provides a mock function with given fields: ctx, bankID
+func (_m *Storage) DeleteQuestionBank(ctx context.Context, bankID string) error {
+	ret := _m.Called(ctx, bankID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteQuestionBank")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, bankID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteTest provides a mock function with given fields: ctx, testID
+func (_m *Storage) DeleteTest(ctx context.Context, testID string) error {
+	ret := _m.Called(ctx, testID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteTest")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, testID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteTestVersion provides a mock function with given fields: ctx, testID, version
+func (_m *Storage) DeleteTestVersion(ctx context.Context, testID string, version int) error {
+	ret := _m.Called(ctx, testID, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteTestVersion")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) error); ok {
+		r0 = rf(ctx, testID, version)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteUserResult provides a mock function with given fields: ctx, resultID
+func (_m *Storage) DeleteUserResult(ctx context.Context, resultID string) error {
+	ret := _m.Called(ctx, resultID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteUserResult")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, resultID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FinishAttempt provides a mock function with given fields: ctx, attemptID, submittedAt, resultID
+func (_m *Storage) FinishAttempt(ctx context.Context, attemptID string, submittedAt time.Time, resultID string) error {
+	ret := _m.Called(ctx, attemptID, submittedAt, resultID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FinishAttempt")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, string) error); ok {
+		r0 = rf(ctx, attemptID, submittedAt, resultID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAttemptByID provides a mock function with given fields: ctx, attemptID
+func (_m *Storage) GetAttemptByID(ctx context.Context, attemptID string) (*domain.Attempt, error) {
+	ret := _m.Called(ctx, attemptID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAttemptByID")
+	}
+
+	var r0 *domain.Attempt
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.Attempt, error)); ok {
+		return rf(ctx, attemptID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.Attempt); ok {
+		r0 = rf(ctx, attemptID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Attempt)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, attemptID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetQuestionBankByID provides a mock function with given fields: ctx, bankID, includeAnswers
+func (_m *Storage) GetQuestionBankByID(ctx context.Context, bankID string, includeAnswers bool) (*domain.QuestionBank, error) {
+	ret := _m.Called(ctx, bankID, includeAnswers)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetQuestionBankByID")
+	}
+
+	var r0 *domain.QuestionBank
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) (*domain.QuestionBank, error)); ok {
+		return rf(ctx, bankID, includeAnswers)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) *domain.QuestionBank); ok {
+		r0 = rf(ctx, bankID, includeAnswers)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.QuestionBank)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, bool) error); ok {
+		r1 = rf(ctx, bankID, includeAnswers)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetQuestionBanks provides a mock function with given fields: ctx, query
+func (_m *Storage) GetQuestionBanks(ctx context.Context, query domain.BanksQuery) ([]*domain.QuestionBank, int64, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetQuestionBanks")
+	}
+
+	var r0 []*domain.QuestionBank
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.BanksQuery) ([]*domain.QuestionBank, int64, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.BanksQuery) []*domain.QuestionBank); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.QuestionBank)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.BanksQuery) int64); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, domain.BanksQuery) error); ok {
+		r2 = rf(ctx, query)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetQuestionBanksByIDs provides a mock function with given fields: ctx, bankIDs, includeAnswers
+func (_m *Storage) GetQuestionBanksByIDs(ctx context.Context, bankIDs []string, includeAnswers bool) ([]*domain.QuestionBank, error) {
+	ret := _m.Called(ctx, bankIDs, includeAnswers)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetQuestionBanksByIDs")
+	}
+
+	var r0 []*domain.QuestionBank
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string, bool) ([]*domain.QuestionBank, error)); ok {
+		return rf(ctx, bankIDs, includeAnswers)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string, bool) []*domain.QuestionBank); ok {
+		r0 = rf(ctx, bankIDs, includeAnswers)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.QuestionBank)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string, bool) error); ok {
+		r1 = rf(ctx, bankIDs, includeAnswers)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRawTestByID provides a mock function with given fields: ctx, testID
+func (_m *Storage) GetRawTestByID(ctx context.Context, testID string) (*domain.Test, error) {
+	ret := _m.Called(ctx, testID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRawTestByID")
+	}
+
+	var r0 *domain.Test
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.Test, error)); ok {
+		return rf(ctx, testID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.Test); ok {
+		r0 = rf(ctx, testID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Test)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, testID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetResultByID provides a mock function with given fields: ctx, resultID
+func (_m *Storage) GetResultByID(ctx context.Context, resultID string) (*domain.Result, error) {
+	ret := _m.Called(ctx, resultID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetResultByID")
+	}
+
+	var r0 *domain.Result
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.Result, error)); ok {
+		return rf(ctx, resultID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.Result); ok {
+		r0 = rf(ctx, resultID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Result)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, resultID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetResults provides a mock function with given fields: ctx, query
+func (_m *Storage) GetResults(ctx context.Context, query domain.ResultsQuery) ([]*domain.Result, int64, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetResults")
+	}
+
+	var r0 []*domain.Result
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ResultsQuery) ([]*domain.Result, int64, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ResultsQuery) []*domain.Result); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Result)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.ResultsQuery) int64); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, domain.ResultsQuery) error); ok {
+		r2 = rf(ctx, query)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetTestByID provides a mock function with given fields: ctx, testID, includeAnswers
+func (_m *Storage) GetTestByID(ctx context.Context, testID string, includeAnswers bool) (*domain.Test, error) {
+	ret := _m.Called(ctx, testID, includeAnswers)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTestByID")
+	}
+
+	var r0 *domain.Test
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) (*domain.Test, error)); ok {
+		return rf(ctx, testID, includeAnswers)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) *domain.Test); ok {
+		r0 = rf(ctx, testID, includeAnswers)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Test)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, bool) error); ok {
+		r1 = rf(ctx, testID, includeAnswers)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTestVersion provides a mock function with given fields: ctx, testID, version
+func (_m *Storage) GetTestVersion(ctx context.Context, testID string, version int) (*domain.TestVersion, error) {
+	ret := _m.Called(ctx, testID, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTestVersion")
+	}
+
+	var r0 *domain.TestVersion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) (*domain.TestVersion, error)); ok {
+		return rf(ctx, testID, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) *domain.TestVersion); ok {
+		r0 = rf(ctx, testID, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TestVersion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, testID, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTestVersions provides a mock function with given fields: ctx, testID
+func (_m *Storage) GetTestVersions(ctx context.Context, testID string) ([]*domain.TestVersion, error) {
+	ret := _m.Called(ctx, testID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTestVersions")
+	}
+
+	var r0 []*domain.TestVersion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*domain.TestVersion, error)); ok {
+		return rf(ctx, testID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*domain.TestVersion); ok {
+		r0 = rf(ctx, testID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.TestVersion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, testID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTests provides a mock function with given fields: ctx, query
+func (_m *Storage) GetTests(ctx context.Context, query domain.TestsQuery) ([]*domain.Test, int64, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTests")
+	}
+
+	var r0 []*domain.Test
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.TestsQuery) ([]*domain.Test, int64, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.TestsQuery) []*domain.Test); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Test)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.TestsQuery) int64); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, domain.TestsQuery) error); ok {
+		r2 = rf(ctx, query)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetTestsUsingBank provides a mock function with given fields: ctx, bankID
+func (_m *Storage) GetTestsUsingBank(ctx context.Context, bankID string) ([]*domain.Test, error) {
+	ret := _m.Called(ctx, bankID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTestsUsingBank")
+	}
+
+	var r0 []*domain.Test
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*domain.Test, error)); ok {
+		return rf(ctx, bankID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*domain.Test); ok {
+		r0 = rf(ctx, bankID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Test)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, bankID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserResults provides a mock function with given fields: ctx, testID, userID
+func (_m *Storage) GetUserResults(ctx context.Context, testID string, userID int) ([]*domain.Result, error) {
+	ret := _m.Called(ctx, testID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserResults")
+	}
+
+	var r0 []*domain.Result
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]*domain.Result, error)); ok {
+		return rf(ctx, testID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []*domain.Result); ok {
+		r0 = rf(ctx, testID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Result)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, testID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReplaceTest provides a mock function with given fields: ctx, test, revision
+func (_m *Storage) ReplaceTest(ctx context.Context, test domain.Test, revision int) error {
+	ret := _m.Called(ctx, test, revision)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplaceTest")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Test, int) error); ok {
+		r0 = rf(ctx, test, revision)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveUserResult provides a mock function with given fields: ctx, result
+func (_m *Storage) SaveUserResult(ctx context.Context, result domain.Result) error {
+	ret := _m.Called(ctx, result)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveUserResult")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Result) error); ok {
+		r0 = rf(ctx, result)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SearchTests provides a mock function with given fields: ctx, text, query
+func (_m *Storage) SearchTests(ctx context.Context, text string, query domain.TestsQuery) ([]*domain.Test, int64, error) {
+	ret := _m.Called(ctx, text, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchTests")
+	}
+
+	var r0 []*domain.Test
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.TestsQuery) ([]*domain.Test, int64, error)); ok {
+		return rf(ctx, text, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.TestsQuery) []*domain.Test); ok {
+		r0 = rf(ctx, text, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Test)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, domain.TestsQuery) int64); ok {
+		r1 = rf(ctx, text, query)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, domain.TestsQuery) error); ok {
+		r2 = rf(ctx, text, query)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// UpdateAttemptAnswers provides a mock function with given fields: ctx, attemptID, answers
+func (_m *Storage) UpdateAttemptAnswers(ctx context.Context, attemptID string, answers []domain.UserAnswerModel) error {
+	ret := _m.Called(ctx, attemptID, answers)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateAttemptAnswers")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []domain.UserAnswerModel) error); ok {
+		r0 = rf(ctx, attemptID, answers)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateQuestionBank provides a mock function with given fields: ctx, bankID, bank
+func (_m *Storage) UpdateQuestionBank(ctx context.Context, bankID string, bank domain.QuestionBank) error {
+	ret := _m.Called(ctx, bankID, bank)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateQuestionBank")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.QuestionBank) error); ok {
+		r0 = rf(ctx, bankID, bank)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateResultScore provides a mock function with given fields: ctx, result
+func (_m *Storage) UpdateResultScore(ctx context.Context, result domain.Result) error {
+	ret := _m.Called(ctx, result)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateResultScore")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Result) error); ok {
+		r0 = rf(ctx, result)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateTest provides a mock function with given fields: ctx, testID, patch, revision
+func (_m *Storage) UpdateTest(ctx context.Context, testID string, patch domain.MergePatch, revision int) error {
+	ret := _m.Called(ctx, testID, patch, revision)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateTest")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.MergePatch, int) error); ok {
+		r0 = rf(ctx, testID, patch, revision)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateTestStatus provides a mock function with given fields: ctx, testID, status, revision
+func (_m *Storage) UpdateTestStatus(ctx context.Context, testID string, status string, revision int) error {
+	ret := _m.Called(ctx, testID, status, revision)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateTestStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) error); ok {
+		r0 = rf(ctx, testID, status, revision)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewStorage creates a new instance of Storage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStorage(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Storage {
+	mock := &Storage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
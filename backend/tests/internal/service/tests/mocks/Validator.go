@@ -0,0 +1,81 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "github.com/coddmeistr/quizzify/backend/tests/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Validator is an autogenerated mock type for the Validator type
+type Validator struct {
+	mock.Mock
+}
+
+// ValidateQuestionBank provides a mock function with given fields: bank
+func (_m *Validator) ValidateQuestionBank(bank domain.QuestionBank) error {
+	ret := _m.Called(bank)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ValidateQuestionBank")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(domain.QuestionBank) error); ok {
+		r0 = rf(bank)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ValidateTest provides a mock function with given fields: test
+func (_m *Validator) ValidateTest(test domain.Test) error {
+	ret := _m.Called(test)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ValidateTest")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(domain.Test) error); ok {
+		r0 = rf(test)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ValidateUserAnswers provides a mock function with given fields: q, a
+func (_m *Validator) ValidateUserAnswers(q domain.Question, a domain.UserAnswerModel) error {
+	ret := _m.Called(q, a)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ValidateUserAnswers")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(domain.Question, domain.UserAnswerModel) error); ok {
+		r0 = rf(q, a)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewValidator creates a new instance of Validator. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewValidator(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Validator {
+	mock := &Validator{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
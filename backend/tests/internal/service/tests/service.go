@@ -2,6 +2,7 @@ package testsservice
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/coddmeistr/quizzify/backend/tests/internal/config"
@@ -9,34 +10,79 @@ import (
 	"github.com/coddmeistr/quizzify/backend/tests/internal/helpers/user"
 	"github.com/coddmeistr/quizzify/backend/tests/internal/storage"
 	p "github.com/coddmeistr/quizzify/backend/tests/pkg/pointer"
-	"github.com/coddmeistr/quizzify/backend/tests/pkg/slice"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 //go:generate mockery --name Storage
 type Storage interface {
 	CreateTest(ctx context.Context, test domain.Test) error
-	UpdateTest(ctx context.Context, testID string, test domain.Test) error
+	UpdateTest(ctx context.Context, testID string, patch domain.MergePatch, revision int) error
 	DeleteTest(ctx context.Context, testID string) error
 	GetTestByID(ctx context.Context, testID string, includeAnswers bool) (*domain.Test, error)
-	GetTests(ctx context.Context) ([]*domain.Test, error)
+	GetTests(ctx context.Context, query domain.TestsQuery) ([]*domain.Test, int64, error)
+	SearchTests(ctx context.Context, text string, query domain.TestsQuery) ([]*domain.Test, int64, error)
 	SaveUserResult(ctx context.Context, result domain.Result) error
-	GetResults(ctx context.Context) ([]*domain.Result, error)
+	DeleteUserResult(ctx context.Context, resultID string) error
+	GetResults(ctx context.Context, query domain.ResultsQuery) ([]*domain.Result, int64, error)
+	GetResultByID(ctx context.Context, resultID string) (*domain.Result, error)
+	GetUserResults(ctx context.Context, testID string, userID int) ([]*domain.Result, error)
+	CreateAttempt(ctx context.Context, attempt domain.Attempt) error
+	GetAttemptByID(ctx context.Context, attemptID string) (*domain.Attempt, error)
+	FinishAttempt(ctx context.Context, attemptID string, submittedAt time.Time, resultID string) error
+	UpdateAttemptAnswers(ctx context.Context, attemptID string, answers []domain.UserAnswerModel) error
+	CreateQuestionBank(ctx context.Context, bank domain.QuestionBank) error
+	GetQuestionBanks(ctx context.Context, query domain.BanksQuery) ([]*domain.QuestionBank, int64, error)
+	GetQuestionBankByID(ctx context.Context, bankID string, includeAnswers bool) (*domain.QuestionBank, error)
+	GetQuestionBanksByIDs(ctx context.Context, bankIDs []string, includeAnswers bool) ([]*domain.QuestionBank, error)
+	UpdateQuestionBank(ctx context.Context, bankID string, bank domain.QuestionBank) error
+	DeleteQuestionBank(ctx context.Context, bankID string) error
+	CountTestsUsingBank(ctx context.Context, bankID string) (int64, error)
+	GetTestsUsingBank(ctx context.Context, bankID string) ([]*domain.Test, error)
+	UpdateResultScore(ctx context.Context, result domain.Result) error
+	UpdateTestStatus(ctx context.Context, testID string, status string, revision int) error
+	GetRawTestByID(ctx context.Context, testID string) (*domain.Test, error)
+	ReplaceTest(ctx context.Context, test domain.Test, revision int) error
+	CreateTestVersion(ctx context.Context, version domain.TestVersion) error
+	DeleteTestVersion(ctx context.Context, testID string, version int) error
+	GetTestVersions(ctx context.Context, testID string) ([]*domain.TestVersion, error)
+	GetTestVersion(ctx context.Context, testID string, version int) (*domain.TestVersion, error)
 }
 
 //go:generate mockery --name Validator
 type Validator interface {
 	ValidateTest(test domain.Test) error
 	ValidateUserAnswers(q domain.Question, a domain.UserAnswerModel) error
+	ValidateQuestionBank(bank domain.QuestionBank) error
 }
 
+// maxAttemptNumberRetries limits rereads of results when attempt number is taken by concurrent submissions
+const maxAttemptNumberRetries = 3
+
 var (
 	ErrNoRights             = errors.New("no rights to perform")
 	ErrInvalidTestType      = errors.New("invalid test type")
 	ErrFailedTestValidation = errors.New("failed test validation")
 	ErrNotFound             = errors.New("not found")
 	ErrNoUserAnswer         = errors.New("no user answer")
+	ErrAttemptRequired      = errors.New("attempt required")
+	ErrAttemptFinished      = errors.New("attempt already finished")
+	ErrTimeLimitExceeded    = errors.New("time limit exceeded")
+	ErrAttemptsLimit        = errors.New("attempts limit reached")
+	ErrRetakeCooldown       = errors.New("retake cooldown is not over")
+	ErrBankInUse            = errors.New("question bank is used by tests")
+	ErrBankBreaksTests      = errors.New("question bank update breaks tests using it")
+	ErrResultNotPending     = errors.New("result is not pending review")
+	ErrNotGraded            = errors.New("not all answers are graded")
+	ErrTestNotPublished     = errors.New("test is not published")
+	ErrInvalidTransition    = errors.New("invalid test status transition")
+	ErrEmptySearchText      = errors.New("empty search text")
+	ErrConflict             = errors.New("test was changed concurrently")
 )
 
 type Service struct {
@@ -55,20 +101,322 @@ func New(log *zap.Logger, cfg *config.Config, storage Storage, validator Validat
 	}
 }
 
-func (s *Service) GetResults(ctx context.Context) ([]*domain.Result, error) {
+// GetResults returns results of all users for all tests, allowed only for admins
+func (s *Service) GetResults(ctx context.Context, query domain.ResultsQuery) ([]*domain.Result, int64, error) {
 	const op = "service.testsservice.GetResults"
 	log := s.log.With(zap.String("op", op))
 	log.Info("getting results")
 
-	results, err := s.storage.GetResults(ctx)
+	authUser, ok := user.AuthUserFromContext(ctx)
+	if !ok || !authUser.HasAny(user.Admin) {
+		log.Error("forbidden action")
+		return nil, 0, fmt.Errorf("%s: %w", op, ErrNoRights)
+	}
+
+	results, total, err := s.storage.GetResults(ctx, query)
+	if err != nil {
+		log.Error("failed to get results", zap.Error(err))
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("results were gotten successfully")
+	return results, total, nil
+}
+
+// GetUserResults returns results of the authorized user
+func (s *Service) GetUserResults(ctx context.Context, query domain.ResultsQuery) ([]*domain.Result, int64, error) {
+	const op = "service.testsservice.GetUserResults"
+	log := s.log.With(zap.String("op", op))
+	log.Info("getting user results")
+
+	authUser, ok := user.AuthUserFromContext(ctx)
+	if !ok {
+		log.Error("forbidden action")
+		return nil, 0, fmt.Errorf("%s: %w", op, ErrNoRights)
+	}
+	query.UserID = &authUser.ID
+
+	results, total, err := s.storage.GetResults(ctx, query)
 	if err != nil {
 		log.Error("failed to get results", zap.Error(err))
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("user results were gotten successfully")
+	return results, total, nil
+}
+
+// GetTestResults returns results of all users for the test, allowed for test creator and moderators
+func (s *Service) GetTestResults(ctx context.Context, testID string, query domain.ResultsQuery) ([]*domain.Result, int64, error) {
+	const op = "service.testsservice.GetTestResults"
+	log := s.log.With(zap.String("op", op))
+	log.Info("getting test results")
+
+	test, err := s.storage.GetTestByID(ctx, testID, false)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("test not found")
+			return nil, 0, fmt.Errorf("%s: %w", op, ErrNotFound)
+		}
+		log.Error("failed to get test", zap.Error(err))
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	authUser, ok := user.AuthUserFromContext(ctx)
+	if !ok || (authUser.ID != *test.UserID && !authUser.HasAny(user.Moderator, user.Admin)) {
+		log.Error("forbidden action")
+		return nil, 0, fmt.Errorf("%s: %w", op, ErrNoRights)
+	}
+	query.TestID = &testID
+
+	results, total, err := s.storage.GetResults(ctx, query)
+	if err != nil {
+		log.Error("failed to get results", zap.Error(err))
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("test results were gotten successfully")
+	return results, total, nil
+}
+
+// checkRetakePolicy checks if user is allowed to make one more attempt of the test
+// Returns previous results of the user ordered from the first one
+// Check isn't atomic, attempts limit of concurrent submissions is guarded by unique attempt numbers in storage
+func (s *Service) checkRetakePolicy(ctx context.Context, test *domain.Test, userID int, now time.Time) ([]*domain.Result, error) {
+	previous, err := s.storage.GetUserResults(ctx, *test.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if test.MaxAttempts != nil && len(previous) >= *test.MaxAttempts {
+		return nil, ErrAttemptsLimit
+	}
+
+	if test.RetakeCooldown != nil && len(previous) > 0 {
+		last := previous[len(previous)-1]
+		if now.Before(last.CreatedAt.Add(time.Duration(*test.RetakeCooldown) * time.Second)) {
+			return nil, ErrRetakeCooldown
+		}
+	}
+
+	return previous, nil
+}
+
+// GetResultByID returns result, allowed for the user who got it, test creator and moderators
+func (s *Service) GetResultByID(ctx context.Context, resultID string) (*domain.Result, error) {
+	const op = "service.testsservice.GetResultByID"
+	log := s.log.With(zap.String("op", op))
+	log.Info("getting result")
+
+	result, err := s.storage.GetResultByID(ctx, resultID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("result not found")
+			return nil, fmt.Errorf("%s: %w", op, ErrNotFound)
+		}
+		log.Error("failed to get result", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	authUser, ok := user.AuthUserFromContext(ctx)
+	if !ok {
+		log.Error("forbidden action")
+		return nil, fmt.Errorf("%s: %w", op, ErrNoRights)
+	}
+	if authUser.ID == result.UserID || authUser.HasAny(user.Moderator, user.Admin) {
+		log.Info("result was gotten successfully")
+		return result, nil
+	}
+
+	test, err := s.storage.GetTestByID(ctx, result.TestID, false)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Error("failed to get test", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if test == nil || authUser.ID != *test.UserID {
+		log.Error("forbidden action")
+		return nil, fmt.Errorf("%s: %w", op, ErrNoRights)
+	}
+
+	log.Info("result was gotten successfully")
+	return result, nil
+}
+
+// StartAttempt starts new attempt of the authorized user for the test
+// Deadline is set if test has time limit
+func (s *Service) StartAttempt(ctx context.Context, testID string) (*domain.Attempt, error) {
+	const op = "service.testsservice.StartAttempt"
+	log := s.log.With(zap.String("op", op))
+	log.Info("starting attempt")
+
+	authUser, ok := user.AuthUserFromContext(ctx)
+	if !ok {
+		log.Error("forbidden action")
+		return nil, fmt.Errorf("%s: %w", op, ErrNoRights)
+	}
+
+	test, err := s.storage.GetTestByID(ctx, testID, false)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("test not found")
+			return nil, fmt.Errorf("%s: %w", op, ErrNotFound)
+		}
+		log.Error("failed to get test", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if test.TestStatus() != domain.TestStatusPublished {
+		log.Warn("test is not published")
+		return nil, fmt.Errorf("%s: %w", op, ErrTestNotPublished)
+	}
+
+	now := time.Now().UTC()
+	if _, err := s.checkRetakePolicy(ctx, test, authUser.ID, now); err != nil {
+		if errors.Is(err, ErrAttemptsLimit) || errors.Is(err, ErrRetakeCooldown) {
+			log.Warn("retake is not allowed", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		log.Error("failed to check retake policy", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	attempt := domain.Attempt{
+		ID:        uuid.New().String(),
+		TestID:    testID,
+		UserID:    authUser.ID,
+		StartedAt: now,
+	}
+	if test.TimeLimit != nil {
+		deadline := attempt.StartedAt.Add(time.Duration(*test.TimeLimit) * time.Second)
+		attempt.Deadline = &deadline
+	}
+	// Layout is fixed when attempt starts, so the user sees the same questions on reload or other device
+	attempt.Layout = test.NewLayout(rand.Shuffle)
+
+	if err := s.storage.CreateAttempt(ctx, attempt); err != nil {
+		log.Error("failed to create attempt", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("attempt was started successfully")
+	return &attempt, nil
+}
+
+// GetAttempt returns attempt of the authorized user with all answers saved in it
+func (s *Service) GetAttempt(ctx context.Context, attemptID string) (*domain.Attempt, error) {
+	const op = "service.testsservice.GetAttempt"
+	log := s.log.With(zap.String("op", op))
+	log.Info("getting attempt")
+
+	attempt, err := s.storage.GetAttemptByID(ctx, attemptID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("attempt not found")
+			return nil, fmt.Errorf("%s: %w", op, ErrNotFound)
+		}
+		log.Error("failed to get attempt", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	authUser, ok := user.AuthUserFromContext(ctx)
+	if !ok || authUser.ID != attempt.UserID {
+		log.Error("forbidden action")
+		return nil, fmt.Errorf("%s: %w", op, ErrNoRights)
+	}
+
+	log.Info("attempt was gotten successfully")
+	return attempt, nil
+}
+
+// SaveAttemptAnswers validates given answers and saves them in the attempt of the authorized user
+// Answers on the same questions are replaced, other saved answers are kept
+func (s *Service) SaveAttemptAnswers(ctx context.Context, attemptID string, answers map[int]domain.UserAnswerModel) (*domain.Attempt, error) {
+	const op = "service.testsservice.SaveAttemptAnswers"
+	log := s.log.With(zap.String("op", op))
+	log.Info("saving attempt answers")
+
+	attempt, err := s.storage.GetAttemptByID(ctx, attemptID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("attempt not found")
+			return nil, fmt.Errorf("%s: %w", op, ErrNotFound)
+		}
+		log.Error("failed to get attempt", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	authUser, ok := user.AuthUserFromContext(ctx)
+	if !ok || authUser.ID != attempt.UserID {
+		log.Error("forbidden action")
+		return nil, fmt.Errorf("%s: %w", op, ErrNoRights)
+	}
+	if attempt.SubmittedAt != nil {
+		log.Warn("attempt was already submitted")
+		return nil, fmt.Errorf("%s: %w", op, ErrAttemptFinished)
+	}
+
+	test, err := s.storage.GetTestByID(ctx, attempt.TestID, false)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("test not found")
+			return nil, fmt.Errorf("%s: %w", op, ErrNotFound)
+		}
+		log.Error("failed to get test", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if test.TestStatus() != domain.TestStatusPublished {
+		log.Warn("test is not published")
+		return nil, fmt.Errorf("%s: %w", op, ErrTestNotPublished)
+	}
+
+	if attempt.IsLate(time.Now().UTC()) && test.LatePenalty == nil {
+		log.Warn("time limit exceeded")
+		return nil, fmt.Errorf("%s: %w", op, ErrTimeLimitExceeded)
+	}
+
+	test.ApplyLayout(attempt.Layout)
+	questions := make(map[int]*domain.Question, len(*test.Questions))
+	for _, q := range *test.Questions {
+		questions[q.ID] = q
+	}
+	for id, a := range answers {
+		q, has := questions[id]
+		if !has {
+			log.Error("no question for user answer", zap.Int("question_id", id))
+			return nil, fmt.Errorf("%s: %w", op, ErrFailedTestValidation)
+		}
+		if err := s.validation.ValidateUserAnswers(*q, a); err != nil {
+			log.Error("failed to validate user answers", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, ErrFailedTestValidation)
+		}
+	}
+
+	// Only given answers are written, so answers saved concurrently from other device are kept
+	saved := make([]domain.UserAnswerModel, 0, len(answers))
+	for _, a := range answers {
+		saved = append(saved, a)
+	}
+	sort.Slice(saved, func(i, j int) bool {
+		return saved[i].QuestionID < saved[j].QuestionID
+	})
+	if err := s.storage.UpdateAttemptAnswers(ctx, attemptID, saved); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("attempt was already submitted")
+			return nil, fmt.Errorf("%s: %w", op, ErrAttemptFinished)
+		}
+		log.Error("failed to save attempt answers", zap.Error(err))
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	return results, nil
+
+	attempt.MergeAnswers(answers)
+	log.Info("attempt answers were saved successfully")
+	return attempt, nil
 }
 
-func (s *Service) ApplyTest(ctx context.Context, testID string, UserID int, answers map[int]domain.UserAnswerModel) error {
+// ApplyTest checks user answers, calculates results depending on the test type and saves them
+// If attemptID is not empty, answers are submitted within this attempt and attempt gets finished
+// Timed tests can be applied only within an attempt
+// Returns saved result
+func (s *Service) ApplyTest(ctx context.Context, testID string, UserID int, attemptID string, answers map[int]domain.UserAnswerModel) (*domain.Result, error) {
 	const op = "service.testsservice.ApplyTest"
 	log := s.log.With(zap.String("op", op))
 	log.Info("applying test")
@@ -77,10 +425,65 @@ func (s *Service) ApplyTest(ctx context.Context, testID string, UserID int, answ
 	if err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
 			log.Warn("test not found")
-			return fmt.Errorf("%s: %w", op, ErrNotFound)
+			return nil, fmt.Errorf("%s: %w", op, ErrNotFound)
 		}
 		log.Error("failed to get test", zap.Error(err))
-		return fmt.Errorf("%s: %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if test.TestStatus() != domain.TestStatusPublished {
+		log.Warn("test is not published")
+		return nil, fmt.Errorf("%s: %w", op, ErrTestNotPublished)
+	}
+
+	now := time.Now().UTC()
+	var attempt *domain.Attempt
+	if attemptID != "" {
+		attempt, err = s.storage.GetAttemptByID(ctx, attemptID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				log.Warn("attempt not found")
+				return nil, fmt.Errorf("%s: %w", op, ErrNotFound)
+			}
+			log.Error("failed to get attempt", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if attempt.TestID != testID || attempt.UserID != UserID {
+			log.Error("forbidden action")
+			return nil, fmt.Errorf("%s: %w", op, ErrNoRights)
+		}
+		if attempt.SubmittedAt != nil {
+			log.Warn("attempt was already submitted")
+			return nil, fmt.Errorf("%s: %w", op, ErrAttemptFinished)
+		}
+		test.ApplyLayout(attempt.Layout)
+	} else if test.TimeLimit != nil || test.IsRandomized() {
+		log.Warn("no attempt for timed or randomized test")
+		return nil, fmt.Errorf("%s: %w", op, ErrAttemptRequired)
+	}
+
+	// Answers saved within the attempt are submitted too, unless they were answered again
+	if attempt != nil && len(attempt.Answers) > 0 {
+		attempt.MergeAnswers(answers)
+		answers = make(map[int]domain.UserAnswerModel, len(attempt.Answers))
+		for _, a := range attempt.Answers {
+			answers[a.QuestionID] = a
+		}
+	}
+
+	late := attempt != nil && attempt.IsLate(now)
+	if late && test.LatePenalty == nil {
+		log.Warn("time limit exceeded")
+		return nil, fmt.Errorf("%s: %w", op, ErrTimeLimitExceeded)
+	}
+
+	previous, err := s.checkRetakePolicy(ctx, test, UserID, now)
+	if err != nil {
+		if errors.Is(err, ErrAttemptsLimit) || errors.Is(err, ErrRetakeCooldown) {
+			log.Warn("retake is not allowed", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		log.Error("failed to check retake policy", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
 	handleQuestions := func(handler func(domain.Question, domain.UserAnswerModel)) ([]domain.UserAnswerModel, error) {
@@ -106,94 +509,225 @@ func (s *Service) ApplyTest(ctx context.Context, testID string, UserID int, answ
 		return ua, nil
 	}
 
-	saveResults := func(r domain.Result) error {
-		err := s.storage.SaveUserResult(ctx, r)
-		if err != nil {
-			log.Error("failed to save user test result", zap.Error(err))
-			return fmt.Errorf("%s: %w", op, err)
+	saveResults := func(r domain.Result) (*domain.Result, error) {
+		r.ID = uuid.New().String()
+		r.CreatedAt = now
+		r.TestVersion = test.Version
+		r.BankQuestions = bankQuestionsSnapshot(test)
+		r.BankRevisions = test.BankRevisions
+		r.Late = late
+		if attempt != nil {
+			r.AttemptID = &attempt.ID
+			r.Layout = attempt.Layout
+			r.StartedAt = &attempt.StartedAt
+			r.Duration = p.Int(int(now.Sub(attempt.StartedAt).Seconds()))
 		}
-		return nil
+		// Attempt number is unique, so the number taken by concurrent submission is rejected by storage
+		// Then previous results are read again and the next number is tried, until the attempts limit is reached
+		for retry := 0; ; retry++ {
+			r.Attempt = nextAttempt(previous)
+			if test.MaxAttempts != nil && r.Attempt > *test.MaxAttempts {
+				log.Warn("attempts limit was reached by concurrent submission")
+				return nil, fmt.Errorf("%s: %w", op, ErrAttemptsLimit)
+			}
+			if r.Percentage != nil {
+				r.FinalPercentage = p.Int(finalPercentage(test, previous, *r.Percentage))
+			}
+
+			err := s.storage.SaveUserResult(ctx, r)
+			if err == nil {
+				break
+			}
+			if !errors.Is(err, storage.ErrExists) || retry == maxAttemptNumberRetries {
+				log.Error("failed to save user test result", zap.Error(err))
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+
+			log.Warn("attempt number is already taken", zap.Int("attempt", r.Attempt))
+			if previous, err = s.storage.GetUserResults(ctx, testID, UserID); err != nil {
+				log.Error("failed to get user results", zap.Error(err))
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+		}
+		if attempt != nil {
+			// Attempt is finished only after the result is saved, so failed save can be retried
+			// Finishing is conditional, so result of the attempt that was submitted twice is removed
+			if err := s.storage.FinishAttempt(ctx, attempt.ID, now, r.ID); err != nil {
+				if delErr := s.storage.DeleteUserResult(ctx, r.ID); delErr != nil {
+					log.Error("failed to delete result of not finished attempt", zap.Error(delErr))
+				}
+				if errors.Is(err, storage.ErrNotFound) {
+					log.Warn("attempt was already submitted")
+					return nil, fmt.Errorf("%s: %w", op, ErrAttemptFinished)
+				}
+				log.Error("failed to finish attempt", zap.Error(err))
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+		}
+		return &r, nil
 	}
 
+	var result *domain.Result
 	switch *test.Type {
 	case domain.TestTypeForm:
 		ua, err := handleQuestions(func(q domain.Question, a domain.UserAnswerModel) {})
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		err = saveResults(domain.Result{
+		result, err = saveResults(domain.Result{
 			TestID:      testID,
 			UserID:      UserID,
 			UserAnswers: ua,
 		})
 		if err != nil {
-			return err
+			return nil, err
 		}
 	case domain.TestTypeQuiz:
 		ua, err := handleQuestions(func(q domain.Question, a domain.UserAnswerModel) {})
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		err = saveResults(domain.Result{
+		result, err = saveResults(domain.Result{
 			TestID:      testID,
 			UserID:      UserID,
 			UserAnswers: ua,
 		})
 		if err != nil {
-			return err
+			return nil, err
 		}
 	case domain.TestTypeStrictTest:
-		maxPoints := 0
-		points := 0
+		pending := false
+		breakdown := make([]*domain.QuestionResult, 0, len(*test.Questions))
+		ua, err := handleQuestions(func(q domain.Question, a domain.UserAnswerModel) {
+			// Question without points is worth nothing, e.g. if it lost points in the question bank
+			points := 0
+			if q.Points != nil {
+				points = *q.Points
+			}
+			qr := &domain.QuestionResult{
+				QuestionID: q.ID,
+				MaxPoints:  points,
+			}
+			if test.RevealAnswers {
+				qr.CorrectAnswers = q.Answers
+			}
+
+			// Answer is left for reviewer, until then it gives no points
+			if q.NeedsReview && a.QuestionID != 0 {
+				qr.Status = domain.QuestionResultPending
+				qr.Pending = true
+				pending = true
+				breakdown = append(breakdown, qr)
+				return
+			}
+
+			got := 0
+			if a.QuestionID != 0 {
+				got = q.ComparePreciseResults(a)
+				qr.Points = int((float64(got) / 100.0) * float64(points))
+			}
+			qr.Status = domain.QuestionResultStatus(got)
+			breakdown = append(breakdown, qr)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		r := domain.Result{
+			TestID:      testID,
+			UserID:      UserID,
+			UserAnswers: ua,
+			Questions:   &breakdown,
+			Pending:     pending,
+		}
+		r.LatePenalty = latePenalty(test, late)
+		r.Score()
+
+		result, err = saveResults(r)
+		if err != nil {
+			return nil, err
+		}
+	case domain.TestTypeTest:
+		params := make(map[string]int)
+		if test.Params != nil {
+			for _, name := range *test.Params {
+				params[name] = 0
+			}
+		}
 		ua, err := handleQuestions(func(q domain.Question, a domain.UserAnswerModel) {
 			if a.QuestionID == 0 {
-				maxPoints += *q.Points
 				return
 			}
-			maxPoints += *q.Points
-			got := q.ComparePreciseResults(a)
-			points += int((float64(got) / 100.0) * float64(*q.Points))
+			q.CollectFlexParams(a, params)
 		})
 		if err != nil {
-			return err
+			return nil, err
+		}
+
+		var resultID *int
+		if outcome := test.ChooseOutcome(params); outcome != nil {
+			resultID = &outcome.ID
+		} else {
+			log.Warn("no outcome matched calculated params", zap.Any("params", params))
 		}
 
-		err = saveResults(domain.Result{
+		result, err = saveResults(domain.Result{
 			TestID:      testID,
 			UserID:      UserID,
 			UserAnswers: ua,
-			Percentage:  p.Int(int(float64(points) / float64(maxPoints) * 100.0)),
+			ResultID:    resultID,
+			Params:      &params,
 		})
 		if err != nil {
-			return err
+			return nil, err
 		}
-	case domain.TestTypeTest:
-		log.Error("NOT IMPLEMENTED")
-		return fmt.Errorf("%s: %w", op, errors.New("SAVING RESULTS FOR TEST OF TYPE TEST NOT IMPLEMENTED"))
 	default:
 		log.Error("invalid test type", zap.String("type", *test.Type))
-		return fmt.Errorf("%s: %w", op, ErrInvalidTestType)
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidTestType)
 	}
 
 	log.Info("test was applied successfully")
-	return nil
+	return result, nil
 }
 
-func (s *Service) GetTests(ctx context.Context) ([]*domain.Test, error) {
+func (s *Service) GetTests(ctx context.Context, query domain.TestsQuery) ([]*domain.Test, int64, error) {
 	const op = "service.testsservice.GetTests"
 	log := s.log.With(zap.String("op", op))
 	log.Info("getting tests")
 
-	tests, err := s.storage.GetTests(ctx)
+	hideDrafts(ctx, &query)
+	tests, total, err := s.storage.GetTests(ctx, query)
 	if err != nil {
 		log.Error("failed to get tests", zap.Error(err))
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
 	}
 
 	log.Info("tests were gotten successfully")
-	return tests, nil
+	return tests, total, nil
+}
+
+func (s *Service) SearchTests(ctx context.Context, text string, query domain.TestsQuery) ([]*domain.Test, int64, error) {
+	const op = "service.testsservice.SearchTests"
+	log := s.log.With(zap.String("op", op))
+	log.Info("searching tests")
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		log.Warn("empty search text")
+		return nil, 0, fmt.Errorf("%s: %w", op, ErrEmptySearchText)
+	}
+
+	hideDrafts(ctx, &query)
+	tests, total, err := s.storage.SearchTests(ctx, text, query)
+	if err != nil {
+		log.Error("failed to search tests", zap.Error(err))
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("tests were found successfully")
+	return tests, total, nil
 }
 
 func (s *Service) GetTestByID(ctx context.Context, testID string, provideAnswers bool) (*domain.Test, error) {
@@ -211,9 +745,15 @@ func (s *Service) GetTestByID(ctx context.Context, testID string, provideAnswers
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
+	// Drafts are hidden as if they don't exist
+	if !canSeeTest(ctx, test) {
+		log.Warn("test is a draft of other user")
+		return nil, fmt.Errorf("%s: %w", op, ErrNotFound)
+	}
+
 	if provideAnswers {
 		authUser, ok := user.AuthUserFromContext(ctx)
-		if !ok || (authUser.ID != *test.UserID && slice.MaxInt(authUser.Permissions) < user.Admin) {
+		if !ok || (authUser.ID != *test.UserID && !authUser.HasAny(user.Admin)) {
 			log.Error("forbidden action")
 			return nil, fmt.Errorf("%s: %w", op, ErrNoRights)
 		}
@@ -239,7 +779,7 @@ func (s *Service) DeleteTest(ctx context.Context, testID string) error {
 	}
 
 	authUser, ok := user.AuthUserFromContext(ctx)
-	if !ok || (authUser.ID != *test.UserID && slice.MaxInt(authUser.Permissions) < user.Moderator) {
+	if !ok || (authUser.ID != *test.UserID && !authUser.HasAny(user.Moderator, user.Admin)) {
 		log.Error("forbidden action")
 		return fmt.Errorf("%s: %w", op, ErrNoRights)
 	}
@@ -264,14 +804,36 @@ func (s *Service) CreateTest(ctx context.Context, test domain.Test) (string, err
 
 	id := uuid.New().String()
 	test.ID = &id
+	test.Status = p.String(domain.TestStatusDraft)
+	test.Version = 1
+	test.Revision = 1
+
+	// Test is validated with bank questions expanded, but stored with references only
+	expanded := test
+	if err := s.expandBankQuestions(ctx, &expanded); err != nil {
+		if errors.Is(err, ErrNoRights) {
+			log.Error("no rights to use question bank")
+			return "", fmt.Errorf("%s: %w", op, ErrNoRights)
+		}
+		if errors.Is(err, ErrFailedTestValidation) {
+			log.Error("test references missing bank questions")
+			return "", fmt.Errorf("%s: %w", op, ErrFailedTestValidation)
+		}
+		log.Error("failed to expand bank questions", zap.Error(err))
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
 
-	if err := s.validation.ValidateTest(test); err != nil {
+	if err := s.validation.ValidateTest(expanded); err != nil {
 		log.Error("failed to validate test", zap.Error(err))
 		return "", fmt.Errorf("%s: %w", op, ErrFailedTestValidation)
 	}
 
-	if err := s.storage.CreateTest(ctx, test); err != nil {
-		s.log.Error("failed to create test", zap.Error(err))
+	authUser, _ := user.AuthUserFromContext(ctx)
+	err := s.saveVersion(ctx, &test, authUser.ID, func() error {
+		return s.storage.CreateTest(ctx, test)
+	})
+	if err != nil {
+		log.Error("failed to create test", zap.Error(err))
 		return "", fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -279,12 +841,15 @@ func (s *Service) CreateTest(ctx context.Context, test domain.Test) (string, err
 	return id, nil
 }
 
-func (s *Service) UpdateTest(ctx context.Context, testID string, update domain.Test) error {
+// UpdateTest applies merge patch to preview fields of the test
+// Null deletes optional field, nested objects are merged into stored ones
+// If revision is not nil, test is updated only if it wasn't changed since this revision
+func (s *Service) UpdateTest(ctx context.Context, testID string, patch domain.MergePatch, revision *int) error {
 	const op = "service.testsservice.UpdateTest"
 	log := s.log.With(zap.String("op", op))
 	log.Info("updating test")
 
-	test, err := s.storage.GetTestByID(ctx, testID, true)
+	test, err := s.storage.GetRawTestByID(ctx, testID)
 	if err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
 			log.Warn("test not found")
@@ -295,16 +860,47 @@ func (s *Service) UpdateTest(ctx context.Context, testID string, update domain.T
 	}
 
 	authUser, ok := user.AuthUserFromContext(ctx)
-	if !ok || (authUser.ID != *test.UserID && slice.MaxInt(authUser.Permissions) < user.Admin) {
+	if !ok || (authUser.ID != *test.UserID && !authUser.HasAny(user.Admin)) {
 		log.Error("forbidden action")
 		return fmt.Errorf("%s: %w", op, ErrNoRights)
 	}
 
-	if err := s.storage.UpdateTest(ctx, testID, update); err != nil {
+	if err := checkRevision(test, revision); err != nil {
+		log.Warn("test was changed since expected revision")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := checkPreviewPatch(patch); err != nil {
+		log.Warn("invalid preview patch", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Snapshot of the new version is built by applying the same patch to the stored test
+	updated := *test
+	if err := patch.Apply(&updated); err != nil {
+		log.Warn("failed to apply preview patch", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, ErrFailedTestValidation)
+	}
+	updated.Version = test.Version + 1
+	updated.Revision = test.Revision + 1
+
+	update := make(domain.MergePatch, len(patch)+1)
+	for k, v := range patch {
+		update[k] = v
+	}
+	update["version"] = json.RawMessage(strconv.Itoa(updated.Version))
+	err = s.saveVersion(ctx, &updated, authUser.ID, func() error {
+		return s.storage.UpdateTest(ctx, testID, update, test.Revision)
+	})
+	if err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
 			s.log.Error("test not found", zap.Error(err))
 			return fmt.Errorf("%s: %w", op, ErrNotFound)
 		}
+		if errors.Is(err, storage.ErrConflict) || errors.Is(err, ErrConflict) {
+			log.Warn("test was changed concurrently")
+			return fmt.Errorf("%s: %w", op, ErrConflict)
+		}
 		log.Error("failed to update test", zap.Error(err))
 		return fmt.Errorf("%s: %w", op, err)
 	}
@@ -312,3 +908,81 @@ func (s *Service) UpdateTest(ctx context.Context, testID string, update domain.T
 	log.Info("test was updated successfully")
 	return nil
 }
+
+// previewFields are fields that can be changed with UpdateTest, value tells if field can be deleted
+var previewFields = map[string]bool{
+	"title":      false,
+	"short_text": false,
+	"long_text":  false,
+	"main_image": true,
+	"tags":       true,
+}
+
+func checkPreviewPatch(patch domain.MergePatch) error {
+	for k := range patch {
+		deletable, ok := previewFields[k]
+		if !ok {
+			return ErrFailedTestValidation
+		}
+		if !deletable && patch.Deletes(k) {
+			return ErrFailedTestValidation
+		}
+	}
+
+	return nil
+}
+
+// bankQuestionsSnapshot copies bank questions of the test, so later edits of the bank don't change the result
+// Correct answers are kept only if test reveals them
+func bankQuestionsSnapshot(test *domain.Test) []*domain.Question {
+	questions := test.BankQuestions()
+	if len(questions) == 0 {
+		return nil
+	}
+
+	snapshot := make([]*domain.Question, 0, len(questions))
+	for _, q := range questions {
+		cp := *q
+		if !test.RevealAnswers {
+			cp.Answers = nil
+		}
+		snapshot = append(snapshot, &cp)
+	}
+
+	return snapshot
+}
+
+// latePenalty returns percents of points taken from the result of the test
+func latePenalty(test *domain.Test, late bool) int {
+	if !late || test.LatePenalty == nil {
+		return 0
+	}
+	return *test.LatePenalty
+}
+
+// nextAttempt returns number of the attempt following previous ones
+func nextAttempt(previous []*domain.Result) int {
+	n := len(previous)
+	for _, r := range previous {
+		n = max(n, r.Attempt)
+	}
+	return n + 1
+}
+
+// finalPercentage returns percentage that counts among previous attempts and the new one by score mode of the test
+func finalPercentage(test *domain.Test, previous []*domain.Result, percentage int) int {
+	percentages := make([]int, 0, len(previous)+1)
+	for _, r := range previous {
+		if r.Percentage != nil {
+			percentages = append(percentages, *r.Percentage)
+		}
+	}
+	percentages = append(percentages, percentage)
+
+	scoreMode := domain.ScoreModeLast
+	if test.ScoreMode != nil {
+		scoreMode = *test.ScoreMode
+	}
+
+	return domain.FinalPercentage(scoreMode, percentages)
+}
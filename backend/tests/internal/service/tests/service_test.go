@@ -2,6 +2,7 @@ package testsservice
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"github.com/coddmeistr/quizzify/backend/tests/internal/config"
 	"github.com/coddmeistr/quizzify/backend/tests/internal/domain"
@@ -14,6 +15,7 @@ import (
 	"go.uber.org/zap"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestService_CreateTest(t *testing.T) {
@@ -39,6 +41,9 @@ func TestService_CreateTest(t *testing.T) {
 			mockF: func(st *mocks.Storage, val *mocks.Validator) {
 				val.On("ValidateTest", mock.AnythingOfType(testType)).Return(nil).Once()
 				st.On("CreateTest", context.Background(), mock.AnythingOfType(testType)).Return(nil).Once()
+				st.On("CreateTestVersion", context.Background(), mock.MatchedBy(func(v domain.TestVersion) bool {
+					return v.Version == 1 && v.Test != nil
+				})).Return(nil).Once()
 			},
 		},
 		{
@@ -61,7 +66,9 @@ func TestService_CreateTest(t *testing.T) {
 			err:       errors.New("some error occured"),
 			mockF: func(st *mocks.Storage, val *mocks.Validator) {
 				val.On("ValidateTest", mock.AnythingOfType(testType)).Return(nil).Once()
+				st.On("CreateTestVersion", context.Background(), mock.AnythingOfType("domain.TestVersion")).Return(nil).Once()
 				st.On("CreateTest", context.Background(), mock.AnythingOfType(testType)).Return(errors.New("some error occured")).Once()
+				st.On("DeleteTestVersion", context.Background(), mock.AnythingOfType("string"), 1).Return(nil).Once()
 			},
 		},
 	}
@@ -250,3 +257,2348 @@ func TestService_DeleteTest(t *testing.T) {
 	}
 
 }
+
+func TestService_ApplyTest_TestType(t *testing.T) {
+	validTestId := "623452gsgsgf"
+	resultType := reflect.TypeOf(domain.Result{}).String()
+
+	newTest := func() *domain.Test {
+		return &domain.Test{
+			ID:     &validTestId,
+			UserID: p.Int(1),
+			Type:   p.String(domain.TestTypeTest),
+			Params: &[]string{"energy"},
+			Questions: &[]*domain.Question{
+				{
+					ID:       1,
+					Type:     p.String(domain.QuestionTypeSingleChoice),
+					Required: true,
+					Answers: &domain.AnswerModel{
+						FlexParams: &[]*domain.FlexParamsModel{
+							{FieldID: 1, Params: &[]*domain.FlexParam{{ParamName: p.String("energy"), Effect: p.Int(2)}}},
+							{FieldID: 2, Params: &[]*domain.FlexParam{{ParamName: p.String("energy"), Effect: p.Int(3), IsNegative: true}}},
+						},
+					},
+				},
+				{
+					ID:   2,
+					Type: p.String(domain.QuestionTypeMultipleChoice),
+					Answers: &domain.AnswerModel{
+						FlexParams: &[]*domain.FlexParamsModel{
+							{FieldID: 1, Params: &[]*domain.FlexParam{{ParamName: p.String("energy"), Effect: p.Int(1)}}},
+							{FieldID: 2, Params: &[]*domain.FlexParam{{ParamName: p.String("energy"), Effect: p.Int(1), IsNegative: true}}},
+						},
+					},
+				},
+			},
+			Outcomes: &[]*domain.Outcome{
+				{
+					ID:    1,
+					Title: p.String("Introvert"),
+					Conditions: &[]*domain.OutcomeCondition{
+						{ParamName: p.String("energy"), Operator: p.String(domain.OutcomeOperatorLess), Value: p.Int(0)},
+					},
+				},
+				{
+					ID:    2,
+					Title: p.String("Extrovert"),
+				},
+			},
+		}
+	}
+
+	type args struct {
+		answers map[int]domain.UserAnswerModel
+	}
+	tc := []struct {
+		name         string
+		args         args
+		wantError    bool
+		err          error
+		wantResultID *int
+		wantParams   map[string]int
+	}{
+		{
+			name: "ok, first outcome matched",
+			args: args{
+				answers: map[int]domain.UserAnswerModel{
+					1: {QuestionID: 1, ChosenID: p.Int(2)},
+					2: {QuestionID: 2, ChosenIDs: &[]int{1, 2}},
+				},
+			},
+			wantResultID: p.Int(1),
+			wantParams:   map[string]int{"energy": -3},
+		},
+		{
+			name: "ok, fallback outcome",
+			args: args{
+				answers: map[int]domain.UserAnswerModel{
+					1: {QuestionID: 1, ChosenID: p.Int(1)},
+				},
+			},
+			wantResultID: p.Int(2),
+			wantParams:   map[string]int{"energy": 2},
+		},
+		{
+			name: "no answer on required question",
+			args: args{
+				answers: map[int]domain.UserAnswerModel{
+					2: {QuestionID: 2, ChosenIDs: &[]int{1}},
+				},
+			},
+			wantError: true,
+			err:       errors.New("no user answer"),
+		},
+	}
+
+	for _, tt := range tc {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockVal := mocks.NewValidator(t)
+			mockSt := mocks.NewStorage(t)
+			mockSt.On("GetTestByID", mock.Anything, validTestId, true).Return(newTest(), nil).Once()
+			mockVal.On("ValidateUserAnswers", mock.Anything, mock.Anything).Return(nil).Maybe()
+			mockSt.On("GetUserResults", mock.Anything, validTestId, 1).Return([]*domain.Result{}, nil).Once()
+			if !tt.wantError {
+				mockSt.On("SaveUserResult", mock.Anything, mock.AnythingOfType(resultType)).Return(nil).Once()
+			}
+
+			s := New(zap.NewExample(), &config.Config{}, mockSt, mockVal)
+			result, got := s.ApplyTest(context.Background(), validTestId, 1, "", tt.args.answers)
+
+			if tt.wantError {
+				assert.Containsf(t, got.Error(), tt.err.Error(), "expected error containing %q, got %s", tt.err.Error(), got.Error())
+			} else {
+				assert.NoError(t, got)
+				assert.Equal(t, tt.wantResultID, result.ResultID)
+				assert.Equal(t, tt.wantParams, *result.Params)
+			}
+		})
+	}
+
+}
+
+func TestService_GetTestResults(t *testing.T) {
+	validTestId := "623452gsgsgf"
+	queryType := reflect.TypeOf(domain.ResultsQuery{}).String()
+
+	type args struct {
+		ctx    context.Context
+		testID string
+	}
+	tc := []struct {
+		name      string
+		args      args
+		wantError bool
+		err       error
+		mockF     func(st *mocks.Storage)
+	}{
+		{
+			name: "ok",
+			args: args{
+				ctx: context.WithValue(context.Background(), user.AuthInfoKey, user.Info{
+					ID: 1,
+				}),
+				testID: validTestId,
+			},
+			wantError: false,
+			err:       nil,
+			mockF: func(st *mocks.Storage) {
+				gotTest := &domain.Test{
+					ID:     &validTestId,
+					UserID: p.Int(1),
+				}
+				st.On("GetTestByID", mock.Anything, validTestId, false).Return(gotTest, nil).Once()
+				st.On("GetResults", mock.Anything, mock.MatchedBy(func(q domain.ResultsQuery) bool {
+					return q.TestID != nil && *q.TestID == validTestId
+				})).Return([]*domain.Result{}, int64(0), nil).Once()
+			},
+		},
+		{
+			name: "ok, but accesed with moderator rights",
+			args: args{
+				ctx: context.WithValue(context.Background(), user.AuthInfoKey, user.Info{
+					ID:          1,
+					Permissions: []int{user.Moderator},
+				}),
+				testID: validTestId,
+			},
+			wantError: false,
+			err:       nil,
+			mockF: func(st *mocks.Storage) {
+				gotTest := &domain.Test{
+					ID:     &validTestId,
+					UserID: p.Int(2),
+				}
+				st.On("GetTestByID", mock.Anything, validTestId, false).Return(gotTest, nil).Once()
+				st.On("GetResults", mock.Anything, mock.AnythingOfType(queryType)).Return([]*domain.Result{}, int64(0), nil).Once()
+			},
+		},
+		{
+			name: "no rights to perform",
+			args: args{
+				ctx: context.WithValue(context.Background(), user.AuthInfoKey, user.Info{
+					ID:          1,
+					Permissions: []int{user.Creator},
+				}),
+				testID: validTestId,
+			},
+			wantError: true,
+			err:       errors.New("no rights to perform"),
+			mockF: func(st *mocks.Storage) {
+				gotTest := &domain.Test{
+					ID:     &validTestId,
+					UserID: p.Int(2),
+				}
+				st.On("GetTestByID", mock.Anything, validTestId, false).Return(gotTest, nil).Once()
+			},
+		},
+		{
+			name: "not found test",
+			args: args{
+				ctx: context.WithValue(context.Background(), user.AuthInfoKey, user.Info{
+					ID: 1,
+				}),
+				testID: validTestId,
+			},
+			wantError: true,
+			err:       errors.New("not found"),
+			mockF: func(st *mocks.Storage) {
+				st.On("GetTestByID", mock.Anything, validTestId, false).Return(nil, storage.ErrNotFound).Once()
+			},
+		},
+	}
+
+	for _, tt := range tc {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockVal := mocks.NewValidator(t)
+			mockSt := mocks.NewStorage(t)
+			tt.mockF(mockSt)
+
+			s := New(zap.NewExample(), &config.Config{}, mockSt, mockVal)
+			_, _, got := s.GetTestResults(tt.args.ctx, tt.args.testID, domain.ResultsQuery{Page: 1, PerPage: 5})
+
+			if tt.wantError {
+				assert.Containsf(t, got.Error(), tt.err.Error(), "expected error containing %q, got %s", tt.err.Error(), got.Error())
+			} else {
+				assert.NoError(t, got)
+			}
+		})
+	}
+
+}
+
+func TestService_ApplyTest_StrictTest(t *testing.T) {
+	validTestId := "623452gsgsgf"
+	resultType := reflect.TypeOf(domain.Result{}).String()
+
+	newTest := func(reveal bool) *domain.Test {
+		return &domain.Test{
+			ID:            &validTestId,
+			UserID:        p.Int(1),
+			Type:          p.String(domain.TestTypeStrictTest),
+			RevealAnswers: reveal,
+			Questions: &[]*domain.Question{
+				{
+					ID:      1,
+					Type:    p.String(domain.QuestionTypeSingleChoice),
+					Points:  p.Int(10),
+					Answers: &domain.AnswerModel{CorrectID: p.Int(1)},
+				},
+				{
+					ID:      2,
+					Type:    p.String(domain.QuestionTypeMultipleChoice),
+					Points:  p.Int(20),
+					Answers: &domain.AnswerModel{CorrectIDs: &[]int{1, 2}},
+				},
+				{
+					ID:      3,
+					Type:    p.String(domain.QuestionTypeManualInput),
+					Points:  p.Int(10),
+					Answers: &domain.AnswerModel{CorrectText: p.String("paris")},
+				},
+			},
+		}
+	}
+
+	tc := []struct {
+		name           string
+		reveal         bool
+		wantPoints     int
+		wantPercentage int
+		wantStatuses   []string
+	}{
+		{
+			name:           "ok",
+			reveal:         false,
+			wantPoints:     20,
+			wantPercentage: 50,
+			wantStatuses:   []string{domain.QuestionResultCorrect, domain.QuestionResultPartial, domain.QuestionResultWrong},
+		},
+		{
+			name:           "ok, with revealed answers",
+			reveal:         true,
+			wantPoints:     20,
+			wantPercentage: 50,
+			wantStatuses:   []string{domain.QuestionResultCorrect, domain.QuestionResultPartial, domain.QuestionResultWrong},
+		},
+	}
+
+	for _, tt := range tc {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockVal := mocks.NewValidator(t)
+			mockSt := mocks.NewStorage(t)
+			mockSt.On("GetTestByID", mock.Anything, validTestId, true).Return(newTest(tt.reveal), nil).Once()
+			mockVal.On("ValidateUserAnswers", mock.Anything, mock.Anything).Return(nil).Times(3)
+			mockSt.On("GetUserResults", mock.Anything, validTestId, 1).Return([]*domain.Result{
+				{Attempt: 1, Percentage: p.Int(80)},
+				{Attempt: 2, Percentage: p.Int(20)},
+			}, nil).Once()
+			mockSt.On("SaveUserResult", mock.Anything, mock.AnythingOfType(resultType)).Return(nil).Once()
+
+			s := New(zap.NewExample(), &config.Config{}, mockSt, mockVal)
+			result, err := s.ApplyTest(context.Background(), validTestId, 1, "", map[int]domain.UserAnswerModel{
+				1: {QuestionID: 1, ChosenID: p.Int(1)},
+				2: {QuestionID: 2, ChosenIDs: &[]int{1}},
+				3: {QuestionID: 3, WritedText: p.String("london")},
+			})
+
+			assert.NoError(t, err)
+			assert.NotEmpty(t, result.ID)
+			assert.False(t, result.CreatedAt.IsZero())
+			assert.Equal(t, 3, result.Attempt)
+			assert.Equal(t, tt.wantPercentage, *result.FinalPercentage)
+			assert.Equal(t, tt.wantPoints, *result.Points)
+			assert.Equal(t, 40, *result.MaxPoints)
+			assert.Equal(t, tt.wantPercentage, *result.Percentage)
+			for i, qr := range *result.Questions {
+				assert.Equal(t, tt.wantStatuses[i], qr.Status)
+				assert.Equal(t, tt.reveal, qr.CorrectAnswers != nil)
+			}
+		})
+	}
+
+}
+
+func TestService_ApplyTest_Attempts(t *testing.T) {
+	validTestId := "623452gsgsgf"
+	validAttemptId := "5gd5gdf65dgf"
+	resultType := reflect.TypeOf(domain.Result{}).String()
+
+	newTest := func(penalty *int) *domain.Test {
+		return &domain.Test{
+			ID:          &validTestId,
+			UserID:      p.Int(1),
+			Type:        p.String(domain.TestTypeStrictTest),
+			TimeLimit:   p.Int(60),
+			LatePenalty: penalty,
+			Questions: &[]*domain.Question{
+				{
+					ID:      1,
+					Type:    p.String(domain.QuestionTypeSingleChoice),
+					Points:  p.Int(10),
+					Answers: &domain.AnswerModel{CorrectID: p.Int(1)},
+				},
+			},
+		}
+	}
+	newAttempt := func(userID int, startedAgo time.Duration, submitted bool) *domain.Attempt {
+		startedAt := time.Now().UTC().Add(-startedAgo)
+		deadline := startedAt.Add(60 * time.Second)
+		a := &domain.Attempt{
+			ID:        validAttemptId,
+			TestID:    validTestId,
+			UserID:    userID,
+			StartedAt: startedAt,
+			Deadline:  &deadline,
+		}
+		if submitted {
+			a.SubmittedAt = &deadline
+		}
+		return a
+	}
+
+	tc := []struct {
+		name           string
+		attemptID      string
+		wantError      bool
+		err            error
+		wantLate       bool
+		wantPercentage int
+		mockF          func(st *mocks.Storage, val *mocks.Validator)
+	}{
+		{
+			name:           "ok",
+			attemptID:      validAttemptId,
+			wantPercentage: 100,
+			mockF: func(st *mocks.Storage, val *mocks.Validator) {
+				st.On("GetTestByID", mock.Anything, validTestId, true).Return(newTest(nil), nil).Once()
+				st.On("GetAttemptByID", mock.Anything, validAttemptId).Return(newAttempt(1, 10*time.Second, false), nil).Once()
+				val.On("ValidateUserAnswers", mock.Anything, mock.Anything).Return(nil).Once()
+				st.On("GetUserResults", mock.Anything, validTestId, 1).Return([]*domain.Result{}, nil).Once()
+				st.On("SaveUserResult", mock.Anything, mock.AnythingOfType(resultType)).Return(nil).Once()
+				st.On("FinishAttempt", mock.Anything, validAttemptId, mock.Anything, mock.Anything).Return(nil).Once()
+			},
+		},
+		{
+			name:           "ok, late with penalty",
+			attemptID:      validAttemptId,
+			wantLate:       true,
+			wantPercentage: 70,
+			mockF: func(st *mocks.Storage, val *mocks.Validator) {
+				st.On("GetTestByID", mock.Anything, validTestId, true).Return(newTest(p.Int(30)), nil).Once()
+				st.On("GetAttemptByID", mock.Anything, validAttemptId).Return(newAttempt(1, 2*time.Minute, false), nil).Once()
+				val.On("ValidateUserAnswers", mock.Anything, mock.Anything).Return(nil).Once()
+				st.On("GetUserResults", mock.Anything, validTestId, 1).Return([]*domain.Result{}, nil).Once()
+				st.On("SaveUserResult", mock.Anything, mock.AnythingOfType(resultType)).Return(nil).Once()
+				st.On("FinishAttempt", mock.Anything, validAttemptId, mock.Anything, mock.Anything).Return(nil).Once()
+			},
+		},
+		{
+			name:      "late without penalty",
+			attemptID: validAttemptId,
+			wantError: true,
+			err:       errors.New("time limit exceeded"),
+			mockF: func(st *mocks.Storage, val *mocks.Validator) {
+				st.On("GetTestByID", mock.Anything, validTestId, true).Return(newTest(nil), nil).Once()
+				st.On("GetAttemptByID", mock.Anything, validAttemptId).Return(newAttempt(1, 2*time.Minute, false), nil).Once()
+			},
+		},
+		{
+			name:      "no attempt for timed test",
+			attemptID: "",
+			wantError: true,
+			err:       errors.New("attempt required"),
+			mockF: func(st *mocks.Storage, val *mocks.Validator) {
+				st.On("GetTestByID", mock.Anything, validTestId, true).Return(newTest(nil), nil).Once()
+			},
+		},
+		{
+			name:      "attempt of other user",
+			attemptID: validAttemptId,
+			wantError: true,
+			err:       errors.New("no rights to perform"),
+			mockF: func(st *mocks.Storage, val *mocks.Validator) {
+				st.On("GetTestByID", mock.Anything, validTestId, true).Return(newTest(nil), nil).Once()
+				st.On("GetAttemptByID", mock.Anything, validAttemptId).Return(newAttempt(2, 10*time.Second, false), nil).Once()
+			},
+		},
+		{
+			name:      "attempt already submitted",
+			attemptID: validAttemptId,
+			wantError: true,
+			err:       errors.New("attempt already finished"),
+			mockF: func(st *mocks.Storage, val *mocks.Validator) {
+				st.On("GetTestByID", mock.Anything, validTestId, true).Return(newTest(nil), nil).Once()
+				st.On("GetAttemptByID", mock.Anything, validAttemptId).Return(newAttempt(1, 10*time.Second, true), nil).Once()
+			},
+		},
+		{
+			name:      "attempt submitted concurrently",
+			attemptID: validAttemptId,
+			wantError: true,
+			err:       errors.New("attempt already finished"),
+			mockF: func(st *mocks.Storage, val *mocks.Validator) {
+				st.On("GetTestByID", mock.Anything, validTestId, true).Return(newTest(nil), nil).Once()
+				st.On("GetAttemptByID", mock.Anything, validAttemptId).Return(newAttempt(1, 10*time.Second, false), nil).Once()
+				val.On("ValidateUserAnswers", mock.Anything, mock.Anything).Return(nil).Once()
+				st.On("GetUserResults", mock.Anything, validTestId, 1).Return([]*domain.Result{}, nil).Once()
+				st.On("SaveUserResult", mock.Anything, mock.AnythingOfType(resultType)).Return(nil).Once()
+				st.On("FinishAttempt", mock.Anything, validAttemptId, mock.Anything, mock.Anything).Return(storage.ErrNotFound).Once()
+				st.On("DeleteUserResult", mock.Anything, mock.AnythingOfType("string")).Return(nil).Once()
+			},
+		},
+		{
+			name:      "failed to save result keeps attempt open",
+			attemptID: validAttemptId,
+			wantError: true,
+			err:       errors.New("storage error"),
+			mockF: func(st *mocks.Storage, val *mocks.Validator) {
+				st.On("GetTestByID", mock.Anything, validTestId, true).Return(newTest(nil), nil).Once()
+				st.On("GetAttemptByID", mock.Anything, validAttemptId).Return(newAttempt(1, 10*time.Second, false), nil).Once()
+				val.On("ValidateUserAnswers", mock.Anything, mock.Anything).Return(nil).Once()
+				st.On("GetUserResults", mock.Anything, validTestId, 1).Return([]*domain.Result{}, nil).Once()
+				st.On("SaveUserResult", mock.Anything, mock.AnythingOfType(resultType)).Return(errors.New("storage error")).Once()
+			},
+		},
+	}
+
+	for _, tt := range tc {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockVal := mocks.NewValidator(t)
+			mockSt := mocks.NewStorage(t)
+			tt.mockF(mockSt, mockVal)
+
+			s := New(zap.NewExample(), &config.Config{}, mockSt, mockVal)
+			result, got := s.ApplyTest(context.Background(), validTestId, 1, tt.attemptID, map[int]domain.UserAnswerModel{
+				1: {QuestionID: 1, ChosenID: p.Int(1)},
+			})
+
+			if tt.wantError {
+				assert.Containsf(t, got.Error(), tt.err.Error(), "expected error containing %q, got %s", tt.err.Error(), got.Error())
+			} else {
+				assert.NoError(t, got)
+				assert.Equal(t, tt.wantLate, result.Late)
+				assert.Equal(t, tt.wantPercentage, *result.Percentage)
+				assert.Equal(t, validAttemptId, *result.AttemptID)
+				assert.NotNil(t, result.Duration)
+			}
+		})
+	}
+
+}
+
+func TestService_ApplyTest_RetakePolicy(t *testing.T) {
+	validTestId := "623452gsgsgf"
+
+	newTest := func(maxAttempts *int, cooldown *int, mode *string) *domain.Test {
+		return &domain.Test{
+			ID:             &validTestId,
+			UserID:         p.Int(1),
+			Type:           p.String(domain.TestTypeStrictTest),
+			MaxAttempts:    maxAttempts,
+			RetakeCooldown: cooldown,
+			ScoreMode:      mode,
+			Questions: &[]*domain.Question{
+				{
+					ID:      1,
+					Type:    p.String(domain.QuestionTypeSingleChoice),
+					Points:  p.Int(10),
+					Answers: &domain.AnswerModel{CorrectID: p.Int(1)},
+				},
+			},
+		}
+	}
+	previous := []*domain.Result{
+		{Attempt: 1, Percentage: p.Int(100), CreatedAt: time.Now().UTC().Add(-2 * time.Hour)},
+		{Attempt: 2, Percentage: p.Int(50), CreatedAt: time.Now().UTC().Add(-time.Hour)},
+	}
+
+	// Concurrent submission takes the third attempt between reading results and saving
+	concurrent := append(previous, &domain.Result{Attempt: 3, Percentage: p.Int(0), CreatedAt: time.Now().UTC()})
+
+	tc := []struct {
+		name        string
+		test        *domain.Test
+		concurrent  bool
+		wantError   bool
+		err         error
+		wantAttempt int
+		wantFinal   int
+	}{
+		{
+			name:        "ok, best score counts",
+			test:        newTest(p.Int(3), p.Int(60), p.String(domain.ScoreModeBest)),
+			wantAttempt: 3,
+			wantFinal:   100,
+		},
+		{
+			name:        "ok, average score counts",
+			test:        newTest(nil, nil, p.String(domain.ScoreModeAverage)),
+			wantAttempt: 3,
+			wantFinal:   50,
+		},
+		{
+			name:      "attempts limit reached",
+			test:      newTest(p.Int(2), nil, nil),
+			wantError: true,
+			err:       errors.New("attempts limit reached"),
+		},
+		{
+			name:      "cooldown is not over",
+			test:      newTest(nil, p.Int(2*60*60), nil),
+			wantError: true,
+			err:       errors.New("retake cooldown is not over"),
+		},
+		{
+			name:       "last attempt taken by concurrent submission",
+			test:       newTest(p.Int(3), nil, nil),
+			concurrent: true,
+			wantError:  true,
+			err:        errors.New("attempts limit reached"),
+		},
+		{
+			name:        "ok, attempt number taken by concurrent submission on unlimited test",
+			test:        newTest(nil, nil, p.String(domain.ScoreModeAverage)),
+			concurrent:  true,
+			wantAttempt: 4,
+			wantFinal:   37,
+		},
+	}
+
+	for _, tt := range tc {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockVal := mocks.NewValidator(t)
+			mockSt := mocks.NewStorage(t)
+			mockSt.On("GetTestByID", mock.Anything, validTestId, true).Return(tt.test, nil).Once()
+			mockSt.On("GetUserResults", mock.Anything, validTestId, 1).Return(previous, nil).Once()
+			if !tt.wantError || tt.concurrent {
+				mockVal.On("ValidateUserAnswers", mock.Anything, mock.Anything).Return(nil).Once()
+			}
+			if tt.concurrent {
+				mockSt.On("SaveUserResult", mock.Anything, mock.MatchedBy(func(r domain.Result) bool {
+					return r.Attempt == 3
+				})).Return(storage.ErrExists).Once()
+				mockSt.On("GetUserResults", mock.Anything, validTestId, 1).Return(concurrent, nil).Once()
+			}
+			if !tt.wantError {
+				mockSt.On("SaveUserResult", mock.Anything, mock.MatchedBy(func(r domain.Result) bool {
+					return r.Attempt == tt.wantAttempt
+				})).Return(nil).Once()
+			}
+
+			s := New(zap.NewExample(), &config.Config{}, mockSt, mockVal)
+			result, got := s.ApplyTest(context.Background(), validTestId, 1, "", map[int]domain.UserAnswerModel{
+				1: {QuestionID: 1, ChosenID: p.Int(2)},
+			})
+
+			if tt.wantError {
+				assert.Containsf(t, got.Error(), tt.err.Error(), "expected error containing %q, got %s", tt.err.Error(), got.Error())
+			} else {
+				assert.NoError(t, got)
+				assert.Equal(t, tt.wantAttempt, result.Attempt)
+				assert.Equal(t, tt.wantFinal, *result.FinalPercentage)
+			}
+		})
+	}
+
+}
+
+func TestService_SaveAttemptAnswers(t *testing.T) {
+	validTestId := "623452gsgsgf"
+	validAttemptId := "5gd5gdf65dgf"
+
+	test := &domain.Test{
+		ID:        &validTestId,
+		UserID:    p.Int(1),
+		Type:      p.String(domain.TestTypeStrictTest),
+		TimeLimit: p.Int(60),
+		Questions: &[]*domain.Question{
+			{ID: 1, Type: p.String(domain.QuestionTypeSingleChoice)},
+			{ID: 2, Type: p.String(domain.QuestionTypeSingleChoice)},
+		},
+	}
+	newAttempt := func(startedAgo time.Duration, submitted bool) *domain.Attempt {
+		startedAt := time.Now().UTC().Add(-startedAgo)
+		deadline := startedAt.Add(60 * time.Second)
+		a := &domain.Attempt{
+			ID:        validAttemptId,
+			TestID:    validTestId,
+			UserID:    1,
+			StartedAt: startedAt,
+			Deadline:  &deadline,
+			Answers: []domain.UserAnswerModel{
+				{QuestionID: 1, ChosenID: p.Int(1)},
+				{QuestionID: 2, ChosenID: p.Int(1)},
+			},
+		}
+		if submitted {
+			a.SubmittedAt = &deadline
+		}
+		return a
+	}
+	tc := []struct {
+		name      string
+		ctx       context.Context
+		wantError bool
+		err       error
+		mockF     func(st *mocks.Storage, val *mocks.Validator)
+	}{
+		{
+			name: "ok, only given answers are written",
+			ctx:  ctxWithUser(1, user.Creator),
+			mockF: func(st *mocks.Storage, val *mocks.Validator) {
+				st.On("GetAttemptByID", mock.Anything, validAttemptId).Return(newAttempt(10*time.Second, false), nil).Once()
+				st.On("GetTestByID", mock.Anything, validTestId, false).Return(test, nil).Once()
+				val.On("ValidateUserAnswers", mock.Anything, mock.Anything).Return(nil).Once()
+				// Answer on the first question can be saved concurrently from other device, so it isn't written back
+				st.On("UpdateAttemptAnswers", mock.Anything, validAttemptId, []domain.UserAnswerModel{
+					{QuestionID: 2, ChosenID: p.Int(2)},
+				}).Return(nil).Once()
+			},
+		},
+		{
+			name:      "attempt of other user",
+			ctx:       ctxWithUser(2, user.Creator),
+			wantError: true,
+			err:       errors.New("no rights to perform"),
+			mockF: func(st *mocks.Storage, val *mocks.Validator) {
+				st.On("GetAttemptByID", mock.Anything, validAttemptId).Return(newAttempt(10*time.Second, false), nil).Once()
+			},
+		},
+		{
+			name:      "attempt already submitted",
+			ctx:       ctxWithUser(1, user.Creator),
+			wantError: true,
+			err:       errors.New("attempt already finished"),
+			mockF: func(st *mocks.Storage, val *mocks.Validator) {
+				st.On("GetAttemptByID", mock.Anything, validAttemptId).Return(newAttempt(10*time.Second, true), nil).Once()
+			},
+		},
+		{
+			name:      "after deadline",
+			ctx:       ctxWithUser(1, user.Creator),
+			wantError: true,
+			err:       errors.New("time limit exceeded"),
+			mockF: func(st *mocks.Storage, val *mocks.Validator) {
+				st.On("GetAttemptByID", mock.Anything, validAttemptId).Return(newAttempt(2*time.Minute, false), nil).Once()
+				st.On("GetTestByID", mock.Anything, validTestId, false).Return(test, nil).Once()
+			},
+		},
+		{
+			name:      "invalid answer",
+			ctx:       ctxWithUser(1, user.Creator),
+			wantError: true,
+			err:       errors.New("failed test validation"),
+			mockF: func(st *mocks.Storage, val *mocks.Validator) {
+				st.On("GetAttemptByID", mock.Anything, validAttemptId).Return(newAttempt(10*time.Second, false), nil).Once()
+				st.On("GetTestByID", mock.Anything, validTestId, false).Return(test, nil).Once()
+				val.On("ValidateUserAnswers", mock.Anything, mock.Anything).Return(errors.New("invalid")).Once()
+			},
+		},
+	}
+
+	for _, tt := range tc {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockVal := mocks.NewValidator(t)
+			mockSt := mocks.NewStorage(t)
+			tt.mockF(mockSt, mockVal)
+
+			s := New(zap.NewExample(), &config.Config{}, mockSt, mockVal)
+			attempt, got := s.SaveAttemptAnswers(tt.ctx, validAttemptId, map[int]domain.UserAnswerModel{
+				2: {QuestionID: 2, ChosenID: p.Int(2)},
+			})
+
+			if tt.wantError {
+				assert.Containsf(t, got.Error(), tt.err.Error(), "expected error containing %q, got %s", tt.err.Error(), got.Error())
+			} else {
+				assert.NoError(t, got)
+				assert.Len(t, attempt.Answers, 2)
+			}
+		})
+	}
+}
+
+func TestService_ApplyTest_Layout(t *testing.T) {
+	validTestId := "623452gsgsgf"
+	validAttemptId := "5gd5gdf65dgf"
+	resultType := reflect.TypeOf(domain.Result{}).String()
+
+	newTest := func() *domain.Test {
+		return &domain.Test{
+			ID:            &validTestId,
+			UserID:        p.Int(1),
+			Type:          p.String(domain.TestTypeStrictTest),
+			QuestionsPool: p.Int(1),
+			Questions: &[]*domain.Question{
+				{
+					ID:       1,
+					Type:     p.String(domain.QuestionTypeSingleChoice),
+					Required: true,
+					Points:   p.Int(10),
+					Answers:  &domain.AnswerModel{CorrectID: p.Int(1)},
+				},
+				{
+					ID:      2,
+					Type:    p.String(domain.QuestionTypeSingleChoice),
+					Points:  p.Int(10),
+					Answers: &domain.AnswerModel{CorrectID: p.Int(2)},
+				},
+			},
+		}
+	}
+	layout := []*domain.QuestionLayout{{QuestionID: 2, VariantIDs: []int{2, 1}}}
+
+	tc := []struct {
+		name      string
+		attemptID string
+		wantError bool
+		err       error
+		mockF     func(st *mocks.Storage, val *mocks.Validator)
+	}{
+		{
+			name:      "ok, only drawn questions are scored",
+			attemptID: validAttemptId,
+			mockF: func(st *mocks.Storage, val *mocks.Validator) {
+				st.On("GetTestByID", mock.Anything, validTestId, true).Return(newTest(), nil).Once()
+				st.On("GetAttemptByID", mock.Anything, validAttemptId).Return(&domain.Attempt{
+					ID:        validAttemptId,
+					TestID:    validTestId,
+					UserID:    1,
+					StartedAt: time.Now().UTC(),
+					Layout:    layout,
+				}, nil).Once()
+				val.On("ValidateUserAnswers", mock.Anything, mock.Anything).Return(nil).Once()
+				st.On("GetUserResults", mock.Anything, validTestId, 1).Return([]*domain.Result{}, nil).Once()
+				st.On("FinishAttempt", mock.Anything, validAttemptId, mock.Anything, mock.Anything).Return(nil).Once()
+				st.On("SaveUserResult", mock.Anything, mock.AnythingOfType(resultType)).Return(nil).Once()
+			},
+		},
+		{
+			name:      "no attempt for randomized test",
+			attemptID: "",
+			wantError: true,
+			err:       errors.New("attempt required"),
+			mockF: func(st *mocks.Storage, val *mocks.Validator) {
+				st.On("GetTestByID", mock.Anything, validTestId, true).Return(newTest(), nil).Once()
+			},
+		},
+	}
+
+	for _, tt := range tc {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockVal := mocks.NewValidator(t)
+			mockSt := mocks.NewStorage(t)
+			tt.mockF(mockSt, mockVal)
+
+			s := New(zap.NewExample(), &config.Config{}, mockSt, mockVal)
+			result, got := s.ApplyTest(context.Background(), validTestId, 1, tt.attemptID, map[int]domain.UserAnswerModel{
+				2: {QuestionID: 2, ChosenID: p.Int(2)},
+			})
+
+			if tt.wantError {
+				assert.Containsf(t, got.Error(), tt.err.Error(), "expected error containing %q, got %s", tt.err.Error(), got.Error())
+			} else {
+				assert.NoError(t, got)
+				assert.Equal(t, 100, *result.Percentage)
+				assert.Equal(t, 10, *result.MaxPoints)
+				assert.Equal(t, layout, result.Layout)
+			}
+		})
+	}
+}
+
+func TestService_CreateTest_BankQuestions(t *testing.T) {
+	bankID := "6fd7gdfg76"
+
+	bank := &domain.QuestionBank{
+		ID:     &bankID,
+		UserID: p.Int(1),
+		Questions: &[]*domain.Question{
+			{
+				ID:      5,
+				Type:    p.String(domain.QuestionTypeSingleChoice),
+				Points:  p.Int(1),
+				Answers: &domain.AnswerModel{CorrectID: p.Int(1)},
+			},
+		},
+	}
+	newTest := func(questionID int) domain.Test {
+		return domain.Test{
+			UserID: p.Int(1),
+			Type:   p.String(domain.TestTypeStrictTest),
+			Questions: &[]*domain.Question{
+				{ID: 1, Points: p.Int(10), BankRef: &domain.BankRef{BankID: bankID, QuestionID: questionID}},
+			},
+		}
+	}
+	tc := []struct {
+		name      string
+		ctx       context.Context
+		test      domain.Test
+		wantError bool
+		err       error
+		mockF     func(st *mocks.Storage, val *mocks.Validator)
+	}{
+		{
+			name: "ok, validated expanded and stored with references",
+			ctx:  ctxWithUser(1, user.Creator),
+			test: newTest(5),
+			mockF: func(st *mocks.Storage, val *mocks.Validator) {
+				st.On("GetQuestionBanksByIDs", mock.Anything, []string{bankID}, true).Return([]*domain.QuestionBank{bank}, nil).Once()
+				val.On("ValidateTest", mock.MatchedBy(func(test domain.Test) bool {
+					q := (*test.Questions)[0]
+					return q.ID == 1 && q.Type != nil && *q.Points == 10
+				})).Return(nil).Once()
+				st.On("CreateTest", mock.Anything, mock.MatchedBy(func(test domain.Test) bool {
+					q := (*test.Questions)[0]
+					return q.Type == nil && q.BankRef != nil
+				})).Return(nil).Once()
+				st.On("CreateTestVersion", mock.Anything, mock.AnythingOfType("domain.TestVersion")).Return(nil).Once()
+			},
+		},
+		{
+			name:      "bank of other user",
+			ctx:       ctxWithUser(2, user.Creator),
+			test:      newTest(5),
+			wantError: true,
+			err:       errors.New("no rights to perform"),
+			mockF: func(st *mocks.Storage, val *mocks.Validator) {
+				st.On("GetQuestionBanksByIDs", mock.Anything, []string{bankID}, true).Return([]*domain.QuestionBank{bank}, nil).Once()
+			},
+		},
+		{
+			name:      "missing bank question",
+			ctx:       ctxWithUser(1, user.Creator),
+			test:      newTest(6),
+			wantError: true,
+			err:       errors.New("failed test validation"),
+			mockF: func(st *mocks.Storage, val *mocks.Validator) {
+				st.On("GetQuestionBanksByIDs", mock.Anything, []string{bankID}, true).Return([]*domain.QuestionBank{bank}, nil).Once()
+			},
+		},
+	}
+
+	for _, tt := range tc {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockVal := mocks.NewValidator(t)
+			mockSt := mocks.NewStorage(t)
+			tt.mockF(mockSt, mockVal)
+
+			s := New(zap.NewExample(), &config.Config{}, mockSt, mockVal)
+			_, got := s.CreateTest(tt.ctx, tt.test)
+
+			if tt.wantError {
+				assert.Containsf(t, got.Error(), tt.err.Error(), "expected error containing %q, got %s", tt.err.Error(), got.Error())
+			} else {
+				assert.NoError(t, got)
+			}
+		})
+	}
+
+}
+
+func TestService_DeleteQuestionBank(t *testing.T) {
+	bankID := "6fd7gdfg76"
+	bank := &domain.QuestionBank{ID: &bankID, UserID: p.Int(1)}
+
+	tc := []struct {
+		name      string
+		ctx       context.Context
+		wantError bool
+		err       error
+		mockF     func(st *mocks.Storage)
+	}{
+		{
+			name: "ok",
+			ctx:  ctxWithUser(1, user.Creator),
+			mockF: func(st *mocks.Storage) {
+				st.On("GetQuestionBankByID", mock.Anything, bankID, true).Return(bank, nil).Once()
+				st.On("CountTestsUsingBank", mock.Anything, bankID).Return(int64(0), nil).Once()
+				st.On("DeleteQuestionBank", mock.Anything, bankID).Return(nil).Once()
+			},
+		},
+		{
+			name:      "used by tests",
+			ctx:       ctxWithUser(1, user.Creator),
+			wantError: true,
+			err:       errors.New("question bank is used by tests"),
+			mockF: func(st *mocks.Storage) {
+				st.On("GetQuestionBankByID", mock.Anything, bankID, true).Return(bank, nil).Once()
+				st.On("CountTestsUsingBank", mock.Anything, bankID).Return(int64(2), nil).Once()
+			},
+		},
+		{
+			name:      "bank of other user",
+			ctx:       ctxWithUser(2, user.Creator),
+			wantError: true,
+			err:       errors.New("no rights to perform"),
+			mockF: func(st *mocks.Storage) {
+				st.On("GetQuestionBankByID", mock.Anything, bankID, true).Return(bank, nil).Once()
+			},
+		},
+		{
+			name:      "not found",
+			ctx:       ctxWithUser(1, user.Creator),
+			wantError: true,
+			err:       errors.New("not found"),
+			mockF: func(st *mocks.Storage) {
+				st.On("GetQuestionBankByID", mock.Anything, bankID, true).Return(nil, storage.ErrNotFound).Once()
+			},
+		},
+	}
+
+	for _, tt := range tc {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockVal := mocks.NewValidator(t)
+			mockSt := mocks.NewStorage(t)
+			tt.mockF(mockSt)
+
+			s := New(zap.NewExample(), &config.Config{}, mockSt, mockVal)
+			got := s.DeleteQuestionBank(tt.ctx, bankID)
+
+			if tt.wantError {
+				assert.Containsf(t, got.Error(), tt.err.Error(), "expected error containing %q, got %s", tt.err.Error(), got.Error())
+			} else {
+				assert.NoError(t, got)
+			}
+		})
+	}
+}
+
+func TestService_ApplyTest_QuestionTypes(t *testing.T) {
+	validTestId := "623452gsgsgf"
+	resultType := reflect.TypeOf(domain.Result{}).String()
+
+	test := &domain.Test{
+		ID:     &validTestId,
+		UserID: p.Int(1),
+		Type:   p.String(domain.TestTypeStrictTest),
+		Questions: &[]*domain.Question{
+			{
+				ID:      1,
+				Type:    p.String(domain.QuestionTypeOrdering),
+				Points:  p.Int(10),
+				Answers: &domain.AnswerModel{CorrectOrder: &[]int{1, 2, 3, 4}},
+			},
+			{
+				ID:     2,
+				Type:   p.String(domain.QuestionTypeMatching),
+				Points: p.Int(10),
+				Answers: &domain.AnswerModel{CorrectPairs: &[]*domain.MatchPair{
+					{LeftID: 1, RightID: 1},
+					{LeftID: 2, RightID: 2},
+				}},
+			},
+			{
+				ID:      3,
+				Type:    p.String(domain.QuestionTypeNumeric),
+				Points:  p.Int(10),
+				Answers: &domain.AnswerModel{CorrectNumber: p.Float(3.14), Tolerance: p.Float(0.01)},
+			},
+			{
+				ID:      4,
+				Type:    p.String(domain.QuestionTypeTrueFalse),
+				Points:  p.Int(10),
+				Answers: &domain.AnswerModel{CorrectBool: p.Bool(true)},
+			},
+		},
+	}
+
+	mockVal := mocks.NewValidator(t)
+	mockSt := mocks.NewStorage(t)
+	mockSt.On("GetTestByID", mock.Anything, validTestId, true).Return(test, nil).Once()
+	mockVal.On("ValidateUserAnswers", mock.Anything, mock.Anything).Return(nil).Times(4)
+	mockSt.On("GetUserResults", mock.Anything, validTestId, 1).Return([]*domain.Result{}, nil).Once()
+	mockSt.On("SaveUserResult", mock.Anything, mock.AnythingOfType(resultType)).Return(nil).Once()
+
+	s := New(zap.NewExample(), &config.Config{}, mockSt, mockVal)
+	result, err := s.ApplyTest(context.Background(), validTestId, 1, "", map[int]domain.UserAnswerModel{
+		1: {QuestionID: 1, OrderedIDs: &[]int{1, 2, 4, 3}},
+		2: {QuestionID: 2, Pairs: &[]*domain.MatchPair{{LeftID: 1, RightID: 1}, {LeftID: 2, RightID: 1}}},
+		3: {QuestionID: 3, Number: p.Float(3.141)},
+		4: {QuestionID: 4, ChosenBool: p.Bool(false)},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 20, *result.Points)
+	assert.Equal(t, 50, *result.Percentage)
+
+	statuses := make([]string, 0, len(*result.Questions))
+	for _, q := range *result.Questions {
+		statuses = append(statuses, q.Status)
+	}
+	assert.Equal(t, []string{
+		domain.QuestionResultPartial,
+		domain.QuestionResultPartial,
+		domain.QuestionResultCorrect,
+		domain.QuestionResultWrong,
+	}, statuses)
+}
+
+func TestService_ApplyTest_ManualInput(t *testing.T) {
+	validTestId := "623452gsgsgf"
+	resultType := reflect.TypeOf(domain.Result{}).String()
+
+	tc := []struct {
+		name    string
+		answer  domain.AnswerModel
+		text    string
+		correct bool
+	}{
+		{
+			name:    "exact",
+			answer:  domain.AnswerModel{CorrectText: p.String("Paris")},
+			text:    "Paris",
+			correct: true,
+		},
+		{
+			name:    "exact, other case",
+			answer:  domain.AnswerModel{CorrectText: p.String("Paris")},
+			text:    "paris",
+			correct: false,
+		},
+		{
+			name: "case and whitespace insensitive",
+			answer: domain.AnswerModel{
+				CorrectText:  p.String("Paris"),
+				TextMatching: &domain.TextMatching{CaseInsensitive: true, IgnoreWhitespace: true},
+			},
+			text:    " paris  ",
+			correct: true,
+		},
+		{
+			name: "alternative text",
+			answer: domain.AnswerModel{
+				CorrectText:      p.String("Saint Petersburg"),
+				AlternativeTexts: &[]string{"St. Petersburg", "Leningrad"},
+			},
+			text:    "Leningrad",
+			correct: true,
+		},
+		{
+			name: "unicode normalization",
+			answer: domain.AnswerModel{
+				CorrectText:  p.String("café"),
+				TextMatching: &domain.TextMatching{NormalizeUnicode: true},
+			},
+			text:    "cafe\u0301",
+			correct: true,
+		},
+		{
+			name: "pattern",
+			answer: domain.AnswerModel{
+				TextMatching: &domain.TextMatching{Pattern: p.String(`colou?r`), CaseInsensitive: true},
+			},
+			text:    "Colour",
+			correct: true,
+		},
+		{
+			name: "pattern must match whole text",
+			answer: domain.AnswerModel{
+				TextMatching: &domain.TextMatching{Pattern: p.String(`colou?r`)},
+			},
+			text:    "colors",
+			correct: false,
+		},
+		{
+			name: "empty text is not a typo",
+			answer: domain.AnswerModel{
+				CorrectText:  p.String("Rome"),
+				TextMatching: &domain.TextMatching{MaxDistance: p.Int(4)},
+			},
+			text:    "",
+			correct: false,
+		},
+		{
+			name: "typo beyond distance",
+			answer: domain.AnswerModel{
+				CorrectText:  p.String("Mississippi"),
+				TextMatching: &domain.TextMatching{MaxDistance: p.Int(2)},
+			},
+			text:    "Misisipi",
+			correct: false,
+		},
+		{
+			name: "typo within distance",
+			answer: domain.AnswerModel{
+				CorrectText:  p.String("Mississippi"),
+				TextMatching: &domain.TextMatching{MaxDistance: p.Int(3)},
+			},
+			text:    "Misisipi",
+			correct: true,
+		},
+	}
+
+	for _, tt := range tc {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			answer := tt.answer
+			test := &domain.Test{
+				ID:     &validTestId,
+				UserID: p.Int(1),
+				Type:   p.String(domain.TestTypeStrictTest),
+				Questions: &[]*domain.Question{
+					{ID: 1, Type: p.String(domain.QuestionTypeManualInput), Points: p.Int(10), Answers: &answer},
+				},
+			}
+
+			mockVal := mocks.NewValidator(t)
+			mockSt := mocks.NewStorage(t)
+			mockSt.On("GetTestByID", mock.Anything, validTestId, true).Return(test, nil).Once()
+			mockVal.On("ValidateUserAnswers", mock.Anything, mock.Anything).Return(nil).Once()
+			mockSt.On("GetUserResults", mock.Anything, validTestId, 1).Return([]*domain.Result{}, nil).Once()
+			mockSt.On("SaveUserResult", mock.Anything, mock.AnythingOfType(resultType)).Return(nil).Once()
+
+			s := New(zap.NewExample(), &config.Config{}, mockSt, mockVal)
+			result, err := s.ApplyTest(context.Background(), validTestId, 1, "", map[int]domain.UserAnswerModel{
+				1: {QuestionID: 1, WritedText: p.String(tt.text)},
+			})
+
+			assert.NoError(t, err)
+			if tt.correct {
+				assert.Equal(t, 100, *result.Percentage)
+			} else {
+				assert.Equal(t, 0, *result.Percentage)
+			}
+		})
+	}
+}
+
+func TestService_ApplyTest_MultipleChoiceScoring(t *testing.T) {
+	validTestId := "623452gsgsgf"
+	resultType := reflect.TypeOf(domain.Result{}).String()
+
+	newTest := func(scoring *string) *domain.Test {
+		fields := []*domain.CommonField{{FieldID: 1}, {FieldID: 2}, {FieldID: 3}, {FieldID: 4}}
+		return &domain.Test{
+			ID:     &validTestId,
+			UserID: p.Int(1),
+			Type:   p.String(domain.TestTypeStrictTest),
+			Questions: &[]*domain.Question{
+				{
+					ID:       1,
+					Type:     p.String(domain.QuestionTypeMultipleChoice),
+					Points:   p.Int(20),
+					Scoring:  scoring,
+					Variants: &domain.VariantsModel{MultipleChoice: &domain.MultipleChoice{MaxChoices: p.Int(4), Fields: &fields}},
+					Answers:  &domain.AnswerModel{CorrectIDs: &[]int{1, 2}},
+				},
+				{
+					ID:      2,
+					Type:    p.String(domain.QuestionTypeSingleChoice),
+					Points:  p.Int(10),
+					Answers: &domain.AnswerModel{CorrectID: p.Int(1)},
+				},
+			},
+		}
+	}
+
+	tc := []struct {
+		name       string
+		scoring    *string
+		chosen     []int
+		wantPoints int
+	}{
+		{
+			name:       "all or nothing, all correct",
+			scoring:    p.String(domain.ScoringAllOrNothing),
+			chosen:     []int{1, 2},
+			wantPoints: 30,
+		},
+		{
+			name:       "all or nothing, partially correct",
+			scoring:    p.String(domain.ScoringAllOrNothing),
+			chosen:     []int{1},
+			wantPoints: 10,
+		},
+		{
+			name:       "proportional, every option chosen",
+			scoring:    p.String(domain.ScoringProportional),
+			chosen:     []int{1, 2, 3, 4},
+			wantPoints: 10,
+		},
+		{
+			name:       "proportional, partially correct",
+			scoring:    p.String(domain.ScoringProportional),
+			chosen:     []int{1},
+			wantPoints: 20,
+		},
+		{
+			name:       "proportional by default, every option chosen",
+			scoring:    nil,
+			chosen:     []int{1, 2, 3, 4},
+			wantPoints: 10,
+		},
+		{
+			name:       "negative, one right and one wrong",
+			scoring:    p.String(domain.ScoringNegative),
+			chosen:     []int{1, 3},
+			wantPoints: 10,
+		},
+		{
+			name:       "negative, takes points of other questions and floors at zero",
+			scoring:    p.String(domain.ScoringNegative),
+			chosen:     []int{3, 4},
+			wantPoints: 0,
+		},
+	}
+
+	for _, tt := range tc {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockVal := mocks.NewValidator(t)
+			mockSt := mocks.NewStorage(t)
+			mockSt.On("GetTestByID", mock.Anything, validTestId, true).Return(newTest(tt.scoring), nil).Once()
+			mockVal.On("ValidateUserAnswers", mock.Anything, mock.Anything).Return(nil).Times(2)
+			mockSt.On("GetUserResults", mock.Anything, validTestId, 1).Return([]*domain.Result{}, nil).Once()
+			mockSt.On("SaveUserResult", mock.Anything, mock.AnythingOfType(resultType)).Return(nil).Once()
+
+			s := New(zap.NewExample(), &config.Config{}, mockSt, mockVal)
+			chosen := tt.chosen
+			result, err := s.ApplyTest(context.Background(), validTestId, 1, "", map[int]domain.UserAnswerModel{
+				1: {QuestionID: 1, ChosenIDs: &chosen},
+				2: {QuestionID: 2, ChosenID: p.Int(1)},
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantPoints, *result.Points)
+		})
+	}
+}
+
+func TestService_ApplyTest_NeedsReview(t *testing.T) {
+	validTestId := "623452gsgsgf"
+	resultType := reflect.TypeOf(domain.Result{}).String()
+
+	test := &domain.Test{
+		ID:      &validTestId,
+		UserID:  p.Int(1),
+		Type:    p.String(domain.TestTypeStrictTest),
+		Version: 2,
+		Questions: &[]*domain.Question{
+			{ID: 1, Type: p.String(domain.QuestionTypeManualInput), Points: p.Int(10), NeedsReview: true},
+			{ID: 2, Type: p.String(domain.QuestionTypeSingleChoice), Points: p.Int(10), Answers: &domain.AnswerModel{CorrectID: p.Int(1)}},
+		},
+	}
+
+	mockVal := mocks.NewValidator(t)
+	mockSt := mocks.NewStorage(t)
+	mockSt.On("GetTestByID", mock.Anything, validTestId, true).Return(test, nil).Once()
+	mockVal.On("ValidateUserAnswers", mock.Anything, mock.Anything).Return(nil).Times(2)
+	mockSt.On("GetUserResults", mock.Anything, validTestId, 1).Return([]*domain.Result{}, nil).Once()
+	mockSt.On("SaveUserResult", mock.Anything, mock.AnythingOfType(resultType)).Return(nil).Once()
+
+	s := New(zap.NewExample(), &config.Config{}, mockSt, mockVal)
+	result, err := s.ApplyTest(context.Background(), validTestId, 1, "", map[int]domain.UserAnswerModel{
+		1: {QuestionID: 1, WritedText: p.String("My essay")},
+		2: {QuestionID: 2, ChosenID: p.Int(1)},
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, result.Pending)
+	assert.Equal(t, 2, result.TestVersion)
+	assert.Equal(t, 10, *result.Points)
+	assert.Equal(t, 20, *result.MaxPoints)
+	assert.Equal(t, domain.QuestionResultPending, (*result.Questions)[0].Status)
+	assert.True(t, (*result.Questions)[0].Pending)
+}
+
+func TestService_GradeAnswers(t *testing.T) {
+	validTestId := "623452gsgsgf"
+	resultID := "5gf8sd7g5sd"
+	resultType := reflect.TypeOf(domain.Result{}).String()
+
+	// Late penalty was changed by the test author after results were submitted
+	test := &domain.Test{ID: &validTestId, UserID: p.Int(1), Type: p.String(domain.TestTypeStrictTest), LatePenalty: p.Int(10)}
+	newResult := func() *domain.Result {
+		return &domain.Result{
+			ID:      resultID,
+			TestID:  validTestId,
+			UserID:  5,
+			Attempt: 1,
+			Pending: true,
+			Questions: &[]*domain.QuestionResult{
+				{QuestionID: 1, Status: domain.QuestionResultPending, MaxPoints: 10, Pending: true},
+				{QuestionID: 2, Status: domain.QuestionResultCorrect, Points: 10, MaxPoints: 10},
+			},
+		}
+	}
+
+	tc := []struct {
+		name       string
+		ctx        context.Context
+		grades     []domain.Grade
+		result     *domain.Result
+		wantPoints int
+		wantError  bool
+		err        error
+		mockF      func(st *mocks.Storage)
+	}{
+		{
+			name:       "ok by test creator",
+			ctx:        ctxWithUser(1, user.Creator),
+			grades:     []domain.Grade{{QuestionID: 1, Points: 7, Comment: p.String("Good")}},
+			result:     newResult(),
+			wantPoints: 17,
+			mockF: func(st *mocks.Storage) {
+				st.On("UpdateResultScore", mock.Anything, mock.AnythingOfType(resultType)).Return(nil).Once()
+			},
+		},
+		{
+			name:       "ok by moderator",
+			ctx:        ctxWithUser(3, user.Moderator),
+			grades:     []domain.Grade{{QuestionID: 1, Points: 10}},
+			result:     newResult(),
+			wantPoints: 20,
+			mockF: func(st *mocks.Storage) {
+				st.On("UpdateResultScore", mock.Anything, mock.AnythingOfType(resultType)).Return(nil).Once()
+			},
+		},
+		{
+			name:   "late result keeps penalty applied at submission",
+			ctx:    ctxWithUser(1, user.Creator),
+			grades: []domain.Grade{{QuestionID: 1, Points: 10}},
+			result: func() *domain.Result {
+				r := newResult()
+				r.Late = true
+				r.LatePenalty = 50
+				return r
+			}(),
+			wantPoints: 10,
+			mockF: func(st *mocks.Storage) {
+				st.On("UpdateResultScore", mock.Anything, mock.AnythingOfType(resultType)).Return(nil).Once()
+			},
+		},
+		{
+			name:      "question doesn't need grading",
+			ctx:       ctxWithUser(1, user.Creator),
+			grades:    []domain.Grade{{QuestionID: 2, Points: 5}},
+			result:    newResult(),
+			wantError: true,
+			err:       errors.New("failed test validation"),
+			mockF:     func(st *mocks.Storage) {},
+		},
+		{
+			name:      "points above max",
+			ctx:       ctxWithUser(1, user.Creator),
+			grades:    []domain.Grade{{QuestionID: 1, Points: 11}},
+			result:    newResult(),
+			wantError: true,
+			err:       errors.New("failed test validation"),
+			mockF:     func(st *mocks.Storage) {},
+		},
+		{
+			name:      "not a test creator",
+			ctx:       ctxWithUser(3, user.Creator),
+			grades:    []domain.Grade{{QuestionID: 1, Points: 5}},
+			result:    newResult(),
+			wantError: true,
+			err:       errors.New("no rights to perform"),
+			mockF:     func(st *mocks.Storage) {},
+		},
+		{
+			name:      "result is not pending",
+			ctx:       ctxWithUser(1, user.Creator),
+			grades:    []domain.Grade{{QuestionID: 1, Points: 5}},
+			result:    &domain.Result{ID: resultID, TestID: validTestId, UserID: 5},
+			wantError: true,
+			err:       errors.New("result is not pending review"),
+			mockF:     func(st *mocks.Storage) {},
+		},
+	}
+
+	for _, tt := range tc {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockVal := mocks.NewValidator(t)
+			mockSt := mocks.NewStorage(t)
+			mockSt.On("GetResultByID", mock.Anything, resultID).Return(tt.result, nil).Once()
+			mockSt.On("GetTestByID", mock.Anything, validTestId, false).Return(test, nil).Once()
+			tt.mockF(mockSt)
+
+			s := New(zap.NewExample(), &config.Config{}, mockSt, mockVal)
+			result, err := s.GradeAnswers(tt.ctx, resultID, tt.grades)
+
+			if tt.wantError {
+				assert.Containsf(t, err.Error(), tt.err.Error(), "expected error containing %q, got %s", tt.err.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, result.Pending)
+				assert.Equal(t, tt.wantPoints, *result.Points)
+				assert.True(t, (*result.Questions)[0].Graded)
+			}
+		})
+	}
+}
+
+func TestService_FinalizeResult(t *testing.T) {
+	validTestId := "623452gsgsgf"
+	resultID := "5gf8sd7g5sd"
+	resultType := reflect.TypeOf(domain.Result{}).String()
+
+	test := &domain.Test{
+		ID:        &validTestId,
+		UserID:    p.Int(1),
+		Type:      p.String(domain.TestTypeStrictTest),
+		ScoreMode: p.String(domain.ScoreModeBest),
+	}
+	newResult := func(graded bool) *domain.Result {
+		points := 0
+		if graded {
+			points = 5
+		}
+		return &domain.Result{
+			ID:      resultID,
+			TestID:  validTestId,
+			UserID:  5,
+			Attempt: 2,
+			Pending: true,
+			Questions: &[]*domain.QuestionResult{
+				{QuestionID: 1, Status: domain.QuestionResultPending, Points: points, MaxPoints: 10, Pending: true, Graded: graded},
+				{QuestionID: 2, Status: domain.QuestionResultCorrect, Points: 10, MaxPoints: 10},
+			},
+		}
+	}
+	ctx := context.WithValue(context.Background(), user.AuthInfoKey, user.Info{ID: 1, Permissions: []int{user.Creator}})
+
+	tc := []struct {
+		name      string
+		result    *domain.Result
+		wantError bool
+		err       error
+		mockF     func(st *mocks.Storage)
+	}{
+		{
+			name:   "ok",
+			result: newResult(true),
+			mockF: func(st *mocks.Storage) {
+				st.On("GetUserResults", mock.Anything, validTestId, 5).Return([]*domain.Result{
+					{Attempt: 1, Percentage: p.Int(90)},
+					{Attempt: 3, Percentage: p.Int(100)},
+				}, nil).Once()
+				st.On("UpdateResultScore", mock.Anything, mock.AnythingOfType(resultType)).Return(nil).Once()
+			},
+		},
+		{
+			name:      "not graded",
+			result:    newResult(false),
+			wantError: true,
+			err:       errors.New("not all answers are graded"),
+			mockF:     func(st *mocks.Storage) {},
+		},
+		{
+			name:      "already finalized concurrently",
+			result:    newResult(true),
+			wantError: true,
+			err:       errors.New("result is not pending review"),
+			mockF: func(st *mocks.Storage) {
+				st.On("GetUserResults", mock.Anything, validTestId, 5).Return([]*domain.Result{}, nil).Once()
+				st.On("UpdateResultScore", mock.Anything, mock.AnythingOfType(resultType)).Return(storage.ErrNotFound).Once()
+			},
+		},
+	}
+
+	for _, tt := range tc {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockVal := mocks.NewValidator(t)
+			mockSt := mocks.NewStorage(t)
+			mockSt.On("GetResultByID", mock.Anything, resultID).Return(tt.result, nil).Once()
+			mockSt.On("GetTestByID", mock.Anything, validTestId, false).Return(test, nil).Once()
+			tt.mockF(mockSt)
+
+			s := New(zap.NewExample(), &config.Config{}, mockSt, mockVal)
+			result, err := s.FinalizeResult(ctx, resultID)
+
+			if tt.wantError {
+				assert.Containsf(t, err.Error(), tt.err.Error(), "expected error containing %q, got %s", tt.err.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.False(t, result.Pending)
+				assert.Equal(t, 75, *result.Percentage)
+				// Later attempt is ignored, best of earlier and this one counts
+				assert.Equal(t, 90, *result.FinalPercentage)
+				assert.Equal(t, domain.QuestionResultPartial, (*result.Questions)[0].Status)
+			}
+		})
+	}
+}
+
+func TestService_TestLifecycle(t *testing.T) {
+	validTestId := "623452gsgsgf"
+	newTest := func(status string) *domain.Test {
+		return &domain.Test{ID: &validTestId, UserID: p.Int(1), Status: p.String(status), Revision: 5}
+	}
+	tc := []struct {
+		name      string
+		ctx       context.Context
+		test      *domain.Test
+		archive   bool
+		revision  *int
+		wantError bool
+		err       error
+		mockF     func(st *mocks.Storage)
+	}{
+		{
+			name: "publish draft",
+			ctx:  ctxWithUser(1, user.Creator),
+			test: newTest(domain.TestStatusDraft),
+			mockF: func(st *mocks.Storage) {
+				st.On("UpdateTestStatus", mock.Anything, validTestId, domain.TestStatusPublished, 5).Return(nil).Once()
+			},
+		},
+		{
+			name:      "publish with outdated revision",
+			ctx:       ctxWithUser(1, user.Creator),
+			test:      newTest(domain.TestStatusDraft),
+			revision:  p.Int(4),
+			wantError: true,
+			err:       errors.New("test was changed concurrently"),
+			mockF:     func(st *mocks.Storage) {},
+		},
+		{
+			name:      "publish changed between read and write",
+			ctx:       ctxWithUser(1, user.Creator),
+			test:      newTest(domain.TestStatusDraft),
+			revision:  p.Int(5),
+			wantError: true,
+			err:       errors.New("test was changed concurrently"),
+			mockF: func(st *mocks.Storage) {
+				st.On("UpdateTestStatus", mock.Anything, validTestId, domain.TestStatusPublished, 5).Return(storage.ErrConflict).Once()
+			},
+		},
+		{
+			name: "publish archived by admin",
+			ctx:  ctxWithUser(2, user.Admin),
+			test: newTest(domain.TestStatusArchived),
+			mockF: func(st *mocks.Storage) {
+				st.On("UpdateTestStatus", mock.Anything, validTestId, domain.TestStatusPublished, 5).Return(nil).Once()
+			},
+		},
+		{
+			name:    "archive published",
+			ctx:     ctxWithUser(1, user.Creator),
+			test:    newTest(domain.TestStatusPublished),
+			archive: true,
+			mockF: func(st *mocks.Storage) {
+				st.On("UpdateTestStatus", mock.Anything, validTestId, domain.TestStatusArchived, 5).Return(nil).Once()
+			},
+		},
+		{
+			name:      "publish published",
+			ctx:       ctxWithUser(1, user.Creator),
+			test:      newTest(domain.TestStatusPublished),
+			wantError: true,
+			err:       errors.New("invalid test status transition"),
+			mockF:     func(st *mocks.Storage) {},
+		},
+		{
+			name:      "archive draft",
+			ctx:       ctxWithUser(1, user.Creator),
+			test:      newTest(domain.TestStatusDraft),
+			archive:   true,
+			wantError: true,
+			err:       errors.New("invalid test status transition"),
+			mockF:     func(st *mocks.Storage) {},
+		},
+		{
+			name:      "archive test of other user",
+			ctx:       ctxWithUser(2, user.Moderator),
+			test:      newTest(domain.TestStatusPublished),
+			archive:   true,
+			wantError: true,
+			err:       errors.New("no rights to perform"),
+			mockF:     func(st *mocks.Storage) {},
+		},
+		{
+			name:      "publish draft of other user",
+			ctx:       ctxWithUser(2, user.Moderator),
+			test:      newTest(domain.TestStatusDraft),
+			wantError: true,
+			err:       errors.New("not found"),
+			mockF:     func(st *mocks.Storage) {},
+		},
+	}
+
+	for _, tt := range tc {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockVal := mocks.NewValidator(t)
+			mockSt := mocks.NewStorage(t)
+			mockSt.On("GetTestByID", mock.Anything, validTestId, false).Return(tt.test, nil).Once()
+			tt.mockF(mockSt)
+
+			s := New(zap.NewExample(), &config.Config{}, mockSt, mockVal)
+			var got error
+			if tt.archive {
+				got = s.ArchiveTest(tt.ctx, validTestId, tt.revision)
+			} else {
+				got = s.PublishTest(tt.ctx, validTestId, tt.revision)
+			}
+
+			if tt.wantError {
+				assert.Containsf(t, got.Error(), tt.err.Error(), "expected error containing %q, got %s", tt.err.Error(), got.Error())
+			} else {
+				assert.NoError(t, got)
+			}
+		})
+	}
+}
+
+func TestService_UpdateTest(t *testing.T) {
+	validTestId := "623452gsgsgf"
+	ctx := ctxWithUser(1, user.Creator)
+
+	tc := []struct {
+		name      string
+		patch     domain.MergePatch
+		revision  *int
+		wantError bool
+		err       error
+		mockF     func(st *mocks.Storage)
+	}{
+		{
+			name:  "set title and delete image",
+			patch: domain.MergePatch{"title": json.RawMessage(`"New title"`), "main_image": json.RawMessage(`null`)},
+			mockF: func(st *mocks.Storage) {
+				want := domain.MergePatch{
+					"title":      json.RawMessage(`"New title"`),
+					"main_image": json.RawMessage(`null`),
+					"version":    json.RawMessage(`4`),
+				}
+				st.On("CreateTestVersion", mock.Anything, mock.MatchedBy(func(v domain.TestVersion) bool {
+					return v.Version == 4 && v.Test.Revision == 6 && *v.Test.Title == "New title" &&
+						v.Test.MainImage == nil && reflect.DeepEqual(*v.Test.Tags, []string{"go"})
+				})).Return(nil).Once()
+				st.On("UpdateTest", mock.Anything, validTestId, want, 5).Return(nil).Once()
+			},
+		},
+		{
+			name:      "delete title",
+			patch:     domain.MergePatch{"title": json.RawMessage(`null`)},
+			wantError: true,
+			err:       errors.New("failed test validation"),
+			mockF:     func(st *mocks.Storage) {},
+		},
+		{
+			name:      "change not preview field",
+			patch:     domain.MergePatch{"questions": json.RawMessage(`[]`)},
+			wantError: true,
+			err:       errors.New("failed test validation"),
+			mockF:     func(st *mocks.Storage) {},
+		},
+		{
+			name:      "outdated revision",
+			patch:     domain.MergePatch{"tags": json.RawMessage(`["go"]`)},
+			revision:  p.Int(4),
+			wantError: true,
+			err:       errors.New("test was changed concurrently"),
+			mockF:     func(st *mocks.Storage) {},
+		},
+		{
+			name:      "changed between read and write",
+			patch:     domain.MergePatch{"tags": json.RawMessage(`["go"]`)},
+			wantError: true,
+			err:       errors.New("test was changed concurrently"),
+			mockF: func(st *mocks.Storage) {
+				st.On("CreateTestVersion", mock.Anything, mock.Anything).Return(nil).Once()
+				st.On("UpdateTest", mock.Anything, validTestId, mock.Anything, 5).Return(storage.ErrConflict).Once()
+				st.On("DeleteTestVersion", mock.Anything, validTestId, 4).Return(nil).Once()
+			},
+		},
+		{
+			name:      "version taken by concurrent write",
+			patch:     domain.MergePatch{"tags": json.RawMessage(`["go"]`)},
+			wantError: true,
+			err:       errors.New("test was changed concurrently"),
+			mockF: func(st *mocks.Storage) {
+				st.On("CreateTestVersion", mock.Anything, mock.Anything).Return(storage.ErrExists).Once()
+			},
+		},
+	}
+
+	for _, tt := range tc {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockVal := mocks.NewValidator(t)
+			mockSt := mocks.NewStorage(t)
+			mockSt.On("GetRawTestByID", mock.Anything, validTestId).Return(&domain.Test{
+				ID:        &validTestId,
+				UserID:    p.Int(1),
+				Version:   3,
+				Revision:  5,
+				Title:     p.String("Old title"),
+				MainImage: &domain.Image{Name: p.String("old.png")},
+				Tags:      &[]string{"go"},
+			}, nil).Once()
+			tt.mockF(mockSt)
+
+			s := New(zap.NewExample(), &config.Config{}, mockSt, mockVal)
+			got := s.UpdateTest(ctx, validTestId, tt.patch, tt.revision)
+
+			if tt.wantError {
+				assert.Containsf(t, got.Error(), tt.err.Error(), "expected error containing %q, got %s", tt.err.Error(), got.Error())
+			} else {
+				assert.NoError(t, got)
+			}
+		})
+	}
+}
+
+func TestService_TestVisibility(t *testing.T) {
+	validTestId := "623452gsgsgf"
+	draft := &domain.Test{ID: &validTestId, UserID: p.Int(1), Status: p.String(domain.TestStatusDraft)}
+	archived := &domain.Test{ID: &validTestId, UserID: p.Int(1), Status: p.String(domain.TestStatusArchived)}
+
+	t.Run("draft is hidden from other users", func(t *testing.T) {
+		t.Parallel()
+
+		mockSt := mocks.NewStorage(t)
+		mockSt.On("GetTestByID", mock.Anything, validTestId, false).Return(draft, nil).Times(4)
+		s := New(zap.NewExample(), &config.Config{}, mockSt, mocks.NewValidator(t))
+
+		_, err := s.GetTestByID(context.Background(), validTestId, false)
+		assert.ErrorIs(t, err, ErrNotFound)
+		_, err = s.GetTestByID(ctxWithUser(2, user.Moderator), validTestId, false)
+		assert.ErrorIs(t, err, ErrNotFound)
+		_, err = s.GetTestByID(ctxWithUser(1, user.Creator), validTestId, false)
+		assert.NoError(t, err)
+		_, err = s.GetTestByID(ctxWithUser(2, user.Admin), validTestId, false)
+		assert.NoError(t, err)
+	})
+
+	t.Run("answers are hidden from moderators", func(t *testing.T) {
+		t.Parallel()
+
+		published := &domain.Test{ID: &validTestId, UserID: p.Int(1), Status: p.String(domain.TestStatusPublished)}
+		mockSt := mocks.NewStorage(t)
+		mockSt.On("GetTestByID", mock.Anything, validTestId, true).Return(published, nil).Times(2)
+		s := New(zap.NewExample(), &config.Config{}, mockSt, mocks.NewValidator(t))
+
+		// Moderator permission id is higher than admin one, but it doesn't give admin rights
+		_, err := s.GetTestByID(ctxWithUser(2, user.Moderator), validTestId, true)
+		assert.ErrorIs(t, err, ErrNoRights)
+		_, err = s.GetTestByID(ctxWithUser(2, user.Admin), validTestId, true)
+		assert.NoError(t, err)
+	})
+
+	t.Run("lists hide drafts of other users", func(t *testing.T) {
+		t.Parallel()
+
+		mockSt := mocks.NewStorage(t)
+		mockSt.On("GetTests", mock.Anything, domain.TestsQuery{HideDrafts: true, ViewerID: p.Int(2)}).Return([]*domain.Test{}, int64(0), nil).Once()
+		mockSt.On("GetTests", mock.Anything, domain.TestsQuery{HideDrafts: true}).Return([]*domain.Test{}, int64(0), nil).Once()
+		mockSt.On("GetTests", mock.Anything, domain.TestsQuery{}).Return([]*domain.Test{}, int64(0), nil).Once()
+		s := New(zap.NewExample(), &config.Config{}, mockSt, mocks.NewValidator(t))
+
+		_, _, err := s.GetTests(ctxWithUser(2, user.Creator), domain.TestsQuery{})
+		assert.NoError(t, err)
+		_, _, err = s.GetTests(context.Background(), domain.TestsQuery{})
+		assert.NoError(t, err)
+		_, _, err = s.GetTests(ctxWithUser(3, user.Admin), domain.TestsQuery{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("archived test is viewable but not submittable", func(t *testing.T) {
+		t.Parallel()
+
+		mockSt := mocks.NewStorage(t)
+		mockSt.On("GetTestByID", mock.Anything, validTestId, false).Return(archived, nil).Once()
+		mockSt.On("GetTestByID", mock.Anything, validTestId, true).Return(archived, nil).Once()
+		s := New(zap.NewExample(), &config.Config{}, mockSt, mocks.NewValidator(t))
+
+		_, err := s.GetTestByID(ctxWithUser(2, user.Creator), validTestId, false)
+		assert.NoError(t, err)
+		_, err = s.ApplyTest(ctxWithUser(2, user.Creator), validTestId, 2, "", map[int]domain.UserAnswerModel{})
+		assert.ErrorIs(t, err, ErrTestNotPublished)
+	})
+}
+
+func TestService_EditQuestions(t *testing.T) {
+	validTestId := "623452gsgsgf"
+	testType := reflect.TypeOf(domain.Test{}).String()
+
+	newTest := func() *domain.Test {
+		return &domain.Test{
+			ID:       &validTestId,
+			UserID:   p.Int(1),
+			Type:     p.String(domain.TestTypeStrictTest),
+			Status:   p.String(domain.TestStatusPublished),
+			Version:  3,
+			Revision: 8,
+			Questions: &[]*domain.Question{
+				{ID: 1, ShortText: p.String("First")},
+				{ID: 2, ShortText: p.String("Second")},
+			},
+		}
+	}
+	questionIDs := func(test domain.Test) []int {
+		ids := make([]int, 0, len(*test.Questions))
+		for _, q := range *test.Questions {
+			ids = append(ids, q.ID)
+		}
+		return ids
+	}
+	tc := []struct {
+		name      string
+		ctx       context.Context
+		edit      func(s *Service, ctx context.Context) (*domain.Test, error)
+		wantIDs   []int
+		wantError bool
+		err       error
+		valF      func(val *mocks.Validator)
+		stF       func(st *mocks.Storage)
+	}{
+		{
+			name: "add question",
+			ctx:  ctxWithUser(1, user.Creator),
+			edit: func(s *Service, ctx context.Context) (*domain.Test, error) {
+				return s.AddQuestion(ctx, validTestId, domain.Question{ID: 3}, nil)
+			},
+			wantIDs: []int{1, 2, 3},
+		},
+		{
+			name: "add question with existing id",
+			ctx:  ctxWithUser(1, user.Creator),
+			edit: func(s *Service, ctx context.Context) (*domain.Test, error) {
+				return s.AddQuestion(ctx, validTestId, domain.Question{ID: 2}, nil)
+			},
+			wantError: true,
+			err:       errors.New("failed test validation"),
+		},
+		{
+			name: "update question by admin",
+			ctx:  ctxWithUser(2, user.Admin),
+			edit: func(s *Service, ctx context.Context) (*domain.Test, error) {
+				return s.UpdateQuestion(ctx, validTestId, domain.Question{ID: 2, ShortText: p.String("Fixed typo")}, nil)
+			},
+			wantIDs: []int{1, 2},
+		},
+		{
+			name: "update missing question",
+			ctx:  ctxWithUser(1, user.Creator),
+			edit: func(s *Service, ctx context.Context) (*domain.Test, error) {
+				return s.UpdateQuestion(ctx, validTestId, domain.Question{ID: 5}, nil)
+			},
+			wantError: true,
+			err:       errors.New("not found"),
+		},
+		{
+			name: "remove question",
+			ctx:  ctxWithUser(1, user.Creator),
+			edit: func(s *Service, ctx context.Context) (*domain.Test, error) {
+				return s.RemoveQuestion(ctx, validTestId, 1, nil)
+			},
+			wantIDs: []int{2},
+		},
+		{
+			name: "reorder questions",
+			ctx:  ctxWithUser(1, user.Creator),
+			edit: func(s *Service, ctx context.Context) (*domain.Test, error) {
+				return s.ReorderQuestions(ctx, validTestId, []int{2, 1}, nil)
+			},
+			wantIDs: []int{2, 1},
+		},
+		{
+			name: "reorder with missing question",
+			ctx:  ctxWithUser(1, user.Creator),
+			edit: func(s *Service, ctx context.Context) (*domain.Test, error) {
+				return s.ReorderQuestions(ctx, validTestId, []int{2, 2}, nil)
+			},
+			wantError: true,
+			err:       errors.New("failed test validation"),
+		},
+		{
+			name: "replace test keeps id, creator and status",
+			ctx:  ctxWithUser(1, user.Creator),
+			edit: func(s *Service, ctx context.Context) (*domain.Test, error) {
+				return s.ReplaceTest(ctx, validTestId, domain.Test{
+					UserID:    p.Int(7),
+					Type:      p.String(domain.TestTypeStrictTest),
+					Questions: &[]*domain.Question{{ID: 4}},
+				}, p.Int(8))
+			},
+			wantIDs: []int{4},
+		},
+		{
+			name: "outdated revision",
+			ctx:  ctxWithUser(1, user.Creator),
+			edit: func(s *Service, ctx context.Context) (*domain.Test, error) {
+				return s.RemoveQuestion(ctx, validTestId, 1, p.Int(7))
+			},
+			wantError: true,
+			err:       errors.New("test was changed concurrently"),
+		},
+		{
+			name: "edited test fails validation",
+			ctx:  ctxWithUser(1, user.Creator),
+			edit: func(s *Service, ctx context.Context) (*domain.Test, error) {
+				return s.RemoveQuestion(ctx, validTestId, 1, nil)
+			},
+			wantError: true,
+			err:       errors.New("failed test validation"),
+			valF: func(val *mocks.Validator) {
+				val.On("ValidateTest", mock.AnythingOfType(testType)).Return(errors.New("failed test validation")).Once()
+			},
+		},
+		{
+			name: "test changed after snapshot was saved",
+			ctx:  ctxWithUser(1, user.Creator),
+			edit: func(s *Service, ctx context.Context) (*domain.Test, error) {
+				return s.RemoveQuestion(ctx, validTestId, 1, nil)
+			},
+			wantError: true,
+			err:       errors.New("test was changed concurrently"),
+			valF: func(val *mocks.Validator) {
+				val.On("ValidateTest", mock.AnythingOfType(testType)).Return(nil).Once()
+			},
+			stF: func(st *mocks.Storage) {
+				st.On("CreateTestVersion", mock.Anything, mock.Anything).Return(nil).Once()
+				st.On("ReplaceTest", mock.Anything, mock.Anything, 8).Return(storage.ErrConflict).Once()
+				st.On("DeleteTestVersion", mock.Anything, validTestId, 4).Return(nil).Once()
+			},
+		},
+		{
+			name: "version taken by concurrent edit",
+			ctx:  ctxWithUser(1, user.Creator),
+			edit: func(s *Service, ctx context.Context) (*domain.Test, error) {
+				return s.RemoveQuestion(ctx, validTestId, 1, nil)
+			},
+			wantError: true,
+			err:       errors.New("test was changed concurrently"),
+			valF: func(val *mocks.Validator) {
+				val.On("ValidateTest", mock.AnythingOfType(testType)).Return(nil).Once()
+			},
+			stF: func(st *mocks.Storage) {
+				st.On("CreateTestVersion", mock.Anything, mock.Anything).Return(storage.ErrExists).Once()
+			},
+		},
+		{
+			name: "not a test creator",
+			ctx:  ctxWithUser(2, user.Moderator),
+			edit: func(s *Service, ctx context.Context) (*domain.Test, error) {
+				return s.RemoveQuestion(ctx, validTestId, 1, nil)
+			},
+			wantError: true,
+			err:       errors.New("no rights to perform"),
+		},
+	}
+
+	for _, tt := range tc {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockVal := mocks.NewValidator(t)
+			mockSt := mocks.NewStorage(t)
+			mockSt.On("GetRawTestByID", mock.Anything, validTestId).Return(newTest(), nil).Once()
+			if tt.stF != nil {
+				tt.stF(mockSt)
+			}
+			if tt.valF != nil {
+				tt.valF(mockVal)
+			} else if !tt.wantError {
+				mockVal.On("ValidateTest", mock.AnythingOfType(testType)).Return(nil).Once()
+				mockSt.On("ReplaceTest", mock.Anything, mock.MatchedBy(func(test domain.Test) bool {
+					return test.Version == 4 && test.Revision == 9 && reflect.DeepEqual(questionIDs(test), tt.wantIDs)
+				}), 8).Return(nil).Once()
+				mockSt.On("CreateTestVersion", mock.Anything, mock.MatchedBy(func(v domain.TestVersion) bool {
+					return v.TestID == validTestId && v.Version == 4 && reflect.DeepEqual(questionIDs(*v.Test), tt.wantIDs)
+				})).Return(nil).Once()
+			}
+
+			s := New(zap.NewExample(), &config.Config{}, mockSt, mockVal)
+			test, err := tt.edit(s, tt.ctx)
+
+			if tt.wantError {
+				assert.Containsf(t, err.Error(), tt.err.Error(), "expected error containing %q, got %s", tt.err.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, validTestId, *test.ID)
+				assert.Equal(t, 1, *test.UserID)
+				assert.Equal(t, domain.TestStatusPublished, *test.Status)
+			}
+		})
+	}
+}
+
+func TestService_UpdateQuestionBank(t *testing.T) {
+	bankID := "6fd7gdfg76"
+	otherBankID := "8gh6fgh5fg"
+	testID := "623452gsgsgf"
+	testType := reflect.TypeOf(domain.Test{}).String()
+	bank := &domain.QuestionBank{ID: &bankID, UserID: p.Int(1)}
+
+	newUpdate := func(questionIDs ...int) domain.QuestionBank {
+		questions := make([]*domain.Question, 0, len(questionIDs))
+		for _, id := range questionIDs {
+			questions = append(questions, &domain.Question{ID: id, Type: p.String(domain.QuestionTypeSingleChoice)})
+		}
+		return domain.QuestionBank{Title: p.String("Bank"), Questions: &questions}
+	}
+	usingTest := func() *domain.Test {
+		return &domain.Test{
+			ID:   &testID,
+			Type: p.String(domain.TestTypeStrictTest),
+			Questions: &[]*domain.Question{
+				{ID: 1, BankRef: &domain.BankRef{BankID: bankID, QuestionID: 2}},
+				{ID: 2, BankRef: &domain.BankRef{BankID: otherBankID, QuestionID: 1}},
+			},
+		}
+	}
+	otherBank := &domain.QuestionBank{ID: &otherBankID, Questions: &[]*domain.Question{{ID: 1}}}
+
+	tc := []struct {
+		name      string
+		update    domain.QuestionBank
+		wantError bool
+		err       error
+		mockF     func(st *mocks.Storage, val *mocks.Validator)
+	}{
+		{
+			name:   "ok",
+			update: newUpdate(1, 2),
+			mockF: func(st *mocks.Storage, val *mocks.Validator) {
+				st.On("GetTestsUsingBank", mock.Anything, bankID).Return([]*domain.Test{usingTest()}, nil).Once()
+				st.On("GetQuestionBanksByIDs", mock.Anything, []string{otherBankID}, true).Return([]*domain.QuestionBank{otherBank}, nil).Once()
+				val.On("ValidateTest", mock.MatchedBy(func(test domain.Test) bool {
+					return len(*test.Questions) == 2 && (*test.Questions)[0].Type != nil
+				})).Return(nil).Once()
+				st.On("UpdateQuestionBank", mock.Anything, bankID, mock.Anything).Return(nil).Once()
+			},
+		},
+		{
+			name:      "referenced question removed",
+			update:    newUpdate(1),
+			wantError: true,
+			err:       errors.New("question bank update breaks tests using it"),
+			mockF: func(st *mocks.Storage, val *mocks.Validator) {
+				st.On("GetTestsUsingBank", mock.Anything, bankID).Return([]*domain.Test{usingTest()}, nil).Once()
+				st.On("GetQuestionBanksByIDs", mock.Anything, []string{otherBankID}, true).Return([]*domain.QuestionBank{otherBank}, nil).Once()
+			},
+		},
+		{
+			name:      "test becomes invalid",
+			update:    newUpdate(1, 2),
+			wantError: true,
+			err:       errors.New("question bank update breaks tests using it"),
+			mockF: func(st *mocks.Storage, val *mocks.Validator) {
+				st.On("GetTestsUsingBank", mock.Anything, bankID).Return([]*domain.Test{usingTest()}, nil).Once()
+				st.On("GetQuestionBanksByIDs", mock.Anything, []string{otherBankID}, true).Return([]*domain.QuestionBank{otherBank}, nil).Once()
+				val.On("ValidateTest", mock.AnythingOfType(testType)).Return(errors.New("no points")).Once()
+			},
+		},
+		{
+			name:   "not used by tests",
+			update: newUpdate(1),
+			mockF: func(st *mocks.Storage, val *mocks.Validator) {
+				st.On("GetTestsUsingBank", mock.Anything, bankID).Return([]*domain.Test{}, nil).Once()
+				st.On("UpdateQuestionBank", mock.Anything, bankID, mock.Anything).Return(nil).Once()
+			},
+		},
+	}
+
+	for _, tt := range tc {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockVal := mocks.NewValidator(t)
+			mockSt := mocks.NewStorage(t)
+			mockSt.On("GetQuestionBankByID", mock.Anything, bankID, true).Return(bank, nil).Once()
+			mockVal.On("ValidateQuestionBank", mock.Anything).Return(nil).Once()
+			tt.mockF(mockSt, mockVal)
+
+			s := New(zap.NewExample(), &config.Config{}, mockSt, mockVal)
+			got := s.UpdateQuestionBank(ctxWithUser(1, user.Creator), bankID, tt.update)
+
+			if tt.wantError {
+				assert.Containsf(t, got.Error(), tt.err.Error(), "expected error containing %q, got %s", tt.err.Error(), got.Error())
+			} else {
+				assert.NoError(t, got)
+			}
+		})
+	}
+}
+
+func TestService_ApplyTest_QuestionWithoutPoints(t *testing.T) {
+	validTestId := "623452gsgsgf"
+	test := &domain.Test{
+		ID:     &validTestId,
+		UserID: p.Int(1),
+		Type:   p.String(domain.TestTypeStrictTest),
+		Questions: &[]*domain.Question{
+			{ID: 1, Type: p.String(domain.QuestionTypeSingleChoice), Points: p.Int(10), Answers: &domain.AnswerModel{CorrectID: p.Int(1)}},
+			{ID: 2, Type: p.String(domain.QuestionTypeSingleChoice)},
+		},
+	}
+
+	mockVal := mocks.NewValidator(t)
+	mockSt := mocks.NewStorage(t)
+	mockSt.On("GetTestByID", mock.Anything, validTestId, true).Return(test, nil).Once()
+	mockSt.On("GetUserResults", mock.Anything, validTestId, 1).Return([]*domain.Result{}, nil).Once()
+	mockVal.On("ValidateUserAnswers", mock.Anything, mock.Anything).Return(nil).Twice()
+	mockSt.On("SaveUserResult", mock.Anything, mock.Anything).Return(nil).Once()
+
+	s := New(zap.NewExample(), &config.Config{}, mockSt, mockVal)
+	result, err := s.ApplyTest(context.Background(), validTestId, 1, "", map[int]domain.UserAnswerModel{
+		1: {QuestionID: 1, ChosenID: p.Int(1)},
+		2: {QuestionID: 2, ChosenID: p.Int(1)},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 10, *result.MaxPoints)
+	assert.Equal(t, 100, *result.Percentage)
+	assert.Equal(t, domain.QuestionResultWrong, (*result.Questions)[1].Status)
+}
+
+func TestService_ApplyTest_BankRevisions(t *testing.T) {
+	validTestId := "623452gsgsgf"
+	bankID := "bank-1"
+	test := &domain.Test{
+		ID:     &validTestId,
+		UserID: p.Int(1),
+		Type:   p.String(domain.TestTypeStrictTest),
+		Questions: &[]*domain.Question{
+			{ID: 1, Points: p.Int(10), BankRef: &domain.BankRef{BankID: bankID, QuestionID: 7}},
+		},
+	}
+	// Bank was updated three times since creation
+	test.ExpandBankQuestions([]*domain.QuestionBank{{
+		ID:       &bankID,
+		Revision: 4,
+		Questions: &[]*domain.Question{
+			{ID: 7, Type: p.String(domain.QuestionTypeSingleChoice), Answers: &domain.AnswerModel{CorrectID: p.Int(1)}},
+		},
+	}})
+
+	mockVal := mocks.NewValidator(t)
+	mockSt := mocks.NewStorage(t)
+	mockSt.On("GetTestByID", mock.Anything, validTestId, true).Return(test, nil).Once()
+	mockSt.On("GetUserResults", mock.Anything, validTestId, 1).Return([]*domain.Result{}, nil).Once()
+	mockVal.On("ValidateUserAnswers", mock.Anything, mock.Anything).Return(nil).Once()
+	mockSt.On("SaveUserResult", mock.Anything, mock.MatchedBy(func(r domain.Result) bool {
+		return r.BankRevisions[bankID] == 4
+	})).Return(nil).Once()
+
+	s := New(zap.NewExample(), &config.Config{}, mockSt, mockVal)
+	result, err := s.ApplyTest(context.Background(), validTestId, 1, "", map[int]domain.UserAnswerModel{
+		1: {QuestionID: 1, ChosenID: p.Int(1)},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{bankID: 4}, result.BankRevisions)
+	assert.Len(t, result.BankQuestions, 1)
+}
+
+func TestService_SearchTests(t *testing.T) {
+	found := []*domain.Test{{ID: p.String("t1")}, {ID: p.String("t2")}}
+
+	tc := []struct {
+		name      string
+		ctx       context.Context
+		text      string
+		query     domain.TestsQuery
+		on        func(st *mocks.Storage)
+		wantTotal int64
+		wantError bool
+		err       error
+	}{
+		{
+			name:  "anonymous search hides drafts",
+			ctx:   context.Background(),
+			text:  "  golang basics ",
+			query: domain.TestsQuery{Page: 2, PerPage: 10},
+			on: func(st *mocks.Storage) {
+				st.On("SearchTests", mock.Anything, "golang basics", domain.TestsQuery{Page: 2, PerPage: 10, HideDrafts: true}).
+					Return(found, int64(12), nil).Once()
+			},
+			wantTotal: 12,
+		},
+		{
+			name:  "own drafts are searchable",
+			ctx:   ctxWithUser(2, user.Creator),
+			text:  "golang",
+			query: domain.TestsQuery{Page: 1, PerPage: 5},
+			on: func(st *mocks.Storage) {
+				st.On("SearchTests", mock.Anything, "golang", domain.TestsQuery{Page: 1, PerPage: 5, HideDrafts: true, ViewerID: p.Int(2)}).
+					Return(found, int64(2), nil).Once()
+			},
+			wantTotal: 2,
+		},
+		{
+			name:  "admin sees drafts",
+			ctx:   ctxWithUser(3, user.Admin),
+			text:  "golang",
+			query: domain.TestsQuery{Page: 1, PerPage: 5},
+			on: func(st *mocks.Storage) {
+				st.On("SearchTests", mock.Anything, "golang", domain.TestsQuery{Page: 1, PerPage: 5}).
+					Return(found, int64(2), nil).Once()
+			},
+			wantTotal: 2,
+		},
+		{
+			name:      "empty text",
+			ctx:       context.Background(),
+			text:      "   ",
+			on:        func(st *mocks.Storage) {},
+			wantError: true,
+			err:       ErrEmptySearchText,
+		},
+		{
+			name: "storage error",
+			ctx:  context.Background(),
+			text: "golang",
+			on: func(st *mocks.Storage) {
+				st.On("SearchTests", mock.Anything, "golang", mock.Anything).Return(nil, int64(0), errors.New("storage error")).Once()
+			},
+			wantError: true,
+			err:       errors.New("storage error"),
+		},
+	}
+
+	for _, tt := range tc {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockSt := mocks.NewStorage(t)
+			tt.on(mockSt)
+			s := New(zap.NewExample(), &config.Config{}, mockSt, mocks.NewValidator(t))
+
+			tests, total, err := s.SearchTests(tt.ctx, tt.text, tt.query)
+
+			if tt.wantError {
+				assert.Containsf(t, err.Error(), tt.err.Error(), "expected error containing %q, got %s", tt.err.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, found, tests)
+				assert.Equal(t, tt.wantTotal, total)
+			}
+		})
+	}
+}
+
+// ctxWithUser returns context of the authorized user with given permissions
+func ctxWithUser(id int, perms ...int) context.Context {
+	return context.WithValue(context.Background(), user.AuthInfoKey, user.Info{
+		ID:          id,
+		Permissions: perms,
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/coddmeistr/quizzify/backend/tests/internal/domain"
 	"github.com/coddmeistr/quizzify/backend/tests/pkg/slice"
 	"go.uber.org/zap"
+	"math"
 )
 
 type Validation struct {
@@ -30,6 +31,26 @@ func (val *Validation) ValidateTest(test domain.Test) error {
 	const op = "testsservice.validation.ValidateTest"
 	log := val.log.With(zap.String("op", op))
 
+	if !val.validateTimeLimit(test) {
+		log.Error("failed time limit validation")
+		return fmt.Errorf("%s: %w", op, ErrFailedTestValidation)
+	}
+
+	if !val.validateRetakePolicy(test) {
+		log.Error("failed retake policy validation")
+		return fmt.Errorf("%s: %w", op, ErrFailedTestValidation)
+	}
+
+	if !val.validateRandomization(test) {
+		log.Error("failed randomization validation")
+		return fmt.Errorf("%s: %w", op, ErrFailedTestValidation)
+	}
+
+	if !val.validateQuestionIDs(test) {
+		log.Error("failed question ids validation")
+		return fmt.Errorf("%s: %w", op, ErrFailedTestValidation)
+	}
+
 	validated := false
 	switch *test.Type {
 	// Form that is to gather information from one person (or group of people)
@@ -64,6 +85,42 @@ func (val *Validation) ValidateTest(test domain.Test) error {
 	return nil
 }
 
+// ValidateQuestionBank checks that every bank question is complete and has unique id within the bank
+// Answers are optional, because the same bank question can be used in tests of any type
+func (val *Validation) ValidateQuestionBank(bank domain.QuestionBank) error {
+	const op = "testsservice.validation.ValidateQuestionBank"
+	log := val.log.With(zap.String("op", op))
+
+	if bank.Questions == nil || len(*bank.Questions) == 0 {
+		log.Error("no questions in bank")
+		return fmt.Errorf("%s: %w", op, ErrFailedTestValidation)
+	}
+
+	met := make(map[int]struct{})
+	for _, q := range *bank.Questions {
+		if q.BankRef != nil {
+			log.Error("bank question references other bank", zap.Int("question_id", q.ID))
+			return fmt.Errorf("%s: %w", op, ErrFailedTestValidation)
+		}
+		if q.Type == nil || q.Variants == nil {
+			log.Error("incomplete bank question", zap.Int("question_id", q.ID))
+			return fmt.Errorf("%s: %w", op, ErrFailedTestValidation)
+		}
+		if _, ok := met[q.ID]; ok {
+			log.Error("repeated question id", zap.Int("question_id", q.ID))
+			return fmt.Errorf("%s: %w", op, ErrFailedTestValidation)
+		}
+		met[q.ID] = struct{}{}
+
+		if !val.validateQuestion(*q, q.Answers != nil) {
+			log.Error("failed to validate bank question", zap.Int("question_id", q.ID))
+			return fmt.Errorf("%s: %w", op, ErrFailedTestValidation)
+		}
+	}
+
+	return nil
+}
+
 func (val *Validation) ValidateUserAnswers(q domain.Question, a domain.UserAnswerModel) error {
 	const op = "testsservice.validation.ValidateUserAnswers"
 	log := val.log.With(zap.String("op", op), zap.String("qtype", *q.Type))
@@ -105,6 +162,38 @@ func (val *Validation) ValidateUserAnswers(q domain.Question, a domain.UserAnswe
 			log.Error("no writed text")
 			return fmt.Errorf("%s: %w", op, ErrFailedUserAnswersValidation)
 		}
+	case domain.QuestionTypeOrdering:
+		if a.OrderedIDs == nil {
+			log.Error("no ordered ids")
+			return fmt.Errorf("%s: %w", op, ErrFailedUserAnswersValidation)
+		}
+		if !isPermutation(*a.OrderedIDs, *q.Variants.Ordering.Fields) {
+			log.Error("ordered ids don't match ordering fields")
+			return fmt.Errorf("%s: %w", op, ErrFailedUserAnswersValidation)
+		}
+	case domain.QuestionTypeMatching:
+		if a.Pairs == nil {
+			log.Error("no pairs")
+			return fmt.Errorf("%s: %w", op, ErrFailedUserAnswersValidation)
+		}
+		if !validatePairs(*a.Pairs, *q.Variants.Matching) {
+			log.Error("pairs don't match matching fields")
+			return fmt.Errorf("%s: %w", op, ErrFailedUserAnswersValidation)
+		}
+	case domain.QuestionTypeNumeric:
+		if a.Number == nil {
+			log.Error("no number")
+			return fmt.Errorf("%s: %w", op, ErrFailedUserAnswersValidation)
+		}
+		if math.IsNaN(*a.Number) || math.IsInf(*a.Number, 0) {
+			log.Error("number is not finite")
+			return fmt.Errorf("%s: %w", op, ErrFailedUserAnswersValidation)
+		}
+	case domain.QuestionTypeTrueFalse:
+		if a.ChosenBool == nil {
+			log.Error("no chosen bool")
+			return fmt.Errorf("%s: %w", op, ErrFailedUserAnswersValidation)
+		}
 	default:
 		log.Error("invalid question type")
 		return fmt.Errorf("%s: %w", op, ErrFailedUserAnswersValidation)
@@ -113,6 +202,99 @@ func (val *Validation) ValidateUserAnswers(q domain.Question, a domain.UserAnswe
 	return nil
 }
 
+func (val *Validation) validateTimeLimit(test domain.Test) bool {
+	const op = "testsservice.validation.validateTimeLimit"
+	log := val.log.With(zap.String("op", op))
+
+	if test.TimeLimit != nil && *test.TimeLimit <= 0 {
+		log.Error("time limit is not positive")
+		return false
+	}
+
+	if test.LatePenalty != nil {
+		if test.TimeLimit == nil {
+			log.Error("late penalty without time limit")
+			return false
+		}
+		if *test.LatePenalty < 0 || *test.LatePenalty > 100 {
+			log.Error("late penalty is out of percents range")
+			return false
+		}
+	}
+
+	return true
+}
+
+func (val *Validation) validateRetakePolicy(test domain.Test) bool {
+	const op = "testsservice.validation.validateRetakePolicy"
+	log := val.log.With(zap.String("op", op))
+
+	if test.MaxAttempts != nil && *test.MaxAttempts <= 0 {
+		log.Error("max attempts is not positive")
+		return false
+	}
+
+	if test.RetakeCooldown != nil && *test.RetakeCooldown < 0 {
+		log.Error("retake cooldown is negative")
+		return false
+	}
+
+	if test.ScoreMode != nil {
+		switch *test.ScoreMode {
+		case domain.ScoreModeBest, domain.ScoreModeLast, domain.ScoreModeAverage:
+		default:
+			log.Error("unknown score mode", zap.String("mode", *test.ScoreMode))
+			return false
+		}
+		if *test.Type != domain.TestTypeStrictTest {
+			log.Error("score mode is supported only for strict test")
+			return false
+		}
+	}
+
+	return true
+}
+
+func (val *Validation) validateRandomization(test domain.Test) bool {
+	const op = "testsservice.validation.validateRandomization"
+	log := val.log.With(zap.String("op", op))
+
+	if test.QuestionsPool != nil {
+		if *test.QuestionsPool <= 0 {
+			log.Error("questions pool is not positive")
+			return false
+		}
+		if test.Questions == nil || *test.QuestionsPool > len(*test.Questions) {
+			log.Error("questions pool is bigger than number of questions")
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateQuestionIDs checks that questions have unique ids within the test
+// Answers and results refer to questions by id, so ids can't repeat
+func (val *Validation) validateQuestionIDs(test domain.Test) bool {
+	const op = "testsservice.validation.validateQuestionIDs"
+	log := val.log.With(zap.String("op", op))
+
+	if test.Questions == nil {
+		return true
+	}
+
+	met := make(map[int]struct{}, len(*test.Questions))
+	for _, q := range *test.Questions {
+		if _, ok := met[q.ID]; ok {
+			log.Error("repeated question id", zap.Int("question_id", q.ID))
+			return false
+		}
+		met[q.ID] = struct{}{}
+	}
+
+	return true
+}
+
 func (val *Validation) validateForm(test domain.Test) bool {
 	for _, q := range *test.Questions {
 		if !val.validateQuestion(*q, false) {
@@ -134,8 +316,33 @@ func (val *Validation) validateQuiz(test domain.Test) bool {
 }
 
 func (val *Validation) validateTest(test domain.Test) bool {
+	const op = "testsservice.validation.validateTest"
+	log := val.log.With(zap.String("op", op))
+
+	if test.Params == nil || len(*test.Params) == 0 {
+		log.Error("no declared params")
+		return false
+	}
+	if slice.ContainsRepeated(*test.Params) {
+		log.Error("repeated names in declared params")
+		return false
+	}
+	if test.Outcomes == nil || len(*test.Outcomes) == 0 {
+		log.Error("no outcomes")
+		return false
+	}
+	if !val.validateOutcomes(*test.Outcomes, *test.Params) {
+		log.Error("failed to validate outcomes")
+		return false
+	}
+
+	// Questions of the test type contain flex params instead of correct answers
 	for _, q := range *test.Questions {
-		if !val.validateQuestion(*q, true) {
+		if !val.validateQuestion(*q, false) {
+			return false
+		}
+		if !val.validateFlexParams(*q, *test.Params) {
+			log.Error("failed to validate flex params", zap.Int("question_id", q.ID))
 			return false
 		}
 	}
@@ -164,6 +371,24 @@ func (val *Validation) validateQuestion(q domain.Question, checkAnswers bool) bo
 	const op = "testsservice.validation.validateQuestion"
 	log := val.log.With(zap.String("op", op), zap.String("qtype", *q.Type))
 
+	if q.NeedsReview && *q.Type != domain.QuestionTypeManualInput {
+		log.Error("manual grading is supported only for manual input")
+		return false
+	}
+
+	if q.Scoring != nil {
+		if *q.Type != domain.QuestionTypeMultipleChoice {
+			log.Error("scoring strategy is supported only for multiple choice")
+			return false
+		}
+		switch *q.Scoring {
+		case domain.ScoringAllOrNothing, domain.ScoringProportional, domain.ScoringNegative:
+		default:
+			log.Error("unknown scoring strategy", zap.String("scoring", *q.Scoring))
+			return false
+		}
+	}
+
 	switch *q.Type {
 	case domain.QuestionTypeSingleChoice:
 		var (
@@ -265,14 +490,99 @@ func (val *Validation) validateQuestion(q domain.Question, checkAnswers bool) bo
 			}
 		}
 	case domain.QuestionTypeManualInput:
+		// Answers graded by reviewer don't need correct text
+		if checkAnswers && !q.NeedsReview {
+			var a = q.Answers
+			if !val.validateAnswers(a) {
+				log.Error("coulnd't validate answers")
+				return false
+			}
+			if a.CorrectText == nil && (a.AlternativeTexts == nil || len(*a.AlternativeTexts) == 0) && (a.TextMatching == nil || a.TextMatching.Pattern == nil) {
+				log.Error("no required CorrectText, AlternativeTexts or Pattern for manualInput model")
+				return false
+			}
+		}
+	case domain.QuestionTypeOrdering:
+		if q.Variants == nil || q.Variants.Ordering == nil || q.Variants.Ordering.Fields == nil {
+			log.Error("no ordering structure")
+			return false
+		}
+		v := q.Variants.Ordering
+		if len(*v.Fields) < 2 {
+			log.Error("less than two items to order")
+			return false
+		}
+		if !val.validateFields(*v.Fields) {
+			log.Error("failed to validate fields")
+			return false
+		}
+
 		if checkAnswers {
 			var a = q.Answers
 			if !val.validateAnswers(a) {
 				log.Error("coulnd't validate answers")
 				return false
 			}
-			if a.CorrectText == nil {
-				log.Error("no required CorrectText for manualInput model")
+			if a.CorrectOrder == nil {
+				log.Error("no required CorrectOrder for ordering model")
+				return false
+			}
+			if !isPermutation(*a.CorrectOrder, *v.Fields) {
+				log.Error("CorrectOrder must contain each FieldID exactly once")
+				return false
+			}
+		}
+	case domain.QuestionTypeMatching:
+		if q.Variants == nil || q.Variants.Matching == nil {
+			log.Error("no matching structure")
+			return false
+		}
+		v := q.Variants.Matching
+		if v.Left == nil || v.Right == nil || len(*v.Left) == 0 || len(*v.Right) < len(*v.Left) {
+			log.Error("right column must contain at least as many items as left")
+			return false
+		}
+		if !val.validateFields(*v.Left) || !val.validateFields(*v.Right) {
+			log.Error("failed to validate fields")
+			return false
+		}
+
+		if checkAnswers {
+			var a = q.Answers
+			if !val.validateAnswers(a) {
+				log.Error("coulnd't validate answers")
+				return false
+			}
+			if a.CorrectPairs == nil {
+				log.Error("no required CorrectPairs for matching model")
+				return false
+			}
+			if len(*a.CorrectPairs) != len(*v.Left) || !validatePairs(*a.CorrectPairs, *v) {
+				log.Error("CorrectPairs must match each left item exactly once")
+				return false
+			}
+		}
+	case domain.QuestionTypeNumeric:
+		if checkAnswers {
+			var a = q.Answers
+			if !val.validateAnswers(a) {
+				log.Error("coulnd't validate answers")
+				return false
+			}
+			if a.CorrectNumber == nil {
+				log.Error("no required CorrectNumber for numeric model")
+				return false
+			}
+		}
+	case domain.QuestionTypeTrueFalse:
+		if checkAnswers {
+			var a = q.Answers
+			if !val.validateAnswers(a) {
+				log.Error("coulnd't validate answers")
+				return false
+			}
+			if a.CorrectBool == nil {
+				log.Error("no required CorrectBool for true/false model")
 				return false
 			}
 		}
@@ -342,5 +652,188 @@ func (val *Validation) validateAnswers(a *domain.AnswerModel) bool {
 		return false
 	}
 
+	if a.AlternativeTexts != nil {
+		for _, v := range *a.AlternativeTexts {
+			if v == "" {
+				log.Error("empty string in AlternativeTexts")
+				return false
+			}
+		}
+	}
+
+	if a.TextMatching != nil {
+		if a.TextMatching.MaxDistance != nil && *a.TextMatching.MaxDistance < 0 {
+			log.Error("MaxDistance is negative")
+			return false
+		}
+		// Distance as long as the accepted text would accept any text of that length
+		if shortest := a.ShortestAcceptedTextLen(); a.TextMatching.MaxDistance != nil && shortest > 0 && *a.TextMatching.MaxDistance >= shortest {
+			log.Error("MaxDistance is not less than length of the shortest accepted text")
+			return false
+		}
+		if err := a.TextMatching.Compile(); err != nil {
+			log.Error("invalid Pattern", zap.Error(err))
+			return false
+		}
+	}
+
+	if a.CorrectNumber != nil && (math.IsNaN(*a.CorrectNumber) || math.IsInf(*a.CorrectNumber, 0)) {
+		log.Error("CorrectNumber is not finite")
+		return false
+	}
+
+	if a.Tolerance != nil && (math.IsNaN(*a.Tolerance) || *a.Tolerance < 0) {
+		log.Error("Tolerance is negative")
+		return false
+	}
+
+	return true
+}
+
+func (val *Validation) validateFlexParams(q domain.Question, params []string) bool {
+	const op = "testsservice.validation.validateFlexParams"
+	log := val.log.With(zap.String("op", op), zap.String("qtype", *q.Type))
+
+	if q.Answers == nil || q.Answers.FlexParams == nil {
+		return true
+	}
+
+	var fields *[]*domain.CommonField
+	switch *q.Type {
+	case domain.QuestionTypeSingleChoice:
+		fields = q.Variants.SingleChoice.Fields
+	case domain.QuestionTypeMultipleChoice:
+		fields = q.Variants.MultipleChoice.Fields
+	default:
+		log.Error("flex params are not supported for question type")
+		return false
+	}
+
+	fieldIDs := make(map[int]struct{})
+	for _, f := range *fields {
+		fieldIDs[f.FieldID] = struct{}{}
+	}
+
+	met := make(map[int]struct{})
+	for _, fp := range *q.Answers.FlexParams {
+		if fp == nil || fp.Params == nil {
+			log.Error("flex params model is incomplete")
+			return false
+		}
+		if _, ok := fieldIDs[fp.FieldID]; !ok {
+			log.Error("FieldID in flex params doesn't pointing to some of the FieldID in fields slice", zap.Int("field_id", fp.FieldID))
+			return false
+		}
+		if _, ok := met[fp.FieldID]; ok {
+			log.Error("repeated FieldID in flex params")
+			return false
+		}
+		met[fp.FieldID] = struct{}{}
+
+		for _, v := range *fp.Params {
+			if v == nil || v.ParamName == nil || v.Effect == nil {
+				log.Error("flex param is incomplete")
+				return false
+			}
+			if !slice.Contains(params, *v.ParamName) {
+				log.Error("flex param is not in declared params", zap.String("param", *v.ParamName))
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (val *Validation) validateOutcomes(outcomes []*domain.Outcome, params []string) bool {
+	const op = "testsservice.validation.validateOutcomes"
+	log := val.log.With(zap.String("op", op))
+
+	met := make(map[int]struct{})
+	for _, o := range outcomes {
+		if o == nil {
+			log.Error("outcome is null")
+			return false
+		}
+		if o.ID < 0 {
+			log.Error("outcome ID is less then zero")
+			return false
+		}
+		if _, ok := met[o.ID]; ok {
+			log.Error("repeated ID in outcomes array")
+			return false
+		}
+		met[o.ID] = struct{}{}
+		if o.Title == nil || *o.Title == "" {
+			log.Error("no outcome title")
+			return false
+		}
+		if o.Conditions == nil {
+			continue
+		}
+		for _, c := range *o.Conditions {
+			if c == nil || c.ParamName == nil || c.Operator == nil || c.Value == nil {
+				log.Error("outcome condition is incomplete")
+				return false
+			}
+			if !slice.Contains(params, *c.ParamName) {
+				log.Error("outcome condition uses undeclared param", zap.String("param", *c.ParamName))
+				return false
+			}
+			switch *c.Operator {
+			case domain.OutcomeOperatorLess, domain.OutcomeOperatorLessEqual,
+				domain.OutcomeOperatorGreater, domain.OutcomeOperatorGreaterEqual,
+				domain.OutcomeOperatorEqual:
+			default:
+				log.Error("unknown outcome condition operator", zap.String("operator", *c.Operator))
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isPermutation checks that ids contain each FieldID of fields exactly once
+func isPermutation(ids []int, fields []*domain.CommonField) bool {
+	if len(ids) != len(fields) || slice.ContainsRepeated(ids) {
+		return false
+	}
+	for _, f := range fields {
+		if !slice.Contains(ids, f.FieldID) {
+			return false
+		}
+	}
+	return true
+}
+
+// validatePairs checks that pairs link existing left and right fields and each left field is used once at most
+func validatePairs(pairs []*domain.MatchPair, m domain.Matching) bool {
+	left := make(map[int]struct{}, len(*m.Left))
+	for _, f := range *m.Left {
+		left[f.FieldID] = struct{}{}
+	}
+	right := make(map[int]struct{}, len(*m.Right))
+	for _, f := range *m.Right {
+		right[f.FieldID] = struct{}{}
+	}
+
+	used := make(map[int]struct{}, len(pairs))
+	for _, p := range pairs {
+		if p == nil {
+			return false
+		}
+		if _, ok := left[p.LeftID]; !ok {
+			return false
+		}
+		if _, ok := right[p.RightID]; !ok {
+			return false
+		}
+		if _, ok := used[p.LeftID]; ok {
+			return false
+		}
+		used[p.LeftID] = struct{}{}
+	}
+
 	return true
 }
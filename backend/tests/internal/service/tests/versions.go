@@ -0,0 +1,265 @@
+package testsservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/coddmeistr/quizzify/backend/tests/internal/domain"
+	"github.com/coddmeistr/quizzify/backend/tests/internal/helpers/user"
+	"github.com/coddmeistr/quizzify/backend/tests/internal/storage"
+	"go.uber.org/zap"
+	"time"
+)
+
+// ReplaceTest replaces whole content of the test, saving it as a new version
+// Id, creator and status are kept from the stored test
+func (s *Service) ReplaceTest(ctx context.Context, testID string, test domain.Test, revision *int) (*domain.Test, error) {
+	const op = "service.testsservice.ReplaceTest"
+	log := s.log.With(zap.String("op", op))
+	log.Info("replacing test")
+
+	updated, err := s.editTest(ctx, testID, revision, func(stored *domain.Test) error {
+		test.ID = stored.ID
+		test.UserID = stored.UserID
+		test.Status = stored.Status
+		test.Version = stored.Version
+		test.Revision = stored.Revision
+		*stored = test
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to replace test", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("test was replaced successfully")
+	return updated, nil
+}
+
+// AddQuestion appends question to the end of the test
+func (s *Service) AddQuestion(ctx context.Context, testID string, question domain.Question, revision *int) (*domain.Test, error) {
+	const op = "service.testsservice.AddQuestion"
+	log := s.log.With(zap.String("op", op))
+	log.Info("adding question")
+
+	updated, err := s.editTest(ctx, testID, revision, func(test *domain.Test) error {
+		if !test.AddQuestion(&question) {
+			return ErrFailedTestValidation
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to add question", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("question was added successfully")
+	return updated, nil
+}
+
+// UpdateQuestion replaces question of the test with the same id
+func (s *Service) UpdateQuestion(ctx context.Context, testID string, question domain.Question, revision *int) (*domain.Test, error) {
+	const op = "service.testsservice.UpdateQuestion"
+	log := s.log.With(zap.String("op", op))
+	log.Info("updating question")
+
+	updated, err := s.editTest(ctx, testID, revision, func(test *domain.Test) error {
+		if !test.ReplaceQuestion(&question) {
+			return ErrNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to update question", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("question was updated successfully")
+	return updated, nil
+}
+
+func (s *Service) RemoveQuestion(ctx context.Context, testID string, questionID int, revision *int) (*domain.Test, error) {
+	const op = "service.testsservice.RemoveQuestion"
+	log := s.log.With(zap.String("op", op))
+	log.Info("removing question")
+
+	updated, err := s.editTest(ctx, testID, revision, func(test *domain.Test) error {
+		if !test.RemoveQuestion(questionID) {
+			return ErrNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to remove question", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("question was removed successfully")
+	return updated, nil
+}
+
+// ReorderQuestions puts questions of the test in the given order
+// Ids must contain every question of the test exactly once
+func (s *Service) ReorderQuestions(ctx context.Context, testID string, questionIDs []int, revision *int) (*domain.Test, error) {
+	const op = "service.testsservice.ReorderQuestions"
+	log := s.log.With(zap.String("op", op))
+	log.Info("reordering questions")
+
+	updated, err := s.editTest(ctx, testID, revision, func(test *domain.Test) error {
+		if !test.ReorderQuestions(questionIDs) {
+			return ErrFailedTestValidation
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to reorder questions", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("questions were reordered successfully")
+	return updated, nil
+}
+
+// GetTestVersions returns history of the test changes, allowed for test creator and admins
+// Snapshots are not included, use GetTestVersion to get the test as it was
+func (s *Service) GetTestVersions(ctx context.Context, testID string) ([]*domain.TestVersion, error) {
+	const op = "service.testsservice.GetTestVersions"
+	log := s.log.With(zap.String("op", op))
+	log.Info("getting test versions")
+
+	if _, _, err := s.getEditableTest(ctx, testID); err != nil {
+		log.Error("failed to check access to versions", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	versions, err := s.storage.GetTestVersions(ctx, testID)
+	if err != nil {
+		log.Error("failed to get test versions", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("test versions were gotten successfully")
+	return versions, nil
+}
+
+// GetTestVersion returns snapshot of the test version, allowed for test creator and admins
+func (s *Service) GetTestVersion(ctx context.Context, testID string, version int) (*domain.TestVersion, error) {
+	const op = "service.testsservice.GetTestVersion"
+	log := s.log.With(zap.String("op", op))
+	log.Info("getting test version")
+
+	if _, _, err := s.getEditableTest(ctx, testID); err != nil {
+		log.Error("failed to check access to versions", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	v, err := s.storage.GetTestVersion(ctx, testID, version)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("test version not found")
+			return nil, fmt.Errorf("%s: %w", op, ErrNotFound)
+		}
+		log.Error("failed to get test version", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("test version was gotten successfully")
+	return v, nil
+}
+
+// editTest applies edit to the stored test, validates the result and saves it as a new version
+// Editing is allowed for test creator and admins
+// If revision is not nil, test is edited only if it wasn't changed since this revision
+func (s *Service) editTest(ctx context.Context, testID string, revision *int, edit func(test *domain.Test) error) (*domain.Test, error) {
+	test, authUser, err := s.getEditableTest(ctx, testID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRevision(test, revision); err != nil {
+		return nil, err
+	}
+	previous := test.Revision
+
+	if err := edit(test); err != nil {
+		return nil, err
+	}
+
+	// Test is validated with bank questions expanded, but stored with references only
+	expanded := *test
+	if err := s.expandBankQuestions(ctx, &expanded); err != nil {
+		return nil, err
+	}
+	if err := s.validation.ValidateTest(expanded); err != nil {
+		return nil, ErrFailedTestValidation
+	}
+
+	test.Version++
+	test.Revision++
+	err = s.saveVersion(ctx, test, authUser.ID, func() error {
+		return s.storage.ReplaceTest(ctx, *test, previous)
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		if errors.Is(err, storage.ErrConflict) {
+			return nil, ErrConflict
+		}
+		return nil, err
+	}
+
+	return test, nil
+}
+
+// saveVersion saves snapshot of the new test version and then writes the test with given function
+// Snapshot goes first, so every version the test is written with has its snapshot
+// Version numbers are unique, so concurrent write of the same version gets ErrConflict
+// If write fails, snapshot is removed, so the version number can be taken again
+func (s *Service) saveVersion(ctx context.Context, test *domain.Test, createdBy int, write func() error) error {
+	if err := s.storage.CreateTestVersion(ctx, test.NewVersion(createdBy, time.Now().UTC())); err != nil {
+		if errors.Is(err, storage.ErrExists) {
+			return ErrConflict
+		}
+		return err
+	}
+
+	if err := write(); err != nil {
+		if delErr := s.storage.DeleteTestVersion(ctx, *test.ID, test.Version); delErr != nil {
+			s.log.Error("failed to delete snapshot of not written test version", zap.Error(delErr))
+		}
+		return err
+	}
+
+	return nil
+}
+
+// checkRevision returns ErrConflict if the test was changed since the revision known to the client
+// Nil revision means that client doesn't care about concurrent changes
+func checkRevision(test *domain.Test, revision *int) error {
+	if revision != nil && *revision != test.Revision {
+		return ErrConflict
+	}
+	return nil
+}
+
+// getEditableTest returns stored test if the authorized user can edit it
+func (s *Service) getEditableTest(ctx context.Context, testID string) (*domain.Test, user.Info, error) {
+	authUser, _ := user.AuthUserFromContext(ctx)
+
+	test, err := s.storage.GetRawTestByID(ctx, testID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, authUser, ErrNotFound
+		}
+		return nil, authUser, err
+	}
+
+	if authUser.ID == 0 || (authUser.ID != *test.UserID && !authUser.HasAny(user.Admin)) {
+		if !canSeeTest(ctx, test) {
+			return nil, authUser, ErrNotFound
+		}
+		return nil, authUser, ErrNoRights
+	}
+
+	return test, authUser, nil
+}
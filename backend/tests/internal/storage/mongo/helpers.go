@@ -1,9 +1,91 @@
 package mongo
 
-import "go.mongodb.org/mongo-driver/bson"
+import (
+	"github.com/coddmeistr/quizzify/backend/tests/internal/domain"
+	"github.com/coddmeistr/quizzify/backend/tests/pkg/api/sort"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"regexp"
+)
 
 // getProjectionForAnswers return projection for all existing bson answer fields in domain entities
 // It used to get quick bson to exclude all answer fields from bson document
 func getProjectionForAnswers() bson.D {
-	return bson.D{{"questions.answers", 0}}
+	return bson.D{{"questions.answers", 0}, {"outcomes.conditions", 0}}
+}
+
+// getProjectionForTextScore return projection of the text search relevance score
+// Same document is used to sort found documents by relevance
+func getProjectionForTextScore() bson.D {
+	return bson.D{{"score", bson.D{{"$meta", "textScore"}}}}
+}
+
+// getTestsFilter builds filter document from all non-empty filters of the query
+func getTestsFilter(query domain.TestsQuery) bson.D {
+	filter := bson.D{}
+	if query.Type != nil {
+		filter = append(filter, bson.E{Key: "type", Value: *query.Type})
+	}
+	if len(query.Tags) > 0 {
+		filter = append(filter, bson.E{Key: "tags", Value: bson.D{{"$all", query.Tags}}})
+	}
+	if query.CreatorID != nil {
+		filter = append(filter, bson.E{Key: "creator_id", Value: *query.CreatorID})
+	}
+	if query.Title != nil && *query.Title != "" {
+		filter = append(filter, bson.E{Key: "title", Value: primitive.Regex{Pattern: regexp.QuoteMeta(*query.Title), Options: "i"}})
+	}
+	if query.Status != nil {
+		filter = append(filter, bson.E{Key: "status", Value: *query.Status})
+	}
+	if query.HideDrafts {
+		visible := bson.A{bson.D{{"status", bson.D{{"$ne", domain.TestStatusDraft}}}}}
+		if query.ViewerID != nil {
+			visible = append(visible, bson.D{{"creator_id", *query.ViewerID}})
+		}
+		filter = append(filter, bson.E{Key: "$or", Value: visible})
+	}
+	return filter
+}
+
+// getSearchTestsFilter builds full-text search filter narrowed by all non-empty filters of the query
+func getSearchTestsFilter(text string, query domain.TestsQuery) bson.D {
+	return append(bson.D{{"$text", bson.D{{"$search", text}}}}, getTestsFilter(query)...)
+}
+
+// getResultsFilter builds filter document from all non-empty filters of the query
+func getResultsFilter(query domain.ResultsQuery) bson.D {
+	filter := bson.D{}
+	if query.TestID != nil {
+		filter = append(filter, bson.E{Key: "test_id", Value: *query.TestID})
+	}
+	if query.UserID != nil {
+		filter = append(filter, bson.E{Key: "user_id", Value: *query.UserID})
+	}
+	if query.Pending != nil {
+		filter = append(filter, bson.E{Key: "pending", Value: *query.Pending})
+	}
+	return filter
+}
+
+// getResultIDFilter returns filter of the result by its id
+// Results saved before the service generated ids have ObjectID ids, they are addressed by its hex
+func getResultIDFilter(resultID string) bson.D {
+	if oid, err := primitive.ObjectIDFromHex(resultID); err == nil {
+		return bson.D{{"_id", bson.D{{"$in", bson.A{resultID, oid}}}}}
+	}
+	return bson.D{{"_id", resultID}}
+}
+
+// getSort returns sort document for the field in given order
+// Field "id" is mapped to the mongo "_id" field, other fields have the same names in bson
+func getSort(field string, order string) bson.D {
+	if field == "id" {
+		field = "_id"
+	}
+	direction := 1
+	if order == sort.DESC {
+		direction = -1
+	}
+	return bson.D{{field, direction}}
 }
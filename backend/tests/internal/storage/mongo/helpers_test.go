@@ -0,0 +1,153 @@
+package mongo
+
+import (
+	"github.com/coddmeistr/quizzify/backend/tests/internal/domain"
+	"github.com/coddmeistr/quizzify/backend/tests/pkg/api/sort"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+func TestGetTestsFilter(t *testing.T) {
+	testType := domain.TestTypeQuiz
+	creatorID := 7
+	viewerID := 3
+	title := "go (basics)"
+	status := domain.TestStatusPublished
+
+	tests := []struct {
+		name  string
+		query domain.TestsQuery
+		want  bson.D
+	}{
+		{
+			name:  "no filters",
+			query: domain.TestsQuery{Page: 1, PerPage: 5},
+			want:  bson.D{},
+		},
+		{
+			name: "all filters",
+			query: domain.TestsQuery{
+				Type:      &testType,
+				Tags:      []string{"go", "basics"},
+				CreatorID: &creatorID,
+				Title:     &title,
+				Status:    &status,
+			},
+			want: bson.D{
+				{"type", domain.TestTypeQuiz},
+				{"tags", bson.D{{"$all", []string{"go", "basics"}}}},
+				{"creator_id", 7},
+				{"title", primitive.Regex{Pattern: `go \(basics\)`, Options: "i"}},
+				{"status", domain.TestStatusPublished},
+			},
+		},
+		{
+			name:  "empty title and tags",
+			query: domain.TestsQuery{Title: new(string), Tags: []string{}},
+			want:  bson.D{},
+		},
+		{
+			name:  "hide drafts for anonymous",
+			query: domain.TestsQuery{HideDrafts: true},
+			want: bson.D{
+				{"$or", bson.A{bson.D{{"status", bson.D{{"$ne", domain.TestStatusDraft}}}}}},
+			},
+		},
+		{
+			name:  "hide drafts except own",
+			query: domain.TestsQuery{HideDrafts: true, ViewerID: &viewerID},
+			want: bson.D{
+				{"$or", bson.A{
+					bson.D{{"status", bson.D{{"$ne", domain.TestStatusDraft}}}},
+					bson.D{{"creator_id", 3}},
+				}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, getTestsFilter(tt.query))
+		})
+	}
+}
+
+func TestGetSort(t *testing.T) {
+	tests := []struct {
+		name  string
+		field string
+		order string
+		want  bson.D
+	}{
+		{name: "id ascending", field: "id", order: sort.ASC, want: bson.D{{"_id", 1}}},
+		{name: "field descending", field: "title", order: sort.DESC, want: bson.D{{"title", -1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, getSort(tt.field, tt.order))
+		})
+	}
+}
+
+func TestGetSearchTestsFilter(t *testing.T) {
+	testType := domain.TestTypeQuiz
+
+	tests := []struct {
+		name  string
+		text  string
+		query domain.TestsQuery
+		want  bson.D
+	}{
+		{
+			name: "text only",
+			text: "golang",
+			want: bson.D{{"$text", bson.D{{"$search", "golang"}}}},
+		},
+		{
+			name:  "text with filters",
+			text:  "golang basics",
+			query: domain.TestsQuery{Type: &testType, HideDrafts: true},
+			want: bson.D{
+				{"$text", bson.D{{"$search", "golang basics"}}},
+				{"type", domain.TestTypeQuiz},
+				{"$or", bson.A{bson.D{{"status", bson.D{{"$ne", domain.TestStatusDraft}}}}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, getSearchTestsFilter(tt.text, tt.query))
+		})
+	}
+}
+
+func TestGetResultIDFilter(t *testing.T) {
+	legacy := primitive.NewObjectID()
+
+	tests := []struct {
+		name string
+		id   string
+		want bson.D
+	}{
+		{
+			name: "generated id",
+			id:   "0b8f4b4e-54b7-4a8c-9a3c-2d5b1e8a7f10",
+			want: bson.D{{"_id", "0b8f4b4e-54b7-4a8c-9a3c-2d5b1e8a7f10"}},
+		},
+		{
+			name: "legacy object id",
+			id:   legacy.Hex(),
+			want: bson.D{{"_id", bson.D{{"$in", bson.A{legacy.Hex(), legacy}}}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, getResultIDFilter(tt.id))
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package mongo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/coddmeistr/quizzify/backend/tests/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// mergePatchUpdate translates merge patch of the document of type t into $set and $unset paths
+// Null deletes the field with $unset, other values are decoded into the field type and set with $set
+// Object is merged field by field when the stored value is a document, otherwise it replaces the stored value as RFC 7396 says
+// current is the stored document, it's used to decide if nested object can be merged
+func mergePatchUpdate(t reflect.Type, current bson.M, patch domain.MergePatch, prefix string, set, unset *bson.D) error {
+	keys := make([]string, 0, len(patch))
+	for k := range patch {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		raw := patch[key]
+		field, ok := fieldByJSONName(t, key)
+		if !ok {
+			return fmt.Errorf("unknown field %q", prefix+key)
+		}
+		name := strings.Split(field.Tag.Get("bson"), ",")[0]
+		path := prefix + name
+
+		if domain.IsJSONNull(raw) {
+			*unset = append(*unset, bson.E{Key: path, Value: ""})
+			continue
+		}
+
+		ft := field.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if doc, ok := asDocument(current[name]); ok && ft.Kind() == reflect.Struct && isJSONObject(raw) {
+			var nested domain.MergePatch
+			if err := json.Unmarshal(raw, &nested); err != nil {
+				return fmt.Errorf("field %q: %w", path, err)
+			}
+			if err := mergePatchUpdate(ft, doc, nested, path+".", set, unset); err != nil {
+				return err
+			}
+			continue
+		}
+
+		v := reflect.New(field.Type)
+		if err := json.Unmarshal(raw, v.Interface()); err != nil {
+			return fmt.Errorf("field %q: %w", path, err)
+		}
+		*set = append(*set, bson.E{Key: path, Value: v.Elem().Interface()})
+	}
+
+	return nil
+}
+
+// fieldByJSONName returns field of the struct type with given json name
+func fieldByJSONName(t reflect.Type, name string) (reflect.StructField, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return reflect.StructField{}, false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if strings.Split(f.Tag.Get("json"), ",")[0] == name && f.Tag.Get("bson") != "" {
+			return f, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// asDocument returns stored value as a document, false if it's not a document
+func asDocument(v any) (bson.M, bool) {
+	switch d := v.(type) {
+	case bson.M:
+		return d, true
+	case bson.D:
+		return d.Map(), true
+	default:
+		return nil, false
+	}
+}
+
+func isJSONObject(v json.RawMessage) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(v), []byte("{"))
+}
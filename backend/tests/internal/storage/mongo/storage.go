@@ -10,11 +10,15 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"reflect"
+	"time"
 )
 
 const (
-	testsCollection   = "tests"
-	resultsCollection = "results"
+	testsCollection    = "tests"
+	resultsCollection  = "results"
+	attemptsCollection = "attempts"
+	banksCollection    = "question_banks"
+	versionsCollection = "test_versions"
 )
 
 type Storage struct {
@@ -27,15 +31,66 @@ func New(db *mongo.Database) *Storage {
 	}
 }
 
-func (s *Storage) GetResults(ctx context.Context) ([]*domain.Result, error) {
+func (s *Storage) GetResults(ctx context.Context, query domain.ResultsQuery) ([]*domain.Result, int64, error) {
 	const op = "mongo.storage.GetResults"
 
-	cur, err := s.db.Collection(resultsCollection).Find(ctx, bson.D{})
+	filter := getResultsFilter(query)
+
+	opt := options.Find().
+		SetSort(bson.D{{"created_at", -1}}).
+		SetSkip(int64((query.Page - 1) * query.PerPage)).
+		SetLimit(int64(query.PerPage))
+
+	total, err := s.db.Collection(resultsCollection).CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	cur, err := s.db.Collection(resultsCollection).Find(ctx, filter, opt)
 	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = cur.Close(ctx) }()
+
+	results := make([]*domain.Result, 0)
+	if err = cur.All(ctx, &results); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return results, total, nil
+}
+
+func (s *Storage) GetResultByID(ctx context.Context, resultID string) (*domain.Result, error) {
+	const op = "mongo.storage.GetResultByID"
+
+	var result domain.Result
+	err := s.db.Collection(resultsCollection).FindOne(ctx, getResultIDFilter(resultID)).Decode(&result)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+		}
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	var results []*domain.Result
+	return &result, nil
+}
+
+// GetUserResults returns short info about all results of the user for the test ordered from the first one
+// Only creation time, attempt number and percentage are provided
+func (s *Storage) GetUserResults(ctx context.Context, testID string, userID int) ([]*domain.Result, error) {
+	const op = "mongo.storage.GetUserResults"
+
+	opt := options.Find().
+		SetProjection(bson.D{{"created_at", 1}, {"attempt", 1}, {"percentage", 1}}).
+		SetSort(bson.D{{"created_at", 1}})
+
+	cur, err := s.db.Collection(resultsCollection).Find(ctx, bson.D{{"test_id", testID}, {"user_id", userID}}, opt)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = cur.Close(ctx) }()
+
+	results := make([]*domain.Result, 0)
 	if err = cur.All(ctx, &results); err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -43,34 +98,122 @@ func (s *Storage) GetResults(ctx context.Context) ([]*domain.Result, error) {
 	return results, nil
 }
 
+// SaveUserResult inserts new result of the user
+// Returns storage.ErrExists if the user already has result with the same attempt number for the test
 func (s *Storage) SaveUserResult(ctx context.Context, result domain.Result) error {
 	const op = "mongo.storage.SaveUserResult"
 
 	_, err := s.db.Collection(resultsCollection).InsertOne(ctx, result)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("%s: %w", op, storage.ErrExists)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteUserResult(ctx context.Context, resultID string) error {
+	const op = "mongo.storage.DeleteUserResult"
+
+	res, err := s.db.Collection(resultsCollection).DeleteOne(ctx, bson.D{{"_id", resultID}})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if res.DeletedCount == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+
+	return nil
+}
+
+// UpdateResultScore saves breakdown and score of the result that is still pending review
+// Returns storage.ErrNotFound if there is no such pending result
+func (s *Storage) UpdateResultScore(ctx context.Context, result domain.Result) error {
+	const op = "mongo.storage.UpdateResultScore"
+
+	filter := bson.D{{"_id", result.ID}, {"pending", true}}
+	update := bson.D{{"$set", bson.D{
+		{"questions", result.Questions},
+		{"points", result.Points},
+		{"max_points", result.MaxPoints},
+		{"percentage", result.Percentage},
+		{"final_percentage", result.FinalPercentage},
+		{"pending", result.Pending},
+	}}}
+
+	res, err := s.db.Collection(resultsCollection).UpdateOne(ctx, filter, update)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
 
 	return nil
 }
 
-func (s *Storage) GetTests(ctx context.Context) ([]*domain.Test, error) {
+func (s *Storage) GetTests(ctx context.Context, query domain.TestsQuery) ([]*domain.Test, int64, error) {
 	const op = "mongo.storage.GetTests"
 
-	opt := options.Find().SetProjection(getProjectionForAnswers())
+	filter := getTestsFilter(query)
+
+	opt := options.Find().
+		SetProjection(getProjectionForAnswers()).
+		SetSkip(int64((query.Page - 1) * query.PerPage)).
+		SetLimit(int64(query.PerPage))
+	if query.SortField != "" {
+		opt.SetSort(getSort(query.SortField, query.SortOrder))
+	}
+
+	total, err := s.db.Collection(testsCollection).CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
 
 	tests := make([]*domain.Test, 0)
-	cursor, err := s.db.Collection(testsCollection).Find(ctx, bson.D{}, opt)
+	cursor, err := s.db.Collection(testsCollection).Find(ctx, filter, opt)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
 	}
 	defer func() { _ = cursor.Close(ctx) }()
 
 	if err = cursor.All(ctx, &tests); err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return tests, nil
+	return tests, total, nil
+}
+
+func (s *Storage) SearchTests(ctx context.Context, text string, query domain.TestsQuery) ([]*domain.Test, int64, error) {
+	const op = "mongo.storage.SearchTests"
+
+	filter := getSearchTestsFilter(text, query)
+
+	opt := options.Find().
+		SetProjection(append(getProjectionForAnswers(), getProjectionForTextScore()...)).
+		SetSort(getProjectionForTextScore()).
+		SetSkip(int64((query.Page - 1) * query.PerPage)).
+		SetLimit(int64(query.PerPage))
+
+	total, err := s.db.Collection(testsCollection).CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tests := make([]*domain.Test, 0)
+	cursor, err := s.db.Collection(testsCollection).Find(ctx, filter, opt)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	if err = cursor.All(ctx, &tests); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tests, total, nil
 }
 
 func (s *Storage) CreateTest(ctx context.Context, test domain.Test) error {
@@ -118,29 +261,387 @@ func (s *Storage) GetTestByID(ctx context.Context, testID string, includeAnswers
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
+	// Bank questions are expanded on every read, so tests always show their current content
+	if ids := test.BankIDs(); len(ids) > 0 {
+		banks, err := s.GetQuestionBanksByIDs(ctx, ids, includeAnswers)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		test.ExpandBankQuestions(banks)
+	}
+
 	return &test, nil
 }
 
-func (s *Storage) UpdateTest(ctx context.Context, testID string, toUpdate domain.Test) error {
+// GetRawTestByID returns test as it is stored, with answers and without expanding bank references
+// It is used to edit the test, so bank questions are saved as references again
+func (s *Storage) GetRawTestByID(ctx context.Context, testID string) (*domain.Test, error) {
+	const op = "mongo.storage.GetRawTestByID"
+
+	var test domain.Test
+	err := s.db.Collection(testsCollection).FindOne(ctx, bson.D{{"_id", testID}}).Decode(&test)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &test, nil
+}
+
+// ReplaceTest replaces whole stored test with the given one
+// Returns storage.ErrConflict if the stored test was changed after given revision
+func (s *Storage) ReplaceTest(ctx context.Context, test domain.Test, revision int) error {
+	const op = "mongo.storage.ReplaceTest"
+
+	res, err := s.db.Collection(testsCollection).ReplaceOne(ctx, bson.D{{"_id", test.ID}, {"revision", revision}}, test)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("%s: %w", op, s.testWriteMissed(ctx, *test.ID))
+	}
+
+	return nil
+}
+
+// testWriteMissed explains why conditional write of the test matched nothing
+// Returns storage.ErrConflict if the test exists, so it was changed by someone else, and storage.ErrNotFound otherwise
+func (s *Storage) testWriteMissed(ctx context.Context, testID string) error {
+	count, err := s.db.Collection(testsCollection).CountDocuments(ctx, bson.D{{"_id", testID}})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return storage.ErrNotFound
+	}
+	return storage.ErrConflict
+}
+
+// UpdateTest applies merge patch to the stored test and increments its revision
+// Returns storage.ErrConflict if the test was changed after given revision
+func (s *Storage) UpdateTest(ctx context.Context, testID string, patch domain.MergePatch, revision int) error {
 	const op = "mongo.storage.UpdateTest"
 
-	// Prepare values to update only for non-nil fields
-	bsonToUpdate := make([]bson.E, 0)
-	val := reflect.ValueOf(toUpdate)
-	for i := 0; i < val.NumField(); i++ {
-		if val.Field(i).Kind() == reflect.Pointer && val.Field(i).IsNil() {
-			continue
+	filter := bson.D{{"_id", testID}, {"revision", revision}}
+
+	// Stored test is needed to know which nested objects can be merged and which must be replaced
+	var current bson.M
+	if err := s.db.Collection(testsCollection).FindOne(ctx, filter).Decode(&current); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fmt.Errorf("%s: %w", op, s.testWriteMissed(ctx, testID))
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	set, unset := bson.D{}, bson.D{}
+	if err := mergePatchUpdate(reflect.TypeOf(domain.Test{}), current, patch, "", &set, &unset); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	update := bson.D{{"$inc", bson.D{{"revision", 1}}}}
+	if len(set) > 0 {
+		update = append(update, bson.E{Key: "$set", Value: set})
+	}
+	if len(unset) > 0 {
+		update = append(update, bson.E{Key: "$unset", Value: unset})
+	}
+
+	res, err := s.db.Collection(testsCollection).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("%s: %w", op, s.testWriteMissed(ctx, testID))
+	}
+
+	return nil
+}
+
+// UpdateTestStatus moves test to the given lifecycle status and increments its revision
+// Returns storage.ErrConflict if the test was changed after given revision
+func (s *Storage) UpdateTestStatus(ctx context.Context, testID string, status string, revision int) error {
+	const op = "mongo.storage.UpdateTestStatus"
+
+	update := bson.D{{"$set", bson.D{{"status", status}}}, {"$inc", bson.D{{"revision", 1}}}}
+	res, err := s.db.Collection(testsCollection).UpdateOne(ctx, bson.D{{"_id", testID}, {"revision", revision}}, update)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("%s: %w", op, s.testWriteMissed(ctx, testID))
+	}
+
+	return nil
+}
+
+// CreateTestVersion saves snapshot of the test version
+// Returns storage.ErrExists if the version was already saved, so it was taken by concurrent write
+func (s *Storage) CreateTestVersion(ctx context.Context, version domain.TestVersion) error {
+	const op = "mongo.storage.CreateTestVersion"
+
+	_, err := s.db.Collection(versionsCollection).InsertOne(ctx, version)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("%s: %w", op, storage.ErrExists)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteTestVersion removes snapshot of the version that failed to be written to the test
+func (s *Storage) DeleteTestVersion(ctx context.Context, testID string, version int) error {
+	const op = "mongo.storage.DeleteTestVersion"
+
+	res, err := s.db.Collection(versionsCollection).DeleteOne(ctx, bson.D{{"test_id", testID}, {"version", version}})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if res.DeletedCount == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+
+	return nil
+}
+
+// GetTestVersions returns all versions of the test from the first one, without test snapshots
+func (s *Storage) GetTestVersions(ctx context.Context, testID string) ([]*domain.TestVersion, error) {
+	const op = "mongo.storage.GetTestVersions"
+
+	opt := options.Find().
+		SetProjection(bson.D{{"test", 0}}).
+		SetSort(bson.D{{"version", 1}})
+
+	cur, err := s.db.Collection(versionsCollection).Find(ctx, bson.D{{"test_id", testID}}, opt)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = cur.Close(ctx) }()
+
+	versions := make([]*domain.TestVersion, 0)
+	if err = cur.All(ctx, &versions); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return versions, nil
+}
+
+func (s *Storage) GetTestVersion(ctx context.Context, testID string, version int) (*domain.TestVersion, error) {
+	const op = "mongo.storage.GetTestVersion"
+
+	var v domain.TestVersion
+	err := s.db.Collection(versionsCollection).FindOne(ctx, bson.D{{"test_id", testID}, {"version", version}}).Decode(&v)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &v, nil
+}
+
+func (s *Storage) CreateAttempt(ctx context.Context, attempt domain.Attempt) error {
+	const op = "mongo.storage.CreateAttempt"
+
+	_, err := s.db.Collection(attemptsCollection).InsertOne(ctx, attempt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) GetAttemptByID(ctx context.Context, attemptID string) (*domain.Attempt, error) {
+	const op = "mongo.storage.GetAttemptByID"
+
+	var attempt domain.Attempt
+	err := s.db.Collection(attemptsCollection).FindOne(ctx, bson.D{{"_id", attemptID}}).Decode(&attempt)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &attempt, nil
+}
+
+// FinishAttempt marks attempt as submitted only if it wasn't submitted before
+// Returns storage.ErrNotFound if there is no such attempt in progress
+func (s *Storage) FinishAttempt(ctx context.Context, attemptID string, submittedAt time.Time, resultID string) error {
+	const op = "mongo.storage.FinishAttempt"
+
+	filter := bson.D{{"_id", attemptID}, {"submitted_at", nil}}
+	update := bson.D{{"$set", bson.D{{"submitted_at", submittedAt}, {"result_id", resultID}}}}
+
+	res, err := s.db.Collection(attemptsCollection).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+
+	return nil
+}
+
+// UpdateAttemptAnswers saves given answers of the attempt only if it is still in progress
+// Each answer is written separately, so concurrent saves of different questions don't overwrite each other
+// Returns storage.ErrNotFound if there is no such attempt in progress
+func (s *Storage) UpdateAttemptAnswers(ctx context.Context, attemptID string, answers []domain.UserAnswerModel) error {
+	const op = "mongo.storage.UpdateAttemptAnswers"
+
+	for _, a := range answers {
+		if err := s.saveAttemptAnswer(ctx, attemptID, a); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
 		}
+	}
 
-		if val.Field(i).IsZero() {
-			continue
+	return nil
+}
+
+// saveAttemptAnswer replaces saved answer on the question or adds it if the question wasn't answered yet
+// If the answer was added concurrently between the two updates, it is replaced on the next try
+func (s *Storage) saveAttemptAnswer(ctx context.Context, attemptID string, answer domain.UserAnswerModel) error {
+	coll := s.db.Collection(attemptsCollection)
+
+	for try := 0; try < 2; try++ {
+		filter := bson.D{{"_id", attemptID}, {"submitted_at", nil}, {"answers.question_id", answer.QuestionID}}
+		update := bson.D{{"$set", bson.D{{"answers.$", answer}}}}
+		res, err := coll.UpdateOne(ctx, filter, update)
+		if err != nil {
+			return err
+		}
+		if res.MatchedCount > 0 {
+			return nil
 		}
 
-		bsonToUpdate = append(bsonToUpdate, bson.E{Key: val.Type().Field(i).Tag.Get("bson"), Value: val.Field(i).Interface()})
+		// Answers are null until the first one is saved, answer is literal, so its text can't be taken for field path
+		filter = bson.D{{"_id", attemptID}, {"submitted_at", nil}, {"answers.question_id", bson.D{{"$ne", answer.QuestionID}}}}
+		pipeline := mongo.Pipeline{{{"$set", bson.D{{"answers", bson.D{{"$sortArray", bson.D{
+			{"input", bson.D{{"$concatArrays", bson.A{
+				bson.D{{"$ifNull", bson.A{"$answers", bson.A{}}}},
+				bson.A{bson.D{{"$literal", answer}}},
+			}}}},
+			{"sortBy", bson.D{{"question_id", 1}}},
+		}}}}}}}}
+		res, err = coll.UpdateOne(ctx, filter, pipeline)
+		if err != nil {
+			return err
+		}
+		if res.MatchedCount > 0 {
+			return nil
+		}
 	}
-	update := bson.D{{"$set", bson.D(bsonToUpdate)}}
 
-	res, err := s.db.Collection(testsCollection).UpdateOne(ctx, bson.D{{"_id", testID}}, update)
+	return storage.ErrNotFound
+}
+
+func (s *Storage) CreateQuestionBank(ctx context.Context, bank domain.QuestionBank) error {
+	const op = "mongo.storage.CreateQuestionBank"
+
+	_, err := s.db.Collection(banksCollection).InsertOne(ctx, bank)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) GetQuestionBanks(ctx context.Context, query domain.BanksQuery) ([]*domain.QuestionBank, int64, error) {
+	const op = "mongo.storage.GetQuestionBanks"
+
+	filter := bson.D{}
+	if query.CreatorID != nil {
+		filter = append(filter, bson.E{Key: "creator_id", Value: *query.CreatorID})
+	}
+
+	opt := options.Find().
+		SetProjection(getProjectionForAnswers()).
+		SetSort(bson.D{{"_id", 1}}).
+		SetSkip(int64((query.Page - 1) * query.PerPage)).
+		SetLimit(int64(query.PerPage))
+
+	total, err := s.db.Collection(banksCollection).CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	cur, err := s.db.Collection(banksCollection).Find(ctx, filter, opt)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = cur.Close(ctx) }()
+
+	banks := make([]*domain.QuestionBank, 0)
+	if err = cur.All(ctx, &banks); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return banks, total, nil
+}
+
+func (s *Storage) GetQuestionBankByID(ctx context.Context, bankID string, includeAnswers bool) (*domain.QuestionBank, error) {
+	const op = "mongo.storage.GetQuestionBankByID"
+
+	opt := options.FindOne()
+	if !includeAnswers {
+		opt.SetProjection(getProjectionForAnswers())
+	}
+
+	var bank domain.QuestionBank
+	err := s.db.Collection(banksCollection).FindOne(ctx, bson.D{{"_id", bankID}}, opt).Decode(&bank)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &bank, nil
+}
+
+// GetQuestionBanksByIDs returns all existing banks with given ids
+// Missing banks are skipped without error
+func (s *Storage) GetQuestionBanksByIDs(ctx context.Context, bankIDs []string, includeAnswers bool) ([]*domain.QuestionBank, error) {
+	const op = "mongo.storage.GetQuestionBanksByIDs"
+
+	opt := options.Find()
+	if !includeAnswers {
+		opt.SetProjection(getProjectionForAnswers())
+	}
+
+	cur, err := s.db.Collection(banksCollection).Find(ctx, bson.D{{"_id", bson.D{{"$in", bankIDs}}}}, opt)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = cur.Close(ctx) }()
+
+	banks := make([]*domain.QuestionBank, 0, len(bankIDs))
+	if err = cur.All(ctx, &banks); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return banks, nil
+}
+
+// UpdateQuestionBank replaces title, description and questions of the bank
+func (s *Storage) UpdateQuestionBank(ctx context.Context, bankID string, bank domain.QuestionBank) error {
+	const op = "mongo.storage.UpdateQuestionBank"
+
+	update := bson.D{
+		{"$set", bson.D{
+			{"title", bank.Title},
+			{"description", bank.Description},
+			{"questions", bank.Questions},
+		}},
+		{"$inc", bson.D{{"revision", 1}}},
+	}
+
+	res, err := s.db.Collection(banksCollection).UpdateByID(ctx, bankID, update)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
@@ -150,3 +651,47 @@ func (s *Storage) UpdateTest(ctx context.Context, testID string, toUpdate domain
 
 	return nil
 }
+
+func (s *Storage) DeleteQuestionBank(ctx context.Context, bankID string) error {
+	const op = "mongo.storage.DeleteQuestionBank"
+
+	res, err := s.db.Collection(banksCollection).DeleteOne(ctx, bson.D{{"_id", bankID}})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if res.DeletedCount == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+
+	return nil
+}
+
+// CountTestsUsingBank returns number of tests that reference questions of the bank
+func (s *Storage) CountTestsUsingBank(ctx context.Context, bankID string) (int64, error) {
+	const op = "mongo.storage.CountTestsUsingBank"
+
+	count, err := s.db.Collection(testsCollection).CountDocuments(ctx, bson.D{{"questions.bank_ref.bank_id", bankID}})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count, nil
+}
+
+// GetTestsUsingBank returns tests that reference the bank as they are stored, with answers and bank references
+func (s *Storage) GetTestsUsingBank(ctx context.Context, bankID string) ([]*domain.Test, error) {
+	const op = "mongo.storage.GetTestsUsingBank"
+
+	cur, err := s.db.Collection(testsCollection).Find(ctx, bson.D{{"questions.bank_ref.bank_id", bankID}})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = cur.Close(ctx) }()
+
+	tests := make([]*domain.Test, 0)
+	if err = cur.All(ctx, &tests); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tests, nil
+}
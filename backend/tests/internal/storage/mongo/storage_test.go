@@ -0,0 +1,220 @@
+package mongo
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/coddmeistr/quizzify/backend/tests/internal/domain"
+	"github.com/coddmeistr/quizzify/backend/tests/internal/storage"
+	"github.com/coddmeistr/quizzify/backend/tests/pkg/api/sort"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"testing"
+)
+
+func TestStorage_UpdateTest(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ns := "quizzify." + testsCollection
+
+	stored := bson.D{
+		{"_id", "t1"},
+		{"revision", 5},
+		{"title", "Old title"},
+		{"tags", bson.A{"go"}},
+		{"main_image", bson.D{{"name", "old.png"}, {"content", []byte{1}}}},
+	}
+
+	mt.Run("merge patch", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, stored),
+			mtest.CreateSuccessResponse(bson.E{"n", 1}, bson.E{"nModified", 1}),
+		)
+		patch := domain.MergePatch{
+			"title":      json.RawMessage(`"New title"`),
+			"tags":       json.RawMessage(`null`),
+			"main_image": json.RawMessage(`{"name": "new.png"}`),
+			"version":    json.RawMessage(`2`),
+		}
+
+		err := New(mt.DB).UpdateTest(context.Background(), "t1", patch, 5)
+		require.NoError(mt, err)
+
+		mt.GetStartedEvent() // find
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(mt, int32(5), update.Lookup("q", "revision").Int32())
+		u := update.Lookup("u")
+		assert.Equal(mt, int32(1), u.Document().Lookup("$inc", "revision").Int32())
+		assert.Equal(mt, "New title", u.Document().Lookup("$set", "title").StringValue())
+		assert.Equal(mt, "new.png", u.Document().Lookup("$set", "main_image.name").StringValue())
+		assert.Equal(mt, int32(2), u.Document().Lookup("$set", "version").Int32())
+		_, err = u.Document().LookupErr("$set", "main_image")
+		assert.Error(mt, err, "nested object must be merged, not replaced")
+		_, err = u.Document().LookupErr("$unset", "tags")
+		assert.NoError(mt, err)
+	})
+
+	mt.Run("replace missing nested object", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{"_id", "t1"}, {"revision", 5}}),
+			mtest.CreateSuccessResponse(bson.E{"n", 1}, bson.E{"nModified", 1}),
+		)
+		patch := domain.MergePatch{"main_image": json.RawMessage(`{"name": "new.png", "content": "AQI="}`)}
+
+		err := New(mt.DB).UpdateTest(context.Background(), "t1", patch, 5)
+		require.NoError(mt, err)
+
+		mt.GetStartedEvent()
+		u := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("u").Document()
+		image := u.Lookup("$set", "main_image").Document()
+		assert.Equal(mt, "new.png", image.Lookup("name").StringValue())
+		_, content := image.Lookup("content").Binary()
+		assert.Equal(mt, []byte{1, 2}, content)
+	})
+
+	mt.Run("unknown field", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, stored))
+
+		err := New(mt.DB).UpdateTest(context.Background(), "t1", domain.MergePatch{"unknown": json.RawMessage(`1`)}, 5)
+		assert.ErrorContains(mt, err, "unknown field")
+	})
+
+	mt.Run("conflict", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch),
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{"n", 1}}),
+		)
+
+		err := New(mt.DB).UpdateTest(context.Background(), "t1", domain.MergePatch{"title": json.RawMessage(`"New title"`)}, 4)
+		assert.ErrorIs(mt, err, storage.ErrConflict)
+	})
+
+	mt.Run("not found", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch),
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch),
+		)
+
+		err := New(mt.DB).UpdateTest(context.Background(), "t1", domain.MergePatch{"title": json.RawMessage(`"New title"`)}, 5)
+		assert.ErrorIs(mt, err, storage.ErrNotFound)
+	})
+}
+
+func TestStorage_GetTests(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ns := "quizzify." + testsCollection
+
+	mt.Run("page options", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{"n", 12}}),
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{"_id", "t1"}}, bson.D{{"_id", "t2"}}),
+		)
+		query := domain.TestsQuery{Page: 3, PerPage: 5, SortField: "id", SortOrder: sort.DESC}
+
+		tests, total, err := New(mt.DB).GetTests(context.Background(), query)
+		require.NoError(mt, err)
+		assert.Equal(mt, int64(12), total)
+		assert.Len(mt, tests, 2)
+
+		mt.GetStartedEvent() // count
+		find := mt.GetStartedEvent().Command
+		assert.Equal(mt, int64(10), find.Lookup("skip").Int64())
+		assert.Equal(mt, int64(5), find.Lookup("limit").Int64())
+		assert.Equal(mt, int32(-1), find.Lookup("sort", "_id").Int32())
+	})
+}
+
+func TestStorage_SearchTests(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ns := "quizzify." + testsCollection
+
+	mt.Run("sorted by relevance", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{"n", 7}}),
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{"_id", "t1"}, {"score", 1.5}}),
+		)
+		query := domain.TestsQuery{Page: 2, PerPage: 5}
+
+		tests, total, err := New(mt.DB).SearchTests(context.Background(), "golang", query)
+		require.NoError(mt, err)
+		assert.Equal(mt, int64(7), total)
+		assert.Len(mt, tests, 1)
+
+		mt.GetStartedEvent() // count
+		find := mt.GetStartedEvent().Command
+		assert.Equal(mt, "golang", find.Lookup("filter", "$text", "$search").StringValue())
+		assert.Equal(mt, "textScore", find.Lookup("sort", "score", "$meta").StringValue())
+		assert.Equal(mt, "textScore", find.Lookup("projection", "score", "$meta").StringValue())
+		assert.Equal(mt, int32(0), find.Lookup("projection", "questions.answers").Int32())
+		assert.Equal(mt, int64(5), find.Lookup("skip").Int64())
+		assert.Equal(mt, int64(5), find.Lookup("limit").Int64())
+	})
+}
+
+func TestStorage_SaveUserResult(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("ok", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		err := New(mt.DB).SaveUserResult(context.Background(), domain.Result{ID: "r1", TestID: "t1", UserID: 1, Attempt: 2})
+		assert.NoError(mt, err)
+	})
+
+	mt.Run("attempt number is taken", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   0,
+			Code:    11000,
+			Message: "E11000 duplicate key error collection: quizzify.results index: results_test_user_attempt",
+		}))
+
+		err := New(mt.DB).SaveUserResult(context.Background(), domain.Result{ID: "r1", TestID: "t1", UserID: 1, Attempt: 2})
+		assert.ErrorIs(mt, err, storage.ErrExists)
+	})
+}
+
+func TestStorage_UpdateAttemptAnswers(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	chosen := 2
+	answer := domain.UserAnswerModel{QuestionID: 3, ChosenID: &chosen}
+
+	mt.Run("replace answered question", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{"n", 1}, bson.E{"nModified", 1}))
+
+		err := New(mt.DB).UpdateAttemptAnswers(context.Background(), "a1", []domain.UserAnswerModel{answer})
+		require.NoError(mt, err)
+
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(mt, int32(3), update.Lookup("q", "answers.question_id").Int32())
+		assert.Equal(mt, int32(2), update.Lookup("u", "$set", "answers.$", "chosen_id").Int32())
+	})
+
+	mt.Run("add not answered question", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{"n", 0}, bson.E{"nModified", 0}),
+			mtest.CreateSuccessResponse(bson.E{"n", 1}, bson.E{"nModified", 1}),
+		)
+
+		err := New(mt.DB).UpdateAttemptAnswers(context.Background(), "a1", []domain.UserAnswerModel{answer})
+		require.NoError(mt, err)
+
+		mt.GetStartedEvent() // replace
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(mt, int32(3), update.Lookup("q", "answers.question_id", "$ne").Int32())
+		// Other answers aren't in the update, so answers saved concurrently are kept
+		_, err = update.Lookup("u").Array().Index(0).Value().Document().LookupErr("$set", "answers", "$sortArray")
+		assert.NoError(mt, err)
+	})
+
+	mt.Run("attempt is submitted", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{"n", 0}, bson.E{"nModified", 0}),
+			mtest.CreateSuccessResponse(bson.E{"n", 0}, bson.E{"nModified", 0}),
+			mtest.CreateSuccessResponse(bson.E{"n", 0}, bson.E{"nModified", 0}),
+			mtest.CreateSuccessResponse(bson.E{"n", 0}, bson.E{"nModified", 0}),
+		)
+
+		err := New(mt.DB).UpdateAttemptAnswers(context.Background(), "a1", []domain.UserAnswerModel{answer})
+		assert.ErrorIs(mt, err, storage.ErrNotFound)
+	})
+}
@@ -4,4 +4,6 @@ import "errors"
 
 var (
 	ErrNotFound = errors.New("not found")
+	ErrExists   = errors.New("already exists")
+	ErrConflict = errors.New("revision conflict")
 )
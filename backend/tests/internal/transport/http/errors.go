@@ -20,6 +20,18 @@ var (
 	ErrInvalidTestStructure = errors.New("invalid test structure")
 	ErrNotFound             = errors.New("not found")
 	ErrUniqueConstraint     = errors.New("got repeated value that must be unique")
+	ErrAttemptRequired      = errors.New("test must be applied within an attempt")
+	ErrAttemptFinished      = errors.New("attempt already finished")
+	ErrTimeLimitExceeded    = errors.New("time limit exceeded")
+	ErrAttemptsLimit        = errors.New("attempts limit reached")
+	ErrRetakeCooldown       = errors.New("retake cooldown is not over")
+	ErrBankInUse            = errors.New("question bank is used by tests")
+	ErrBankBreaksTests      = errors.New("question bank update breaks tests using it")
+	ErrResultNotPending     = errors.New("result is not pending review")
+	ErrNotGraded            = errors.New("not all answers are graded")
+	ErrTestNotPublished     = errors.New("test is not published")
+	ErrInvalidTransition    = errors.New("invalid test status transition")
+	ErrRevisionConflict     = errors.New("test was changed by someone else")
 )
 
 var codes = map[error]string{
@@ -34,6 +46,18 @@ var codes = map[error]string{
 	ErrInvalidTestStructure: "INVALID_TEST_STRUCTURE",
 	ErrNotFound:             "NOT_FOUND",
 	ErrUniqueConstraint:     "UNIQUE_CONSTRAINT",
+	ErrAttemptRequired:      "ATTEMPT_REQUIRED",
+	ErrAttemptFinished:      "ATTEMPT_FINISHED",
+	ErrTimeLimitExceeded:    "TIME_LIMIT_EXCEEDED",
+	ErrAttemptsLimit:        "ATTEMPTS_LIMIT",
+	ErrRetakeCooldown:       "RETAKE_COOLDOWN",
+	ErrBankInUse:            "BANK_IN_USE",
+	ErrBankBreaksTests:      "BANK_BREAKS_TESTS",
+	ErrResultNotPending:     "RESULT_NOT_PENDING",
+	ErrNotGraded:            "NOT_GRADED",
+	ErrTestNotPublished:     "TEST_NOT_PUBLISHED",
+	ErrInvalidTransition:    "INVALID_STATUS_TRANSITION",
+	ErrRevisionConflict:     "REVISION_CONFLICT",
 	ErrUnknown:              unknown,
 }
 
@@ -49,8 +73,15 @@ func CodeFromError(err error) int {
 	case errors.Is(err, ErrInvalidJSONBody), errors.Is(err, ErrFailedValidation),
 		errors.Is(err, ErrMaxLimit), errors.Is(err, ErrMinLimit),
 		errors.Is(err, ErrNoRequiredValue), errors.Is(err, ErrInvalidTestType),
-		errors.Is(err, ErrInvalidTestStructure), errors.Is(err, ErrUniqueConstraint):
+		errors.Is(err, ErrInvalidTestStructure), errors.Is(err, ErrUniqueConstraint),
+		errors.Is(err, ErrAttemptRequired), errors.Is(err, ErrAttemptFinished),
+		errors.Is(err, ErrTimeLimitExceeded), errors.Is(err, ErrAttemptsLimit),
+		errors.Is(err, ErrRetakeCooldown), errors.Is(err, ErrBankInUse), errors.Is(err, ErrBankBreaksTests),
+		errors.Is(err, ErrResultNotPending), errors.Is(err, ErrNotGraded),
+		errors.Is(err, ErrTestNotPublished), errors.Is(err, ErrInvalidTransition):
 		return http.StatusBadRequest
+	case errors.Is(err, ErrRevisionConflict):
+		return http.StatusConflict
 	case errors.Is(err, ErrInternal):
 		return http.StatusInternalServerError
 	case errors.Is(err, ErrForbidden):
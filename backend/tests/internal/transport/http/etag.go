@@ -0,0 +1,38 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var errInvalidETag = errors.New("invalid entity tag")
+
+// SetETag writes revision of the resource to the ETag header
+// Must be called before the response is written
+func SetETag(w http.ResponseWriter, revision int) {
+	w.Header().Set("ETag", strconv.Quote(strconv.Itoa(revision)))
+}
+
+// IfMatch returns revision from the If-Match header
+// Returns nil if header is not set or matches any revision
+func IfMatch(r *http.Request) (*int, error) {
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	if v == "" || v == "*" {
+		return nil, nil
+	}
+
+	// Revisions are compared strongly, weak tags are accepted as they are sent by some proxies
+	v = strings.TrimPrefix(v, "W/")
+	unquoted, err := strconv.Unquote(v)
+	if err != nil {
+		return nil, errInvalidETag
+	}
+	revision, err := strconv.Atoi(unquoted)
+	if err != nil {
+		return nil, errInvalidETag
+	}
+
+	return &revision, nil
+}
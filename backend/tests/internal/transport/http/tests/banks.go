@@ -0,0 +1,187 @@
+package testshandlers
+
+import (
+	"errors"
+	testsservice "github.com/coddmeistr/quizzify/backend/tests/internal/service/tests"
+	ahttp "github.com/coddmeistr/quizzify/backend/tests/internal/transport/http"
+	"github.com/coddmeistr/quizzify/backend/tests/pkg/api/paginate"
+	"github.com/coddmeistr/quizzify/backend/tests/pkg/httputil"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+func (h *Handlers) CreateQuestionBank(w http.ResponseWriter, r *http.Request) {
+	const op = "tests.handlers.CreateQuestionBank"
+	log := h.log.With(zap.String("op", op))
+
+	var req QuestionBank
+	if err := httputil.UnmarshalJSONBody(r.Body, &req); err != nil {
+		log.Error("failed to parse body", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInvalidJSONBody)
+		return
+	}
+
+	if ok := h.val.Validate(w, req); !ok {
+		log.Error("interrupting request due to failed validation")
+		return
+	}
+
+	id, err := h.srv.CreateQuestionBank(r.Context(), *req.ToDomain())
+	if err != nil {
+		if errors.Is(err, testsservice.ErrFailedTestValidation) {
+			log.Error("invalid question bank", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrInvalidTestStructure)
+			return
+		}
+		if errors.Is(err, testsservice.ErrNoRights) {
+			log.Error("forbidden action", zap.Error(err))
+			ahttp.WriteErrorMessage(w, ahttp.ErrForbidden, "no rights to create question bank")
+			return
+		}
+		log.Error("failed to create question bank", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInternal)
+		return
+	}
+
+	ahttp.WriteResponse(w, http.StatusCreated, id)
+}
+
+func (h *Handlers) GetQuestionBanks(w http.ResponseWriter, r *http.Request) {
+	const op = "tests.handlers.GetQuestionBanks"
+	log := h.log.With(zap.String("op", op))
+
+	pageOpt, _ := paginate.OptionsFromContext(r.Context())
+
+	banks, total, err := h.srv.GetQuestionBanks(r.Context(), banksQuery(pageOpt))
+	if err != nil {
+		if errors.Is(err, testsservice.ErrNoRights) {
+			log.Error("forbidden action", zap.Error(err))
+			ahttp.WriteErrorMessage(w, ahttp.ErrForbidden, "no rights to get question banks")
+			return
+		}
+		log.Error("failed to get question banks", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInternal)
+		return
+	}
+
+	ahttp.WriteResponse(w, http.StatusOK, paginate.NewResponse(banks, pageOpt, total))
+}
+
+func (h *Handlers) GetQuestionBank(w http.ResponseWriter, r *http.Request) {
+	const op = "tests.handlers.GetQuestionBank"
+	log := h.log.With(zap.String("op", op))
+
+	bankID, ok := mux.Vars(r)["bank_id"]
+	if !ok || bankID == "" {
+		log.Error("failed to get bank id from url path")
+		ahttp.WriteErrorMessage(w, ahttp.ErrNoRequiredValue, "no bank id in url path")
+		return
+	}
+
+	bank, err := h.srv.GetQuestionBankByID(r.Context(), bankID)
+	if err != nil {
+		if errors.Is(err, testsservice.ErrNotFound) {
+			log.Error("question bank not found", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrNotFound)
+			return
+		}
+		if errors.Is(err, testsservice.ErrNoRights) {
+			log.Error("forbidden action", zap.Error(err))
+			ahttp.WriteErrorMessage(w, ahttp.ErrForbidden, "no rights to get question bank")
+			return
+		}
+		log.Error("failed to get question bank", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInternal)
+		return
+	}
+
+	ahttp.WriteResponse(w, http.StatusOK, bank)
+}
+
+func (h *Handlers) UpdateQuestionBank(w http.ResponseWriter, r *http.Request) {
+	const op = "tests.handlers.UpdateQuestionBank"
+	log := h.log.With(zap.String("op", op))
+
+	bankID, ok := mux.Vars(r)["bank_id"]
+	if !ok || bankID == "" {
+		log.Error("failed to get bank id from url path")
+		ahttp.WriteErrorMessage(w, ahttp.ErrNoRequiredValue, "no bank id in url path")
+		return
+	}
+
+	var req QuestionBank
+	if err := httputil.UnmarshalJSONBody(r.Body, &req); err != nil {
+		log.Error("failed to parse body", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInvalidJSONBody)
+		return
+	}
+
+	if ok := h.val.Validate(w, req); !ok {
+		log.Error("interrupting request due to failed validation")
+		return
+	}
+
+	if err := h.srv.UpdateQuestionBank(r.Context(), bankID, *req.ToDomain()); err != nil {
+		if errors.Is(err, testsservice.ErrNotFound) {
+			log.Error("question bank not found", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrNotFound)
+			return
+		}
+		if errors.Is(err, testsservice.ErrNoRights) {
+			log.Error("forbidden action", zap.Error(err))
+			ahttp.WriteErrorMessage(w, ahttp.ErrForbidden, "no rights to update question bank")
+			return
+		}
+		if errors.Is(err, testsservice.ErrFailedTestValidation) {
+			log.Error("invalid question bank", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrInvalidTestStructure)
+			return
+		}
+		if errors.Is(err, testsservice.ErrBankBreaksTests) {
+			log.Error("question bank update breaks tests", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrBankBreaksTests)
+			return
+		}
+		log.Error("failed to update question bank", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInternal)
+		return
+	}
+
+	ahttp.WriteResponse(w, http.StatusOK, "question bank was updated")
+}
+
+func (h *Handlers) DeleteQuestionBank(w http.ResponseWriter, r *http.Request) {
+	const op = "tests.handlers.DeleteQuestionBank"
+	log := h.log.With(zap.String("op", op))
+
+	bankID, ok := mux.Vars(r)["bank_id"]
+	if !ok || bankID == "" {
+		log.Error("failed to get bank id from url path")
+		ahttp.WriteErrorMessage(w, ahttp.ErrNoRequiredValue, "no bank id in url path")
+		return
+	}
+
+	if err := h.srv.DeleteQuestionBank(r.Context(), bankID); err != nil {
+		if errors.Is(err, testsservice.ErrNotFound) {
+			log.Error("question bank not found", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrNotFound)
+			return
+		}
+		if errors.Is(err, testsservice.ErrNoRights) {
+			log.Error("forbidden action", zap.Error(err))
+			ahttp.WriteErrorMessage(w, ahttp.ErrForbidden, "no rights to delete question bank")
+			return
+		}
+		if errors.Is(err, testsservice.ErrBankInUse) {
+			log.Error("question bank is used by tests", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrBankInUse)
+			return
+		}
+		log.Error("failed to delete question bank", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInternal)
+		return
+	}
+
+	ahttp.WriteResponse(w, http.StatusOK, "question bank was deleted")
+}
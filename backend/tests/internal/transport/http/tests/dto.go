@@ -1,18 +1,65 @@
 package testshandlers
 
 import (
+	"errors"
 	"github.com/coddmeistr/quizzify/backend/tests/internal/domain"
+	"github.com/coddmeistr/quizzify/backend/tests/pkg/api/paginate"
+	"github.com/coddmeistr/quizzify/backend/tests/pkg/api/sort"
+	"net/url"
+	"strconv"
+	"strings"
 )
 
 type ApplyTestRequest struct {
-	UserAnswers []*UserAnswer `json:"user_answers" validate:"required,dive"`
+	AttemptID   *string       `json:"attempt_id"`
+	UserAnswers []*UserAnswer `json:"user_answers" validate:"omitempty,dive"` // May be omitted if answers were saved in the attempt
 }
 
-type UserAnswer struct {
+type GradeAnswersRequest struct {
+	Grades []*Grade `json:"grades" validate:"required,gte=1,dive"`
+}
+
+type Grade struct {
 	QuestionID int     `json:"question_id" validate:"required,gte=1"`
-	ChosenID   *int    `json:"chosen_id"`
-	ChosenIDs  *[]int  `json:"chosen_ids"`
-	WritedText *string `json:"writed_text"`
+	Points     *int    `json:"points" validate:"required,gte=0"`
+	Comment    *string `json:"comment"`
+}
+
+type SaveAttemptAnswersRequest struct {
+	UserAnswers []*UserAnswer `json:"user_answers" validate:"required,gte=1,dive"`
+}
+
+// userAnswersToDomain maps user answers by question id
+// Returns false if there are several answers on the same question
+func userAnswersToDomain(answers []*UserAnswer) (map[int]domain.UserAnswerModel, bool) {
+	res := make(map[int]domain.UserAnswerModel, len(answers))
+	for _, a := range answers {
+		da := a.ToDomain()
+		if _, has := res[da.QuestionID]; has {
+			return nil, false
+		}
+		res[da.QuestionID] = *da
+	}
+	return res, true
+}
+
+type UserAnswer struct {
+	QuestionID int      `json:"question_id" validate:"required,gte=1"`
+	ChosenID   *int     `json:"chosen_id"`
+	ChosenIDs  *[]int   `json:"chosen_ids"`
+	WritedText *string  `json:"writed_text"`
+	OrderedIDs *[]int   `json:"ordered_ids"`
+	Pairs      *[]*Pair `json:"pairs" validate:"omitempty,dive"`
+	Number     *float64 `json:"number"`
+	ChosenBool *bool    `json:"chosen_bool"`
+}
+
+type GetTestsRequest struct {
+	Type      *string
+	Tags      []string
+	CreatorID *int
+	Title     *string
+	Status    *string
 }
 
 type UpdateTestPreviewRequest struct {
@@ -27,6 +74,16 @@ type CreateTestRequest struct {
 	Test
 }
 
+// ReplaceTestRequest is the whole new content of the test
+// Creator of the test can't be changed, so creator id of the request is ignored
+type ReplaceTestRequest struct {
+	Test
+}
+
+type ReorderQuestionsRequest struct {
+	QuestionIDs []int `json:"question_ids" validate:"required,gte=1"`
+}
+
 type Test struct {
 	Title     *string      `json:"title" validate:"required"`
 	CreatorID *int         `json:"creator_id" validate:"required"`
@@ -36,17 +93,59 @@ type Test struct {
 	MainImage *Image       `json:"main_image"`
 	Questions *[]*Question `json:"questions" validate:"required,gte=1,dive"`
 	Tags      *[]string    `json:"tags"`
+
+	TimeLimit      *int    `json:"time_limit" validate:"omitempty,gte=1"`
+	LatePenalty    *int    `json:"late_penalty" validate:"omitempty,gte=0,lte=100"`
+	MaxAttempts    *int    `json:"max_attempts" validate:"omitempty,gte=1"`
+	RetakeCooldown *int    `json:"retake_cooldown" validate:"omitempty,gte=0"`
+	ScoreMode      *string `json:"score_mode" validate:"omitempty,oneof=best last average"`
+	RevealAnswers  bool    `json:"reveal_answers"`
+
+	ShuffleQuestions bool `json:"shuffle_questions"`
+	ShuffleVariants  bool `json:"shuffle_variants"`
+	QuestionsPool    *int `json:"questions_pool" validate:"omitempty,gte=1"`
+
+	Params   *[]string   `json:"params"`
+	Outcomes *[]*Outcome `json:"outcomes" validate:"omitempty,dive"`
+}
+
+type Outcome struct {
+	ID         int                  `json:"id"`
+	Title      *string              `json:"title" validate:"required"`
+	Text       *string              `json:"text"`
+	Image      *Image               `json:"image"`
+	Conditions *[]*OutcomeCondition `json:"conditions" validate:"omitempty,dive"`
+}
+
+type OutcomeCondition struct {
+	Name     *string `json:"name" validate:"required"`
+	Operator *string `json:"operator" validate:"required"`
+	Value    *int    `json:"value" validate:"required"`
 }
 
 type Question struct {
-	ID        int       `json:"id"  validate:"required,gte=1"`
-	Type      *string   `json:"type" validate:"required"`
-	LongText  *string   `json:"long_text"`
-	ShortText *string   `json:"short_text" validate:"required"`
-	Required  bool      `json:"required"`
-	Points    *int      `json:"points"`
-	Variants  *Variants `json:"variants" validate:"required,dive"`
-	Answer    *Answer   `json:"answers,omitempty"`
+	ID          int       `json:"id"  validate:"required,gte=1"`
+	Type        *string   `json:"type" validate:"required_without=BankRef"`
+	LongText    *string   `json:"long_text"`
+	ShortText   *string   `json:"short_text" validate:"required_without=BankRef"`
+	Required    bool      `json:"required"`
+	Points      *int      `json:"points"`
+	Scoring     *string   `json:"scoring" validate:"omitempty,oneof=all_or_nothing proportional negative"`
+	NeedsReview bool      `json:"needs_review"`
+	Variants    *Variants `json:"variants" validate:"required_without=BankRef,omitempty,dive"`
+	Answer      *Answer   `json:"answers,omitempty"`
+	BankRef     *BankRef  `json:"bank_ref"` // Question content is taken from the bank, only Points and Required can be set
+}
+
+type BankRef struct {
+	BankID     string `json:"bank_id" validate:"required"`
+	QuestionID int    `json:"question_id" validate:"required,gte=1"`
+}
+
+type QuestionBank struct {
+	Title       *string      `json:"title" validate:"required"`
+	Description *string      `json:"description"`
+	Questions   *[]*Question `json:"questions" validate:"required,gte=1,dive"`
 }
 
 type Image struct {
@@ -63,6 +162,28 @@ type VariantField struct {
 type Variants struct {
 	VariantSingleChoice   *VariantSingleChoice   `json:"single_choice"`
 	VariantMultipleChoice *VariantMultipleChoice `json:"multiple_choice"`
+	VariantOrdering       *VariantOrdering       `json:"ordering"`
+	VariantMatching       *VariantMatching       `json:"matching"`
+	VariantNumeric        *VariantNumeric        `json:"numeric"`
+	VariantTrueFalse      *VariantTrueFalse      `json:"true_false"`
+}
+
+type VariantOrdering struct {
+	OrderingFields *[]*VariantField `json:"fields" validate:"required,dive"`
+}
+
+type VariantMatching struct {
+	LeftFields  *[]*VariantField `json:"left" validate:"required,dive"`
+	RightFields *[]*VariantField `json:"right" validate:"required,dive"`
+}
+
+type VariantNumeric struct {
+	Unit *string `json:"unit"`
+}
+
+type VariantTrueFalse struct {
+	TrueText  *string `json:"true_text"`
+	FalseText *string `json:"false_text"`
 }
 
 type VariantSingleChoice struct {
@@ -75,20 +196,40 @@ type VariantMultipleChoice struct {
 }
 
 type Answer struct {
-	CorrectID   *int       `json:"correct_id"`
-	CorrectIDs  *[]int     `json:"correct_ids"`
-	CorrectText *string    `json:"correct_text"`
-	Params      *[]*Params `json:"params"`
+	CorrectID        *int          `json:"correct_id"`
+	CorrectIDs       *[]int        `json:"correct_ids"`
+	CorrectText      *string       `json:"correct_text"`
+	AlternativeTexts *[]string     `json:"alternative_texts"`
+	TextMatching     *TextMatching `json:"text_matching"`
+	CorrectOrder     *[]int        `json:"correct_order"`
+	CorrectPairs     *[]*Pair      `json:"correct_pairs" validate:"omitempty,dive"`
+	CorrectNumber    *float64      `json:"correct_number"`
+	Tolerance        *float64      `json:"tolerance" validate:"omitempty,gte=0"`
+	CorrectBool      *bool         `json:"correct_bool"`
+	Params           *[]*Params    `json:"params" validate:"omitempty,dive"`
+}
+
+type TextMatching struct {
+	CaseInsensitive  bool    `json:"case_insensitive"`
+	IgnoreWhitespace bool    `json:"ignore_whitespace"`
+	NormalizeUnicode bool    `json:"normalize_unicode"`
+	Pattern          *string `json:"pattern"`
+	MaxDistance      *int    `json:"max_distance" validate:"omitempty,gte=0"`
+}
+
+type Pair struct {
+	LeftID  int `json:"left_id"`
+	RightID int `json:"right_id"`
 }
 
 type Params struct {
 	FieldID int            `json:"id"`
-	Params  *[]*FlexParams `json:"params"`
+	Params  *[]*FlexParams `json:"params" validate:"required,dive"`
 }
 
 type FlexParams struct {
-	Name       *string `json:"name"`
-	Effect     *int    `json:"effect"`
+	Name       *string `json:"name" validate:"required"`
+	Effect     *int    `json:"effect" validate:"required"`
 	IsNegative *bool   `json:"is_negative"`
 }
 
@@ -98,8 +239,116 @@ func (a *UserAnswer) ToDomain() *domain.UserAnswerModel {
 		ChosenID:   a.ChosenID,
 		ChosenIDs:  a.ChosenIDs,
 		WritedText: a.WritedText,
+		OrderedIDs: a.OrderedIDs,
+		Pairs:      pairsToDomain(a.Pairs),
+		Number:     a.Number,
+		ChosenBool: a.ChosenBool,
+	}
+
+}
+
+func (m *TextMatching) ToDomain() *domain.TextMatching {
+	if m == nil {
+		return nil
+	}
+
+	return &domain.TextMatching{
+		CaseInsensitive:  m.CaseInsensitive,
+		IgnoreWhitespace: m.IgnoreWhitespace,
+		NormalizeUnicode: m.NormalizeUnicode,
+		Pattern:          m.Pattern,
+		MaxDistance:      m.MaxDistance,
+	}
+}
+
+func pairsToDomain(pairs *[]*Pair) *[]*domain.MatchPair {
+	if pairs == nil {
+		return nil
+	}
+
+	res := make([]*domain.MatchPair, 0, len(*pairs))
+	for _, v := range *pairs {
+		res = append(res, &domain.MatchPair{LeftID: v.LeftID, RightID: v.RightID})
+	}
+	return &res
+}
+
+// FromQuery fills filters from url query values
+// Tags can be passed both as repeated parameter and as comma separated list
+func (req *GetTestsRequest) FromQuery(q url.Values) error {
+	if v := q.Get("type"); v != "" {
+		req.Type = &v
+	}
+
+	for _, v := range q["tags"] {
+		for _, tag := range strings.Split(v, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				req.Tags = append(req.Tags, tag)
+			}
+		}
+	}
+
+	if v := q.Get("creator_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return errors.New("invalid creator id")
+		}
+		req.CreatorID = &id
+	}
+
+	if v := q.Get("title"); v != "" {
+		req.Title = &v
+	}
+
+	if v := q.Get("status"); v != "" {
+		if v != domain.TestStatusDraft && v != domain.TestStatusPublished && v != domain.TestStatusArchived {
+			return errors.New("invalid status")
+		}
+		req.Status = &v
+	}
+
+	return nil
+}
+
+func (req *GetTestsRequest) ToDomain(pageOpt paginate.Options, sortOpt sort.Options) *domain.TestsQuery {
+	return &domain.TestsQuery{
+		Page:      pageOpt.Page,
+		PerPage:   pageOpt.PerPage,
+		SortField: sortOpt.Field,
+		SortOrder: sortOpt.Order,
+		Type:      req.Type,
+		Tags:      req.Tags,
+		CreatorID: req.CreatorID,
+		Title:     req.Title,
+		Status:    req.Status,
+	}
+}
+
+func resultsQuery(pageOpt paginate.Options) domain.ResultsQuery {
+	return domain.ResultsQuery{
+		Page:    pageOpt.Page,
+		PerPage: pageOpt.PerPage,
 	}
+}
 
+func (req *GradeAnswersRequest) ToDomain() []domain.Grade {
+	grades := make([]domain.Grade, 0, len(req.Grades))
+	for _, g := range req.Grades {
+		grades = append(grades, domain.Grade{
+			QuestionID: g.QuestionID,
+			Points:     *g.Points,
+			Comment:    g.Comment,
+		})
+	}
+	return grades
+}
+
+// banksQuery builds query of question banks for the requested page
+func banksQuery(pageOpt paginate.Options) domain.BanksQuery {
+	return domain.BanksQuery{
+		Page:    pageOpt.Page,
+		PerPage: pageOpt.PerPage,
+	}
 }
 
 func (t *Test) ToDomain() *domain.Test {
@@ -114,6 +363,15 @@ func (t *Test) ToDomain() *domain.Test {
 		domainImage = t.MainImage.ToDomain()
 	}
 
+	var domainOutcomes *[]*domain.Outcome
+	if t.Outcomes != nil {
+		outcomes := make([]*domain.Outcome, 0, len(*t.Outcomes))
+		for _, v := range *t.Outcomes {
+			outcomes = append(outcomes, v.ToDomain())
+		}
+		domainOutcomes = &outcomes
+	}
+
 	return &domain.Test{
 		Title:     t.Title,
 		UserID:    t.CreatorID,
@@ -123,6 +381,53 @@ func (t *Test) ToDomain() *domain.Test {
 		MainImage: domainImage,
 		Tags:      t.Tags,
 		Questions: &domainQuestions,
+
+		TimeLimit:      t.TimeLimit,
+		LatePenalty:    t.LatePenalty,
+		MaxAttempts:    t.MaxAttempts,
+		RetakeCooldown: t.RetakeCooldown,
+		ScoreMode:      t.ScoreMode,
+		RevealAnswers:  t.RevealAnswers,
+
+		ShuffleQuestions: t.ShuffleQuestions,
+		ShuffleVariants:  t.ShuffleVariants,
+		QuestionsPool:    t.QuestionsPool,
+
+		Params:   t.Params,
+		Outcomes: domainOutcomes,
+	}
+}
+
+func (o *Outcome) ToDomain() *domain.Outcome {
+
+	var domainImage *domain.Image
+	if o.Image != nil {
+		domainImage = o.Image.ToDomain()
+	}
+
+	var domainConditions *[]*domain.OutcomeCondition
+	if o.Conditions != nil {
+		conditions := make([]*domain.OutcomeCondition, 0, len(*o.Conditions))
+		for _, v := range *o.Conditions {
+			conditions = append(conditions, v.ToDomain())
+		}
+		domainConditions = &conditions
+	}
+
+	return &domain.Outcome{
+		ID:         o.ID,
+		Title:      o.Title,
+		Text:       o.Text,
+		Image:      domainImage,
+		Conditions: domainConditions,
+	}
+}
+
+func (c *OutcomeCondition) ToDomain() *domain.OutcomeCondition {
+	return &domain.OutcomeCondition{
+		ParamName: c.Name,
+		Operator:  c.Operator,
+		Value:     c.Value,
 	}
 }
 
@@ -145,24 +450,90 @@ func (q *Question) ToDomain() *domain.Question {
 		domainAnswer = q.Answer.ToDomain()
 	}
 
+	var domainBankRef *domain.BankRef
+	if q.BankRef != nil {
+		domainBankRef = &domain.BankRef{
+			BankID:     q.BankRef.BankID,
+			QuestionID: q.BankRef.QuestionID,
+		}
+	}
+
 	return &domain.Question{
-		ID:        q.ID,
-		Type:      q.Type,
-		LongText:  q.LongText,
-		ShortText: q.ShortText,
-		Required:  q.Required,
-		Points:    q.Points,
-		Variants:  domainVariants,
-		Answers:   domainAnswer,
+		ID:          q.ID,
+		Type:        q.Type,
+		LongText:    q.LongText,
+		ShortText:   q.ShortText,
+		Required:    q.Required,
+		Points:      q.Points,
+		Scoring:     q.Scoring,
+		NeedsReview: q.NeedsReview,
+		Variants:    domainVariants,
+		Answers:     domainAnswer,
+		BankRef:     domainBankRef,
+	}
+}
+
+func (b *QuestionBank) ToDomain() *domain.QuestionBank {
+	questions := make([]*domain.Question, 0, len(*b.Questions))
+	for _, q := range *b.Questions {
+		questions = append(questions, q.ToDomain())
+	}
+
+	return &domain.QuestionBank{
+		Title:       b.Title,
+		Description: b.Description,
+		Questions:   &questions,
 	}
 }
 
 func (a *Answer) ToDomain() *domain.AnswerModel {
 
+	var domainParams *[]*domain.FlexParamsModel
+	if a.Params != nil {
+		params := make([]*domain.FlexParamsModel, 0, len(*a.Params))
+		for _, v := range *a.Params {
+			params = append(params, v.ToDomain())
+		}
+		domainParams = &params
+	}
+
 	return &domain.AnswerModel{
-		CorrectID:   a.CorrectID,
-		CorrectIDs:  a.CorrectIDs,
-		CorrectText: a.CorrectText,
+		CorrectID:        a.CorrectID,
+		CorrectIDs:       a.CorrectIDs,
+		CorrectText:      a.CorrectText,
+		AlternativeTexts: a.AlternativeTexts,
+		TextMatching:     a.TextMatching.ToDomain(),
+		CorrectOrder:     a.CorrectOrder,
+		CorrectPairs:     pairsToDomain(a.CorrectPairs),
+		CorrectNumber:    a.CorrectNumber,
+		Tolerance:        a.Tolerance,
+		CorrectBool:      a.CorrectBool,
+		FlexParams:       domainParams,
+	}
+}
+
+func (p *Params) ToDomain() *domain.FlexParamsModel {
+
+	var domainParams *[]*domain.FlexParam
+	if p.Params != nil {
+		params := make([]*domain.FlexParam, 0, len(*p.Params))
+		for _, v := range *p.Params {
+			params = append(params, v.ToDomain())
+		}
+		domainParams = &params
+	}
+
+	return &domain.FlexParamsModel{
+		FieldID: p.FieldID,
+		Params:  domainParams,
+	}
+}
+
+func (p *FlexParams) ToDomain() *domain.FlexParam {
+	return &domain.FlexParam{
+		ParamName:  p.Name,
+		Effect:     p.Effect,
+		IsNegative: p.IsNegative != nil && *p.IsNegative,
 	}
 }
 
@@ -177,10 +548,48 @@ func (a *Variants) ToDomain() *domain.VariantsModel {
 		domainMultipleChoice = a.VariantMultipleChoice.ToDomain()
 	}
 
+	var domainOrdering *domain.Ordering
+	if a.VariantOrdering != nil {
+		domainOrdering = &domain.Ordering{Fields: fieldsToDomain(*a.VariantOrdering.OrderingFields)}
+	}
+
+	var domainMatching *domain.Matching
+	if a.VariantMatching != nil {
+		domainMatching = &domain.Matching{
+			Left:  fieldsToDomain(*a.VariantMatching.LeftFields),
+			Right: fieldsToDomain(*a.VariantMatching.RightFields),
+		}
+	}
+
+	var domainNumeric *domain.Numeric
+	if a.VariantNumeric != nil {
+		domainNumeric = &domain.Numeric{Unit: a.VariantNumeric.Unit}
+	}
+
+	var domainTrueFalse *domain.TrueFalse
+	if a.VariantTrueFalse != nil {
+		domainTrueFalse = &domain.TrueFalse{
+			TrueText:  a.VariantTrueFalse.TrueText,
+			FalseText: a.VariantTrueFalse.FalseText,
+		}
+	}
+
 	return &domain.VariantsModel{
 		SingleChoice:   domainSingleChoice,
 		MultipleChoice: domainMultipleChoice,
+		Ordering:       domainOrdering,
+		Matching:       domainMatching,
+		Numeric:        domainNumeric,
+		TrueFalse:      domainTrueFalse,
+	}
+}
+
+func fieldsToDomain(fields []*VariantField) *[]*domain.CommonField {
+	domainFields := make([]*domain.CommonField, 0, len(fields))
+	for _, v := range fields {
+		domainFields = append(domainFields, v.ToDomain())
 	}
+	return &domainFields
 }
 
 func (a VariantMultipleChoice) ToDomain() *domain.MultipleChoice {
@@ -220,19 +629,3 @@ func (a *VariantField) ToDomain() *domain.CommonField {
 		Image:   domainImage,
 	}
 }
-
-func (t *UpdateTestPreviewRequest) ToDomain() *domain.Test {
-
-	var domainImage *domain.Image
-	if t.MainImage != nil {
-		domainImage = t.MainImage.ToDomain()
-	}
-
-	return &domain.Test{
-		Title:     t.Title,
-		ShortText: t.ShortText,
-		LongText:  t.LongText,
-		MainImage: domainImage,
-		Tags:      t.Tags,
-	}
-}
@@ -0,0 +1,87 @@
+package testshandlers
+
+import (
+	"errors"
+	testsservice "github.com/coddmeistr/quizzify/backend/tests/internal/service/tests"
+	ahttp "github.com/coddmeistr/quizzify/backend/tests/internal/transport/http"
+	"github.com/coddmeistr/quizzify/backend/tests/pkg/httputil"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+func (h *Handlers) GradeAnswers(w http.ResponseWriter, r *http.Request) {
+	const op = "tests.handlers.GradeAnswers"
+	log := h.log.With(zap.String("op", op))
+
+	resultID, ok := mux.Vars(r)["result_id"]
+	if !ok || resultID == "" {
+		log.Error("failed to get result id from url path")
+		ahttp.WriteErrorMessage(w, ahttp.ErrNoRequiredValue, "no result id in url path")
+		return
+	}
+
+	var req GradeAnswersRequest
+	if err := httputil.UnmarshalJSONBody(r.Body, &req); err != nil {
+		log.Error("failed to parse body", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInvalidJSONBody)
+		return
+	}
+
+	if ok := h.val.Validate(w, req); !ok {
+		log.Error("interrupting request due to failed validation")
+		return
+	}
+
+	result, err := h.srv.GradeAnswers(r.Context(), resultID, req.ToDomain())
+	if err != nil {
+		h.writeGradingError(w, log, err)
+		return
+	}
+
+	ahttp.WriteResponse(w, http.StatusOK, result)
+}
+
+func (h *Handlers) FinalizeResult(w http.ResponseWriter, r *http.Request) {
+	const op = "tests.handlers.FinalizeResult"
+	log := h.log.With(zap.String("op", op))
+
+	resultID, ok := mux.Vars(r)["result_id"]
+	if !ok || resultID == "" {
+		log.Error("failed to get result id from url path")
+		ahttp.WriteErrorMessage(w, ahttp.ErrNoRequiredValue, "no result id in url path")
+		return
+	}
+
+	result, err := h.srv.FinalizeResult(r.Context(), resultID)
+	if err != nil {
+		h.writeGradingError(w, log, err)
+		return
+	}
+
+	ahttp.WriteResponse(w, http.StatusOK, result)
+}
+
+// writeGradingError maps errors of manual grading to http errors
+func (h *Handlers) writeGradingError(w http.ResponseWriter, log *zap.Logger, err error) {
+	switch {
+	case errors.Is(err, testsservice.ErrNotFound):
+		log.Error("result or test not found", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrNotFound)
+	case errors.Is(err, testsservice.ErrNoRights):
+		log.Error("forbidden action", zap.Error(err))
+		ahttp.WriteErrorMessage(w, ahttp.ErrForbidden, "no rights to grade result")
+	case errors.Is(err, testsservice.ErrResultNotPending):
+		log.Error("result is not pending review", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrResultNotPending)
+	case errors.Is(err, testsservice.ErrNotGraded):
+		log.Error("not all answers are graded", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrNotGraded)
+	case errors.Is(err, testsservice.ErrFailedTestValidation):
+		log.Error("invalid grades", zap.Error(err))
+		ahttp.WriteErrorMessage(w, ahttp.ErrFailedValidation, "invalid grades")
+	default:
+		log.Error("failed to grade result", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInternal)
+	}
+}
@@ -1,30 +1,59 @@
 package testshandlers
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"github.com/coddmeistr/quizzify/backend/tests/internal/config"
 	"github.com/coddmeistr/quizzify/backend/tests/internal/domain"
 	"github.com/coddmeistr/quizzify/backend/tests/internal/helpers/user"
 	testsservice "github.com/coddmeistr/quizzify/backend/tests/internal/service/tests"
 	ahttp "github.com/coddmeistr/quizzify/backend/tests/internal/transport/http"
+	"github.com/coddmeistr/quizzify/backend/tests/pkg/api/paginate"
+	"github.com/coddmeistr/quizzify/backend/tests/pkg/api/sort"
 	"github.com/coddmeistr/quizzify/backend/tests/pkg/httputil"
 	"github.com/coddmeistr/quizzify/backend/tests/pkg/slice"
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 type Service interface {
 	CreateTest(ctx context.Context, test domain.Test) (string, error)
-	UpdateTest(ctx context.Context, testID string, test domain.Test) error
+	UpdateTest(ctx context.Context, testID string, patch domain.MergePatch, revision *int) error
 	DeleteTest(ctx context.Context, testID string) error
+	PublishTest(ctx context.Context, testID string, revision *int) error
+	ArchiveTest(ctx context.Context, testID string, revision *int) error
 	GetTestByID(ctx context.Context, testID string, provideAnswers bool) (*domain.Test, error)
-	GetTests(ctx context.Context) ([]*domain.Test, error)
-	ApplyTest(ctx context.Context, testID string, UserID int, answers map[int]domain.UserAnswerModel) error
-	GetResults(ctx context.Context) ([]*domain.Result, error)
+	GetTests(ctx context.Context, query domain.TestsQuery) ([]*domain.Test, int64, error)
+	SearchTests(ctx context.Context, text string, query domain.TestsQuery) ([]*domain.Test, int64, error)
+	ApplyTest(ctx context.Context, testID string, UserID int, attemptID string, answers map[int]domain.UserAnswerModel) (*domain.Result, error)
+	StartAttempt(ctx context.Context, testID string) (*domain.Attempt, error)
+	GetAttempt(ctx context.Context, attemptID string) (*domain.Attempt, error)
+	SaveAttemptAnswers(ctx context.Context, attemptID string, answers map[int]domain.UserAnswerModel) (*domain.Attempt, error)
+	GetResults(ctx context.Context, query domain.ResultsQuery) ([]*domain.Result, int64, error)
+	GetUserResults(ctx context.Context, query domain.ResultsQuery) ([]*domain.Result, int64, error)
+	GetTestResults(ctx context.Context, testID string, query domain.ResultsQuery) ([]*domain.Result, int64, error)
+	GetResultByID(ctx context.Context, resultID string) (*domain.Result, error)
+	CreateQuestionBank(ctx context.Context, bank domain.QuestionBank) (string, error)
+	GetQuestionBanks(ctx context.Context, query domain.BanksQuery) ([]*domain.QuestionBank, int64, error)
+	GetQuestionBankByID(ctx context.Context, bankID string) (*domain.QuestionBank, error)
+	UpdateQuestionBank(ctx context.Context, bankID string, bank domain.QuestionBank) error
+	DeleteQuestionBank(ctx context.Context, bankID string) error
+	GradeAnswers(ctx context.Context, resultID string, grades []domain.Grade) (*domain.Result, error)
+	FinalizeResult(ctx context.Context, resultID string) (*domain.Result, error)
+	ReplaceTest(ctx context.Context, testID string, test domain.Test, revision *int) (*domain.Test, error)
+	AddQuestion(ctx context.Context, testID string, question domain.Question, revision *int) (*domain.Test, error)
+	UpdateQuestion(ctx context.Context, testID string, question domain.Question, revision *int) (*domain.Test, error)
+	RemoveQuestion(ctx context.Context, testID string, questionID int, revision *int) (*domain.Test, error)
+	ReorderQuestions(ctx context.Context, testID string, questionIDs []int, revision *int) (*domain.Test, error)
+	GetTestVersions(ctx context.Context, testID string) ([]*domain.TestVersion, error)
+	GetTestVersion(ctx context.Context, testID string, version int) (*domain.TestVersion, error)
 }
 
 const (
@@ -32,9 +61,27 @@ const (
 	updateTestPreviewUrl = "/tests/{test_id}/preview"
 	deleteTestUrl        = "/tests/{test_id}"
 	getTestsUrl          = "/tests"
+	searchTestsUrl       = "/tests/search"
 	getTestUrl           = "/tests/{test_id}"
+	publishTestUrl       = "/tests/{test_id}/publish"
+	archiveTestUrl       = "/tests/{test_id}/archive"
+	replaceTestUrl       = "/tests/{test_id}"
+	questionsUrl         = "/tests/{test_id}/questions"
+	questionsOrderUrl    = "/tests/{test_id}/questions/order"
+	questionUrl          = "/tests/{test_id}/questions/{question_id}"
+	testVersionsUrl      = "/tests/{test_id}/versions"
+	testVersionUrl       = "/tests/{test_id}/versions/{version}"
 	applyTestUrl         = "/tests/{test_id}/apply"
-	getResultsUrl        = "/tests/results"
+	startAttemptUrl      = "/tests/{test_id}/attempts"
+	attemptAnswersUrl    = "/attempts/{attempt_id}/answers"
+	getResultsUrl        = "/results"
+	getUserResultsUrl    = "/me/results"
+	getTestResultsUrl    = "/tests/{test_id}/results"
+	getResultUrl         = "/results/{result_id}"
+	gradeResultUrl       = "/results/{result_id}/grades"
+	finalizeResultUrl    = "/results/{result_id}/finalize"
+	banksUrl             = "/banks"
+	bankUrl              = "/banks/{bank_id}"
 )
 
 type Handlers struct {
@@ -57,31 +104,158 @@ func New(log *zap.Logger, cfg *config.Config, srv Service) *Handlers {
 
 func (h *Handlers) Register(router *mux.Router) {
 	router.Methods(http.MethodGet).Path(getTestsUrl).HandlerFunc(h.GetTests)
+	router.Methods(http.MethodGet).Path(searchTestsUrl).HandlerFunc(h.SearchTests)
 	router.Methods(http.MethodGet).Path(getTestUrl).HandlerFunc(h.GetTest)
 
 	auth := router.PathPrefix("").Subrouter()
 	auth.Use(
-		user.AuthMiddleware(0),
+		user.AuthMiddleware(),
 	)
 	auth.Methods(http.MethodPost).Path(applyTestUrl).HandlerFunc(h.ApplyTest)
+	auth.Methods(http.MethodPost).Path(startAttemptUrl).HandlerFunc(h.StartAttempt)
+	auth.Methods(http.MethodGet).Path(attemptAnswersUrl).HandlerFunc(h.GetAttemptAnswers)
+	auth.Methods(http.MethodPut).Path(attemptAnswersUrl).HandlerFunc(h.SaveAttemptAnswers)
 	auth.Methods(http.MethodPost).Path(createTestUrl).HandlerFunc(h.CreateTest)
 	auth.Methods(http.MethodPut).Path(updateTestPreviewUrl).HandlerFunc(h.UpdateTestPreview)
 	auth.Methods(http.MethodDelete).Path(deleteTestUrl).HandlerFunc(h.DeleteTest)
-	router.Methods(http.MethodGet).Path(getResultsUrl).HandlerFunc(h.GetResults)
+	auth.Methods(http.MethodPost).Path(publishTestUrl).HandlerFunc(h.PublishTest)
+	auth.Methods(http.MethodPost).Path(archiveTestUrl).HandlerFunc(h.ArchiveTest)
+	auth.Methods(http.MethodPut).Path(replaceTestUrl).HandlerFunc(h.ReplaceTest)
+	auth.Methods(http.MethodPost).Path(questionsUrl).HandlerFunc(h.AddQuestion)
+	auth.Methods(http.MethodPut).Path(questionsOrderUrl).HandlerFunc(h.ReorderQuestions) // Before questionUrl, so "order" is not taken as question id
+	auth.Methods(http.MethodPut).Path(questionUrl).HandlerFunc(h.UpdateQuestion)
+	auth.Methods(http.MethodDelete).Path(questionUrl).HandlerFunc(h.RemoveQuestion)
+	auth.Methods(http.MethodGet).Path(testVersionsUrl).HandlerFunc(h.GetTestVersions)
+	auth.Methods(http.MethodGet).Path(testVersionUrl).HandlerFunc(h.GetTestVersion)
+	auth.Methods(http.MethodGet).Path(getResultsUrl).HandlerFunc(h.GetResults)
+	auth.Methods(http.MethodGet).Path(getUserResultsUrl).HandlerFunc(h.GetUserResults)
+	auth.Methods(http.MethodGet).Path(getTestResultsUrl).HandlerFunc(h.GetTestResults)
+	auth.Methods(http.MethodGet).Path(getResultUrl).HandlerFunc(h.GetResult)
+	auth.Methods(http.MethodPut).Path(gradeResultUrl).HandlerFunc(h.GradeAnswers)
+	auth.Methods(http.MethodPost).Path(finalizeResultUrl).HandlerFunc(h.FinalizeResult)
+	auth.Methods(http.MethodPost).Path(banksUrl).HandlerFunc(h.CreateQuestionBank)
+	auth.Methods(http.MethodGet).Path(banksUrl).HandlerFunc(h.GetQuestionBanks)
+	auth.Methods(http.MethodGet).Path(bankUrl).HandlerFunc(h.GetQuestionBank)
+	auth.Methods(http.MethodPut).Path(bankUrl).HandlerFunc(h.UpdateQuestionBank)
+	auth.Methods(http.MethodDelete).Path(bankUrl).HandlerFunc(h.DeleteQuestionBank)
 }
 
 func (h *Handlers) GetResults(w http.ResponseWriter, r *http.Request) {
-	const op = "tests.handlers.GetTest"
+	const op = "tests.handlers.GetResults"
 	log := h.log.With(zap.String("op", op))
 
-	results, err := h.srv.GetResults(r.Context())
+	pageOpt, _ := paginate.OptionsFromContext(r.Context())
+
+	results, total, err := h.srv.GetResults(r.Context(), resultsQuery(pageOpt))
 	if err != nil {
+		if errors.Is(err, testsservice.ErrNoRights) {
+			log.Error("forbidden action", zap.Error(err))
+			ahttp.WriteErrorMessage(w, ahttp.ErrForbidden, "no rights to get results")
+			return
+		}
 		log.Error("failed to get results", zap.Error(err))
 		ahttp.WriteError(w, ahttp.ErrInternal)
 		return
 	}
 
-	ahttp.WriteResponse(w, http.StatusOK, results)
+	ahttp.WriteResponse(w, http.StatusOK, paginate.NewResponse(results, pageOpt, total))
+}
+
+func (h *Handlers) GetUserResults(w http.ResponseWriter, r *http.Request) {
+	const op = "tests.handlers.GetUserResults"
+	log := h.log.With(zap.String("op", op))
+
+	pageOpt, _ := paginate.OptionsFromContext(r.Context())
+
+	results, total, err := h.srv.GetUserResults(r.Context(), resultsQuery(pageOpt))
+	if err != nil {
+		if errors.Is(err, testsservice.ErrNoRights) {
+			log.Error("forbidden action", zap.Error(err))
+			ahttp.WriteErrorMessage(w, ahttp.ErrForbidden, "no rights to get results")
+			return
+		}
+		log.Error("failed to get user results", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInternal)
+		return
+	}
+
+	ahttp.WriteResponse(w, http.StatusOK, paginate.NewResponse(results, pageOpt, total))
+}
+
+func (h *Handlers) GetTestResults(w http.ResponseWriter, r *http.Request) {
+	const op = "tests.handlers.GetTestResults"
+	log := h.log.With(zap.String("op", op))
+
+	testID, ok := mux.Vars(r)["test_id"]
+	if !ok || testID == "" {
+		log.Error("failed to get test id from url path")
+		ahttp.WriteErrorMessage(w, ahttp.ErrNoRequiredValue, "no test id in url path")
+		return
+	}
+
+	pageOpt, _ := paginate.OptionsFromContext(r.Context())
+	query := resultsQuery(pageOpt)
+
+	// Reviewers get results that wait for manual grading with ?pending=true
+	if v := r.URL.Query().Get("pending"); v != "" {
+		pending, err := strconv.ParseBool(v)
+		if err != nil {
+			log.Error("invalid pending filter", zap.Error(err))
+			ahttp.WriteErrorMessage(w, ahttp.ErrFailedValidation, "pending must be boolean")
+			return
+		}
+		query.Pending = &pending
+	}
+
+	results, total, err := h.srv.GetTestResults(r.Context(), testID, query)
+	if err != nil {
+		if errors.Is(err, testsservice.ErrNotFound) {
+			log.Error("test not found", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrNotFound)
+			return
+		}
+		if errors.Is(err, testsservice.ErrNoRights) {
+			log.Error("forbidden action", zap.Error(err))
+			ahttp.WriteErrorMessage(w, ahttp.ErrForbidden, "no rights to get test results")
+			return
+		}
+		log.Error("failed to get test results", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInternal)
+		return
+	}
+
+	ahttp.WriteResponse(w, http.StatusOK, paginate.NewResponse(results, pageOpt, total))
+}
+
+func (h *Handlers) GetResult(w http.ResponseWriter, r *http.Request) {
+	const op = "tests.handlers.GetResult"
+	log := h.log.With(zap.String("op", op))
+
+	resultID, ok := mux.Vars(r)["result_id"]
+	if !ok || resultID == "" {
+		log.Error("failed to get result id from url path")
+		ahttp.WriteErrorMessage(w, ahttp.ErrNoRequiredValue, "no result id in url path")
+		return
+	}
+
+	result, err := h.srv.GetResultByID(r.Context(), resultID)
+	if err != nil {
+		if errors.Is(err, testsservice.ErrNotFound) {
+			log.Error("result not found", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrNotFound)
+			return
+		}
+		if errors.Is(err, testsservice.ErrNoRights) {
+			log.Error("forbidden action", zap.Error(err))
+			ahttp.WriteErrorMessage(w, ahttp.ErrForbidden, "no rights to get result")
+			return
+		}
+		log.Error("failed to get result", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInternal)
+		return
+	}
+
+	ahttp.WriteResponse(w, http.StatusOK, result)
 }
 
 func (h *Handlers) ApplyTest(w http.ResponseWriter, r *http.Request) {
@@ -114,38 +288,288 @@ func (h *Handlers) ApplyTest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	answers := make(map[int]domain.UserAnswerModel)
-	for _, a := range req.UserAnswers {
-		da := a.ToDomain()
-		if _, has := answers[da.QuestionID]; has {
-			log.Error("duplicate answers for question", zap.Int("question_id", da.QuestionID))
-			ahttp.WriteError(w, ahttp.ErrFailedValidation)
+	answers, ok := userAnswersToDomain(req.UserAnswers)
+	if !ok {
+		log.Error("duplicate answers for question")
+		ahttp.WriteError(w, ahttp.ErrFailedValidation)
+		return
+	}
+
+	var attemptID string
+	if req.AttemptID != nil {
+		attemptID = *req.AttemptID
+	}
+
+	result, err := h.srv.ApplyTest(r.Context(), testID, authUser.ID, attemptID, answers)
+	if err != nil {
+		if errors.Is(err, testsservice.ErrNotFound) {
+			log.Error("test or attempt not found", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrNotFound)
+			return
+		}
+		if errors.Is(err, testsservice.ErrTestNotPublished) {
+			log.Error("test is not published", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrTestNotPublished)
+			return
+		}
+		if errors.Is(err, testsservice.ErrNoRights) {
+			log.Error("forbidden action", zap.Error(err))
+			ahttp.WriteErrorMessage(w, ahttp.ErrForbidden, "attempt belongs to other user or test")
+			return
+		}
+		if errors.Is(err, testsservice.ErrAttemptRequired) {
+			log.Error("no attempt for timed test", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrAttemptRequired)
+			return
+		}
+		if errors.Is(err, testsservice.ErrAttemptFinished) {
+			log.Error("attempt already finished", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrAttemptFinished)
+			return
+		}
+		if errors.Is(err, testsservice.ErrTimeLimitExceeded) {
+			log.Error("time limit exceeded", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrTimeLimitExceeded)
+			return
+		}
+		if errors.Is(err, testsservice.ErrAttemptsLimit) {
+			log.Error("attempts limit reached", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrAttemptsLimit)
+			return
+		}
+		if errors.Is(err, testsservice.ErrRetakeCooldown) {
+			log.Error("retake cooldown is not over", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrRetakeCooldown)
 			return
 		}
-		answers[da.QuestionID] = *da
+		if errors.Is(err, testsservice.ErrNoUserAnswer) {
+			log.Error("no answer on required question", zap.Error(err))
+			ahttp.WriteErrorMessage(w, ahttp.ErrNoRequiredValue, "no answer on required question")
+			return
+		}
+		if errors.Is(err, testsservice.ErrFailedTestValidation) {
+			log.Error("invalid user answers", zap.Error(err))
+			ahttp.WriteErrorMessage(w, ahttp.ErrFailedValidation, "invalid user answers")
+			return
+		}
+		log.Error("failed to apply test", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInternal)
+		return
 	}
 
-	if err := h.srv.ApplyTest(r.Context(), testID, authUser.ID, answers); err != nil {
-		log.Error("failed to apply test", zap.Error(err))
+	ahttp.WriteResponse(w, http.StatusOK, result)
+}
+
+func (h *Handlers) StartAttempt(w http.ResponseWriter, r *http.Request) {
+	const op = "tests.handlers.StartAttempt"
+	log := h.log.With(zap.String("op", op))
+
+	testID, ok := mux.Vars(r)["test_id"]
+	if !ok || testID == "" {
+		log.Error("failed to get test id from url path")
+		ahttp.WriteErrorMessage(w, ahttp.ErrNoRequiredValue, "no test id in url path")
+		return
+	}
+
+	attempt, err := h.srv.StartAttempt(r.Context(), testID)
+	if err != nil {
+		if errors.Is(err, testsservice.ErrNotFound) {
+			log.Error("test not found", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrNotFound)
+			return
+		}
+		if errors.Is(err, testsservice.ErrTestNotPublished) {
+			log.Error("test is not published", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrTestNotPublished)
+			return
+		}
+		if errors.Is(err, testsservice.ErrNoRights) {
+			log.Error("forbidden action", zap.Error(err))
+			ahttp.WriteErrorMessage(w, ahttp.ErrForbidden, "no rights to start attempt")
+			return
+		}
+		if errors.Is(err, testsservice.ErrAttemptsLimit) {
+			log.Error("attempts limit reached", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrAttemptsLimit)
+			return
+		}
+		if errors.Is(err, testsservice.ErrRetakeCooldown) {
+			log.Error("retake cooldown is not over", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrRetakeCooldown)
+			return
+		}
+		log.Error("failed to start attempt", zap.Error(err))
 		ahttp.WriteError(w, ahttp.ErrInternal)
 		return
 	}
 
-	ahttp.WriteResponse(w, http.StatusOK, "test was applied")
+	ahttp.WriteResponse(w, http.StatusCreated, attempt)
+}
+
+func (h *Handlers) GetAttemptAnswers(w http.ResponseWriter, r *http.Request) {
+	const op = "tests.handlers.GetAttemptAnswers"
+	log := h.log.With(zap.String("op", op))
+
+	attemptID, ok := mux.Vars(r)["attempt_id"]
+	if !ok || attemptID == "" {
+		log.Error("failed to get attempt id from url path")
+		ahttp.WriteErrorMessage(w, ahttp.ErrNoRequiredValue, "no attempt id in url path")
+		return
+	}
+
+	attempt, err := h.srv.GetAttempt(r.Context(), attemptID)
+	if err != nil {
+		if errors.Is(err, testsservice.ErrNotFound) {
+			log.Error("attempt not found", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrNotFound)
+			return
+		}
+		if errors.Is(err, testsservice.ErrNoRights) {
+			log.Error("forbidden action", zap.Error(err))
+			ahttp.WriteErrorMessage(w, ahttp.ErrForbidden, "attempt belongs to other user")
+			return
+		}
+		log.Error("failed to get attempt", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInternal)
+		return
+	}
+
+	ahttp.WriteResponse(w, http.StatusOK, attempt)
+}
+
+func (h *Handlers) SaveAttemptAnswers(w http.ResponseWriter, r *http.Request) {
+	const op = "tests.handlers.SaveAttemptAnswers"
+	log := h.log.With(zap.String("op", op))
+
+	attemptID, ok := mux.Vars(r)["attempt_id"]
+	if !ok || attemptID == "" {
+		log.Error("failed to get attempt id from url path")
+		ahttp.WriteErrorMessage(w, ahttp.ErrNoRequiredValue, "no attempt id in url path")
+		return
+	}
+
+	var req SaveAttemptAnswersRequest
+	if err := httputil.UnmarshalJSONBody(r.Body, &req); err != nil {
+		log.Error("failed to parse body", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInvalidJSONBody)
+		return
+	}
+
+	if ok := h.val.Validate(w, req); !ok {
+		log.Error("interrupting request due to failed validation")
+		return
+	}
+
+	answers, ok := userAnswersToDomain(req.UserAnswers)
+	if !ok {
+		log.Error("duplicate answers for question")
+		ahttp.WriteError(w, ahttp.ErrFailedValidation)
+		return
+	}
+
+	attempt, err := h.srv.SaveAttemptAnswers(r.Context(), attemptID, answers)
+	if err != nil {
+		if errors.Is(err, testsservice.ErrNotFound) {
+			log.Error("attempt or test not found", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrNotFound)
+			return
+		}
+		if errors.Is(err, testsservice.ErrTestNotPublished) {
+			log.Error("test is not published", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrTestNotPublished)
+			return
+		}
+		if errors.Is(err, testsservice.ErrNoRights) {
+			log.Error("forbidden action", zap.Error(err))
+			ahttp.WriteErrorMessage(w, ahttp.ErrForbidden, "attempt belongs to other user")
+			return
+		}
+		if errors.Is(err, testsservice.ErrAttemptFinished) {
+			log.Error("attempt already finished", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrAttemptFinished)
+			return
+		}
+		if errors.Is(err, testsservice.ErrTimeLimitExceeded) {
+			log.Error("time limit exceeded", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrTimeLimitExceeded)
+			return
+		}
+		if errors.Is(err, testsservice.ErrFailedTestValidation) {
+			log.Error("invalid user answers", zap.Error(err))
+			ahttp.WriteErrorMessage(w, ahttp.ErrFailedValidation, "invalid user answers")
+			return
+		}
+		log.Error("failed to save attempt answers", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInternal)
+		return
+	}
+
+	ahttp.WriteResponse(w, http.StatusOK, attempt)
 }
 
 func (h *Handlers) GetTests(w http.ResponseWriter, r *http.Request) {
 	const op = "tests.handlers.GetTests"
 	log := h.log.With(zap.String("op", op))
 
-	tests, err := h.srv.GetTests(r.Context())
+	pageOpt, _ := paginate.OptionsFromContext(r.Context())
+	sortOpt, _ := sort.OptionsFromContext(r.Context())
+
+	var req GetTestsRequest
+	if err := req.FromQuery(r.URL.Query()); err != nil {
+		log.Error("failed to parse query", zap.Error(err))
+		ahttp.WriteErrorMessage(w, ahttp.ErrFailedValidation, err.Error())
+		return
+	}
+
+	if sortOpt.Field != "" && !slice.Contains(domain.TestsSortFields, sortOpt.Field) {
+		log.Error("invalid sort field", zap.String("field", sortOpt.Field))
+		ahttp.WriteErrorMessage(w, ahttp.ErrFailedValidation, "invalid sort field")
+		return
+	}
+
+	tests, total, err := h.srv.GetTests(r.Context(), *req.ToDomain(pageOpt, sortOpt))
 	if err != nil {
 		log.Error("failed to get tests", zap.Error(err))
 		ahttp.WriteError(w, ahttp.ErrInternal)
 		return
 	}
 
-	ahttp.WriteResponse(w, http.StatusOK, tests)
+	ahttp.WriteResponse(w, http.StatusOK, paginate.NewResponse(tests, pageOpt, total))
+}
+
+func (h *Handlers) SearchTests(w http.ResponseWriter, r *http.Request) {
+	const op = "tests.handlers.SearchTests"
+	log := h.log.With(zap.String("op", op))
+
+	text := strings.TrimSpace(r.URL.Query().Get("q"))
+	if text == "" {
+		log.Error("no search text in query")
+		ahttp.WriteErrorMessage(w, ahttp.ErrNoRequiredValue, "no search text in query")
+		return
+	}
+
+	pageOpt, _ := paginate.OptionsFromContext(r.Context())
+
+	var req GetTestsRequest
+	if err := req.FromQuery(r.URL.Query()); err != nil {
+		log.Error("failed to parse query", zap.Error(err))
+		ahttp.WriteErrorMessage(w, ahttp.ErrFailedValidation, err.Error())
+		return
+	}
+
+	tests, total, err := h.srv.SearchTests(r.Context(), text, *req.ToDomain(pageOpt, sort.Options{}))
+	if err != nil {
+		if errors.Is(err, testsservice.ErrEmptySearchText) {
+			log.Error("empty search text", zap.Error(err))
+			ahttp.WriteErrorMessage(w, ahttp.ErrNoRequiredValue, "no search text in query")
+			return
+		}
+		log.Error("failed to search tests", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInternal)
+		return
+	}
+
+	ahttp.WriteResponse(w, http.StatusOK, paginate.NewResponse(tests, pageOpt, total))
 }
 
 func (h *Handlers) GetTest(w http.ResponseWriter, r *http.Request) {
@@ -182,6 +606,7 @@ func (h *Handlers) GetTest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ahttp.SetETag(w, test.Revision)
 	ahttp.WriteResponse(w, http.StatusOK, test)
 }
 
@@ -215,6 +640,98 @@ func (h *Handlers) DeleteTest(w http.ResponseWriter, r *http.Request) {
 	ahttp.WriteResponse(w, http.StatusOK, "test was deleted")
 }
 
+func (h *Handlers) PublishTest(w http.ResponseWriter, r *http.Request) {
+	const op = "tests.handlers.PublishTest"
+	log := h.log.With(zap.String("op", op))
+
+	testID, ok := mux.Vars(r)["test_id"]
+	if !ok || testID == "" {
+		log.Error("failed to get test id from url path")
+		ahttp.WriteErrorMessage(w, ahttp.ErrNoRequiredValue, "no test id in url path")
+		return
+	}
+	revision, err := ahttp.IfMatch(r)
+	if err != nil {
+		log.Error("invalid If-Match header", zap.Error(err))
+		ahttp.WriteErrorMessage(w, ahttp.ErrFailedValidation, "invalid If-Match header")
+		return
+	}
+
+	if err := h.srv.PublishTest(r.Context(), testID, revision); err != nil {
+		if errors.Is(err, testsservice.ErrNotFound) {
+			log.Error("test not found", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrNotFound)
+			return
+		}
+		if errors.Is(err, testsservice.ErrNoRights) {
+			log.Error("forbidden action", zap.Error(err))
+			ahttp.WriteErrorMessage(w, ahttp.ErrForbidden, "no rights to publish test")
+			return
+		}
+		if errors.Is(err, testsservice.ErrConflict) {
+			log.Error("test was changed concurrently", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrRevisionConflict)
+			return
+		}
+		if errors.Is(err, testsservice.ErrInvalidTransition) {
+			log.Error("invalid status transition", zap.Error(err))
+			ahttp.WriteErrorMessage(w, ahttp.ErrInvalidTransition, "test can't be published from its current status")
+			return
+		}
+		log.Error("failed to publish test", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInternal)
+		return
+	}
+
+	ahttp.WriteResponse(w, http.StatusOK, "test was published")
+}
+
+func (h *Handlers) ArchiveTest(w http.ResponseWriter, r *http.Request) {
+	const op = "tests.handlers.ArchiveTest"
+	log := h.log.With(zap.String("op", op))
+
+	testID, ok := mux.Vars(r)["test_id"]
+	if !ok || testID == "" {
+		log.Error("failed to get test id from url path")
+		ahttp.WriteErrorMessage(w, ahttp.ErrNoRequiredValue, "no test id in url path")
+		return
+	}
+	revision, err := ahttp.IfMatch(r)
+	if err != nil {
+		log.Error("invalid If-Match header", zap.Error(err))
+		ahttp.WriteErrorMessage(w, ahttp.ErrFailedValidation, "invalid If-Match header")
+		return
+	}
+
+	if err := h.srv.ArchiveTest(r.Context(), testID, revision); err != nil {
+		if errors.Is(err, testsservice.ErrNotFound) {
+			log.Error("test not found", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrNotFound)
+			return
+		}
+		if errors.Is(err, testsservice.ErrNoRights) {
+			log.Error("forbidden action", zap.Error(err))
+			ahttp.WriteErrorMessage(w, ahttp.ErrForbidden, "no rights to archive test")
+			return
+		}
+		if errors.Is(err, testsservice.ErrConflict) {
+			log.Error("test was changed concurrently", zap.Error(err))
+			ahttp.WriteError(w, ahttp.ErrRevisionConflict)
+			return
+		}
+		if errors.Is(err, testsservice.ErrInvalidTransition) {
+			log.Error("invalid status transition", zap.Error(err))
+			ahttp.WriteErrorMessage(w, ahttp.ErrInvalidTransition, "test can't be archived from its current status")
+			return
+		}
+		log.Error("failed to archive test", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInternal)
+		return
+	}
+
+	ahttp.WriteResponse(w, http.StatusOK, "test was archived")
+}
+
 func (h *Handlers) CreateTest(w http.ResponseWriter, r *http.Request) {
 	const op = "tests.handlers.CreateTest"
 	log := h.log.With(zap.String("op", op))
@@ -232,7 +749,7 @@ func (h *Handlers) CreateTest(w http.ResponseWriter, r *http.Request) {
 	}
 
 	authUser, ok := user.AuthUserFromContext(r.Context())
-	if !ok || (authUser.ID != *req.Test.CreatorID && slice.MaxInt(authUser.Permissions) < user.Admin) {
+	if !ok || (authUser.ID != *req.Test.CreatorID && !authUser.HasAny(user.Admin)) {
 		log.Error("forbidden action")
 		ahttp.WriteError(w, ahttp.ErrForbidden)
 		return
@@ -269,9 +786,31 @@ func (h *Handlers) UpdateTestPreview(w http.ResponseWriter, r *http.Request) {
 		ahttp.WriteErrorMessage(w, ahttp.ErrNoRequiredValue, "no test id in url path")
 		return
 	}
+	revision, err := ahttp.IfMatch(r)
+	if err != nil {
+		log.Error("invalid If-Match header", zap.Error(err))
+		ahttp.WriteErrorMessage(w, ahttp.ErrFailedValidation, "invalid If-Match header")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Error("failed to read body", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInvalidJSONBody)
+		return
+	}
 
+	// Body is a merge patch, typed request is decoded from the same body only to validate given values
+	var patch domain.MergePatch
+	if err := json.Unmarshal(body, &patch); err != nil || patch == nil {
+		log.Error("body is not a merge patch object", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInvalidJSONBody)
+		return
+	}
 	var req UpdateTestPreviewRequest
-	if err := httputil.UnmarshalJSONBody(r.Body, &req); err != nil {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
 		log.Error("failed to parse body", zap.Error(err))
 		ahttp.WriteError(w, ahttp.ErrInvalidJSONBody)
 		return
@@ -282,7 +821,11 @@ func (h *Handlers) UpdateTestPreview(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.srv.UpdateTest(r.Context(), testID, *req.ToDomain()); err != nil {
+	if err := h.srv.UpdateTest(r.Context(), testID, patch, revision); err != nil {
+		if errors.Is(err, testsservice.ErrFailedTestValidation) {
+			ahttp.WriteErrorMessage(w, ahttp.ErrFailedValidation, "only title, short_text, long_text, main_image and tags can be changed, title and texts can't be deleted")
+			return
+		}
 		if errors.Is(err, testsservice.ErrNotFound) {
 			ahttp.WriteError(w, ahttp.ErrNotFound)
 			return
@@ -291,6 +834,10 @@ func (h *Handlers) UpdateTestPreview(w http.ResponseWriter, r *http.Request) {
 			ahttp.WriteErrorMessage(w, ahttp.ErrForbidden, "no rights to update test")
 			return
 		}
+		if errors.Is(err, testsservice.ErrConflict) {
+			ahttp.WriteError(w, ahttp.ErrRevisionConflict)
+			return
+		}
 		ahttp.WriteError(w, ahttp.ErrInternal)
 		return
 	}
@@ -119,6 +119,11 @@ func (v *TestValidator) QuestionStructLevelValidation(sl validator.StructLevel)
 		sl.ReportError(q.LongText, "LongText", "LongText", http.ErrTagLowerThanMinLimit, "")
 	}
 
+	// Bank question can't be partially overridden in the test
+	if q.BankRef != nil && (q.Type != nil || q.ShortText != nil || q.LongText != nil || q.Variants != nil || q.Answer != nil || q.Scoring != nil) {
+		sl.ReportError(q.BankRef, "BankRef", "BankRef", "excluded_with", "")
+	}
+
 	if q.ShortText != nil && len(*q.ShortText) > int(v.cfg.Service.Questions.ShortTextMaxLength) {
 		sl.ReportError(q.ShortText, "ShortText", "ShortText", http.ErrTagHigherThanMaxLimit, "")
 	}
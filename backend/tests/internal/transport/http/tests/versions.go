@@ -0,0 +1,279 @@
+package testshandlers
+
+import (
+	"errors"
+	testsservice "github.com/coddmeistr/quizzify/backend/tests/internal/service/tests"
+	ahttp "github.com/coddmeistr/quizzify/backend/tests/internal/transport/http"
+	"github.com/coddmeistr/quizzify/backend/tests/pkg/httputil"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handlers) ReplaceTest(w http.ResponseWriter, r *http.Request) {
+	const op = "tests.handlers.ReplaceTest"
+	log := h.log.With(zap.String("op", op))
+
+	testID, ok := mux.Vars(r)["test_id"]
+	if !ok || testID == "" {
+		log.Error("failed to get test id from url path")
+		ahttp.WriteErrorMessage(w, ahttp.ErrNoRequiredValue, "no test id in url path")
+		return
+	}
+	revision, err := ahttp.IfMatch(r)
+	if err != nil {
+		log.Error("invalid If-Match header", zap.Error(err))
+		ahttp.WriteErrorMessage(w, ahttp.ErrFailedValidation, "invalid If-Match header")
+		return
+	}
+
+	var req ReplaceTestRequest
+	if err := httputil.UnmarshalJSONBody(r.Body, &req); err != nil {
+		log.Error("failed to parse body", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInvalidJSONBody)
+		return
+	}
+
+	if ok := h.val.Validate(w, req); !ok {
+		log.Error("interrupting request due to failed validation")
+		return
+	}
+
+	test, err := h.srv.ReplaceTest(r.Context(), testID, *req.Test.ToDomain(), revision)
+	if err != nil {
+		h.writeEditError(w, log, err)
+		return
+	}
+
+	ahttp.SetETag(w, test.Revision)
+	ahttp.WriteResponse(w, http.StatusOK, test)
+}
+
+func (h *Handlers) AddQuestion(w http.ResponseWriter, r *http.Request) {
+	const op = "tests.handlers.AddQuestion"
+	log := h.log.With(zap.String("op", op))
+
+	testID, ok := mux.Vars(r)["test_id"]
+	if !ok || testID == "" {
+		log.Error("failed to get test id from url path")
+		ahttp.WriteErrorMessage(w, ahttp.ErrNoRequiredValue, "no test id in url path")
+		return
+	}
+	revision, err := ahttp.IfMatch(r)
+	if err != nil {
+		log.Error("invalid If-Match header", zap.Error(err))
+		ahttp.WriteErrorMessage(w, ahttp.ErrFailedValidation, "invalid If-Match header")
+		return
+	}
+
+	var req Question
+	if err := httputil.UnmarshalJSONBody(r.Body, &req); err != nil {
+		log.Error("failed to parse body", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInvalidJSONBody)
+		return
+	}
+
+	if ok := h.val.Validate(w, req); !ok {
+		log.Error("interrupting request due to failed validation")
+		return
+	}
+
+	test, err := h.srv.AddQuestion(r.Context(), testID, *req.ToDomain(), revision)
+	if err != nil {
+		h.writeEditError(w, log, err)
+		return
+	}
+
+	ahttp.SetETag(w, test.Revision)
+	ahttp.WriteResponse(w, http.StatusCreated, test)
+}
+
+func (h *Handlers) UpdateQuestion(w http.ResponseWriter, r *http.Request) {
+	const op = "tests.handlers.UpdateQuestion"
+	log := h.log.With(zap.String("op", op))
+
+	testID, ok := mux.Vars(r)["test_id"]
+	if !ok || testID == "" {
+		log.Error("failed to get test id from url path")
+		ahttp.WriteErrorMessage(w, ahttp.ErrNoRequiredValue, "no test id in url path")
+		return
+	}
+	revision, err := ahttp.IfMatch(r)
+	if err != nil {
+		log.Error("invalid If-Match header", zap.Error(err))
+		ahttp.WriteErrorMessage(w, ahttp.ErrFailedValidation, "invalid If-Match header")
+		return
+	}
+	questionID, err := strconv.Atoi(mux.Vars(r)["question_id"])
+	if err != nil {
+		log.Error("failed to get question id from url path", zap.Error(err))
+		ahttp.WriteErrorMessage(w, ahttp.ErrFailedValidation, "invalid question id in url path")
+		return
+	}
+
+	var req Question
+	if err := httputil.UnmarshalJSONBody(r.Body, &req); err != nil {
+		log.Error("failed to parse body", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInvalidJSONBody)
+		return
+	}
+
+	if ok := h.val.Validate(w, req); !ok {
+		log.Error("interrupting request due to failed validation")
+		return
+	}
+	if req.ID != questionID {
+		log.Error("question id in body doesn't match url path")
+		ahttp.WriteErrorMessage(w, ahttp.ErrFailedValidation, "question id in body doesn't match url path")
+		return
+	}
+
+	test, err := h.srv.UpdateQuestion(r.Context(), testID, *req.ToDomain(), revision)
+	if err != nil {
+		h.writeEditError(w, log, err)
+		return
+	}
+
+	ahttp.SetETag(w, test.Revision)
+	ahttp.WriteResponse(w, http.StatusOK, test)
+}
+
+func (h *Handlers) RemoveQuestion(w http.ResponseWriter, r *http.Request) {
+	const op = "tests.handlers.RemoveQuestion"
+	log := h.log.With(zap.String("op", op))
+
+	testID, ok := mux.Vars(r)["test_id"]
+	if !ok || testID == "" {
+		log.Error("failed to get test id from url path")
+		ahttp.WriteErrorMessage(w, ahttp.ErrNoRequiredValue, "no test id in url path")
+		return
+	}
+	revision, err := ahttp.IfMatch(r)
+	if err != nil {
+		log.Error("invalid If-Match header", zap.Error(err))
+		ahttp.WriteErrorMessage(w, ahttp.ErrFailedValidation, "invalid If-Match header")
+		return
+	}
+	questionID, err := strconv.Atoi(mux.Vars(r)["question_id"])
+	if err != nil {
+		log.Error("failed to get question id from url path", zap.Error(err))
+		ahttp.WriteErrorMessage(w, ahttp.ErrFailedValidation, "invalid question id in url path")
+		return
+	}
+
+	test, err := h.srv.RemoveQuestion(r.Context(), testID, questionID, revision)
+	if err != nil {
+		h.writeEditError(w, log, err)
+		return
+	}
+
+	ahttp.SetETag(w, test.Revision)
+	ahttp.WriteResponse(w, http.StatusOK, test)
+}
+
+func (h *Handlers) ReorderQuestions(w http.ResponseWriter, r *http.Request) {
+	const op = "tests.handlers.ReorderQuestions"
+	log := h.log.With(zap.String("op", op))
+
+	testID, ok := mux.Vars(r)["test_id"]
+	if !ok || testID == "" {
+		log.Error("failed to get test id from url path")
+		ahttp.WriteErrorMessage(w, ahttp.ErrNoRequiredValue, "no test id in url path")
+		return
+	}
+	revision, err := ahttp.IfMatch(r)
+	if err != nil {
+		log.Error("invalid If-Match header", zap.Error(err))
+		ahttp.WriteErrorMessage(w, ahttp.ErrFailedValidation, "invalid If-Match header")
+		return
+	}
+
+	var req ReorderQuestionsRequest
+	if err := httputil.UnmarshalJSONBody(r.Body, &req); err != nil {
+		log.Error("failed to parse body", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInvalidJSONBody)
+		return
+	}
+
+	if ok := h.val.Validate(w, req); !ok {
+		log.Error("interrupting request due to failed validation")
+		return
+	}
+
+	test, err := h.srv.ReorderQuestions(r.Context(), testID, req.QuestionIDs, revision)
+	if err != nil {
+		h.writeEditError(w, log, err)
+		return
+	}
+
+	ahttp.SetETag(w, test.Revision)
+	ahttp.WriteResponse(w, http.StatusOK, test)
+}
+
+func (h *Handlers) GetTestVersions(w http.ResponseWriter, r *http.Request) {
+	const op = "tests.handlers.GetTestVersions"
+	log := h.log.With(zap.String("op", op))
+
+	testID, ok := mux.Vars(r)["test_id"]
+	if !ok || testID == "" {
+		log.Error("failed to get test id from url path")
+		ahttp.WriteErrorMessage(w, ahttp.ErrNoRequiredValue, "no test id in url path")
+		return
+	}
+
+	versions, err := h.srv.GetTestVersions(r.Context(), testID)
+	if err != nil {
+		h.writeEditError(w, log, err)
+		return
+	}
+
+	ahttp.WriteResponse(w, http.StatusOK, versions)
+}
+
+func (h *Handlers) GetTestVersion(w http.ResponseWriter, r *http.Request) {
+	const op = "tests.handlers.GetTestVersion"
+	log := h.log.With(zap.String("op", op))
+
+	testID, ok := mux.Vars(r)["test_id"]
+	if !ok || testID == "" {
+		log.Error("failed to get test id from url path")
+		ahttp.WriteErrorMessage(w, ahttp.ErrNoRequiredValue, "no test id in url path")
+		return
+	}
+	version, err := strconv.Atoi(mux.Vars(r)["version"])
+	if err != nil {
+		log.Error("failed to get version from url path", zap.Error(err))
+		ahttp.WriteErrorMessage(w, ahttp.ErrFailedValidation, "invalid version in url path")
+		return
+	}
+
+	v, err := h.srv.GetTestVersion(r.Context(), testID, version)
+	if err != nil {
+		h.writeEditError(w, log, err)
+		return
+	}
+
+	ahttp.WriteResponse(w, http.StatusOK, v)
+}
+
+// writeEditError maps errors of test editing and its history to http errors
+func (h *Handlers) writeEditError(w http.ResponseWriter, log *zap.Logger, err error) {
+	switch {
+	case errors.Is(err, testsservice.ErrNotFound):
+		log.Error("test, question or version not found", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrNotFound)
+	case errors.Is(err, testsservice.ErrNoRights):
+		log.Error("forbidden action", zap.Error(err))
+		ahttp.WriteErrorMessage(w, ahttp.ErrForbidden, "no rights to edit test")
+	case errors.Is(err, testsservice.ErrConflict):
+		log.Error("test was changed concurrently", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrRevisionConflict)
+	case errors.Is(err, testsservice.ErrFailedTestValidation):
+		log.Error("invalid test structure", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInvalidTestStructure)
+	default:
+		log.Error("failed to edit test", zap.Error(err))
+		ahttp.WriteError(w, ahttp.ErrInternal)
+	}
+}
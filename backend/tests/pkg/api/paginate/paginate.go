@@ -16,7 +16,24 @@ type Options struct {
 	PerPage int
 }
 
-func Middleware(defaultPage int, defaultPerPage int) func(next http.Handler) http.Handler {
+// Response is a pagination envelope for list payloads
+type Response struct {
+	Items   any   `json:"items"`
+	Page    int   `json:"page"`
+	PerPage int   `json:"per_page"`
+	Total   int64 `json:"total"`
+}
+
+func NewResponse(items any, opt Options, total int64) Response {
+	return Response{
+		Items:   items,
+		Page:    opt.Page,
+		PerPage: opt.PerPage,
+		Total:   total,
+	}
+}
+
+func Middleware(defaultPage int, defaultPerPage int, maxPerPage int) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			pageInQuery := r.URL.Query().Get("page")
@@ -28,7 +45,7 @@ func Middleware(defaultPage int, defaultPerPage int) func(next http.Handler) htt
 			} else {
 				var err error
 				page, err = strconv.Atoi(pageInQuery)
-				if err != nil {
+				if err != nil || page < 1 {
 					api.WriteErrorMessage(w, http.StatusBadRequest, "INVALID_PAGE_NUMBER", "invalid page number")
 					return
 				}
@@ -40,7 +57,7 @@ func Middleware(defaultPage int, defaultPerPage int) func(next http.Handler) htt
 			} else {
 				var err error
 				perPage, err = strconv.Atoi(perPageInQuery)
-				if err != nil {
+				if err != nil || perPage < 1 || perPage > maxPerPage {
 					api.WriteErrorMessage(w, http.StatusBadRequest, "INVALID_PER_PAGE", "invalid per page number")
 					return
 				}
@@ -58,3 +75,8 @@ func Middleware(defaultPage int, defaultPerPage int) func(next http.Handler) htt
 		})
 	}
 }
+
+func OptionsFromContext(ctx context.Context) (Options, bool) {
+	options, ok := ctx.Value(OptionsContextKey).(Options)
+	return options, ok
+}
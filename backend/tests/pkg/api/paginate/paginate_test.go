@@ -0,0 +1,83 @@
+package paginate
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	const (
+		defaultPage    = 1
+		defaultPerPage = 5
+		maxPerPage     = 100
+	)
+
+	tests := []struct {
+		name        string
+		query       string
+		wantStatus  int
+		wantOptions Options
+	}{
+		{
+			name:        "defaults",
+			query:       "",
+			wantStatus:  http.StatusOK,
+			wantOptions: Options{Page: defaultPage, PerPage: defaultPerPage},
+		},
+		{
+			name:        "explicit values",
+			query:       "page=3&per_page=20",
+			wantStatus:  http.StatusOK,
+			wantOptions: Options{Page: 3, PerPage: 20},
+		},
+		{
+			name:        "max per page",
+			query:       "per_page=100",
+			wantStatus:  http.StatusOK,
+			wantOptions: Options{Page: defaultPage, PerPage: maxPerPage},
+		},
+		{
+			name:       "per page above max",
+			query:      "per_page=101",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "zero per page",
+			query:      "per_page=0",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "zero page",
+			query:      "page=0",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "negative page",
+			query:      "page=-1",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "not a number",
+			query:      "page=first",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Options
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = OptionsFromContext(r.Context())
+			})
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/tests?"+tt.query, nil)
+
+			Middleware(defaultPage, defaultPerPage, maxPerPage)(next).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantOptions, got)
+		})
+	}
+}
@@ -29,10 +29,10 @@ func Middleware(defaultSortField string, defaultSortOrder string) func(next http
 			}
 
 			if sortOrder == "" {
-				sortOrder = defaultSortOrder
+				sortOrder = strings.ToLower(defaultSortOrder)
 			} else {
-				upperSortOrder := strings.ToUpper(sortOrder)
-				if upperSortOrder != ASC && upperSortOrder != DESC {
+				sortOrder = strings.ToLower(sortOrder)
+				if sortOrder != ASC && sortOrder != DESC {
 					api.WriteErrorMessage(w, http.StatusBadRequest, "INVALID_SORT_ORDER", "invalid sort order")
 					return
 				}
@@ -49,3 +49,8 @@ func Middleware(defaultSortField string, defaultSortOrder string) func(next http
 		})
 	}
 }
+
+func OptionsFromContext(ctx context.Context) (Options, bool) {
+	options, ok := ctx.Value(OptionsContextKey).(Options)
+	return options, ok
+}
@@ -0,0 +1,69 @@
+package sort
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		defaultOrder string
+		wantStatus   int
+		wantOptions  Options
+	}{
+		{
+			name:         "defaults",
+			query:        "",
+			defaultOrder: "DESC",
+			wantStatus:   http.StatusOK,
+			wantOptions:  Options{Field: "id", Order: DESC},
+		},
+		{
+			name:         "lowercase order",
+			query:        "sort_by=title&sort_order=asc",
+			defaultOrder: DESC,
+			wantStatus:   http.StatusOK,
+			wantOptions:  Options{Field: "title", Order: ASC},
+		},
+		{
+			name:         "uppercase order",
+			query:        "sort_order=DESC",
+			defaultOrder: ASC,
+			wantStatus:   http.StatusOK,
+			wantOptions:  Options{Field: "id", Order: DESC},
+		},
+		{
+			name:         "mixed case order",
+			query:        "sort_order=Asc",
+			defaultOrder: DESC,
+			wantStatus:   http.StatusOK,
+			wantOptions:  Options{Field: "id", Order: ASC},
+		},
+		{
+			name:         "invalid order",
+			query:        "sort_order=up",
+			defaultOrder: DESC,
+			wantStatus:   http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Options
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = OptionsFromContext(r.Context())
+			})
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/tests?"+tt.query, nil)
+
+			Middleware("id", tt.defaultOrder)(next).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantOptions, got)
+		})
+	}
+}
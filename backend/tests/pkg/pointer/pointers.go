@@ -10,6 +10,10 @@ func Int[T constraints.Integer](v T) *T {
 	return &v
 }
 
+func Float[T constraints.Float](v T) *T {
+	return &v
+}
+
 func String(v string) *string {
 	return &v
 }
@@ -0,0 +1,51 @@
+package strs
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	type args struct {
+		a string
+		b string
+	}
+	tests := []struct {
+		name string
+		args args
+		want int
+	}{
+		{
+			name: "equal",
+			args: args{a: "paris", b: "paris"},
+			want: 0,
+		},
+		{
+			name: "empty",
+			args: args{a: "", b: "paris"},
+			want: 5,
+		},
+		{
+			name: "substitution",
+			args: args{a: "paris", b: "parys"},
+			want: 1,
+		},
+		{
+			name: "insertion and deletion",
+			args: args{a: "kitten", b: "sitting"},
+			want: 3,
+		},
+		{
+			name: "unicode",
+			args: args{a: "москва", b: "масква"},
+			want: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Levenshtein(tt.args.a, tt.args.b)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
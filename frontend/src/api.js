@@ -13,19 +13,59 @@ axios.interceptors.response.use(function (response) {
     return Promise.reject(error);
 });
 
+// SSO paths which don't need the access token, so their failures don't cause token refresh
+const noRefreshPaths = ["/sso/login", "/sso/register", "/sso/refresh", "/sso/logout"];
+
+function needsRefresh(config) {
+    return config && config.headers && config.headers.Authorization && !noRefreshPaths.includes(config.url);
+}
+
 export function getAxios(){
-    return axios.create({
+    const instance = axios.create({
         baseURL: "http://"+BackendHost
     });
+
+    // Expired token is refreshed before the request is sent
+    instance.interceptors.request.use(function (config) {
+        if (!needsRefresh(config)) return config;
+        return useStore().dispatch("auth/ensureToken")
+            .then((token) => {
+                config.headers.Authorization = `Bearer `+token;
+                return config;
+            })
+            .catch(() => config);
+    });
+
+    // Token rejected by the server is refreshed once and the request is repeated
+    // If the session can't be refreshed, user is logged out
+    instance.interceptors.response.use(undefined, function (error) {
+        const config = error.config;
+        if (!needsRefresh(config) || config.retried || !error.response || error.response.status !== 401) {
+            return Promise.reject(error);
+        }
+        config.retried = true;
+        const store = useStore();
+        return store.dispatch("auth/refresh")
+            .then((token) => {
+                config.headers.Authorization = `Bearer `+token;
+                return instance(config);
+            })
+            .catch(() => {
+                store.dispatch("auth/logout");
+                return Promise.reject(error);
+            });
+    });
+
+    return instance;
 }
 
 export function getAuthConfig(){
     let store = useStore();
-    let userdata = store.getters["auth/userdata"];
-    if (!userdata || !userdata.userId) return {};
+    let token = store.getters["auth/token"];
+    if (!token) return {};
     return {
         headers: {
-            'Auth-User-Info': JSON.stringify({id: parseInt(userdata.userId), permissions: userdata.permissions})
+            Authorization: `Bearer `+token,
         }
     }
 }
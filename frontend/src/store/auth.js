@@ -3,11 +3,29 @@ import Cookies from "js-cookie";
 import router from "@/router";
 
 const COOKIES_NAME = "quizzify-token";
+const REFRESH_COOKIES_NAME = "quizzify-refresh-token";
+
+// Token is refreshed a bit before it expires, so requests don't fail on the way
+const TOKEN_EXPIRY_LEEWAY = 30e3;
+
+// Refresh token can be used only once, so concurrent requests wait for the same refresh
+let refreshing = null;
+
+// tokenExpired checks exp claim of the token
+function tokenExpired(token) {
+  try {
+    const payload = JSON.parse(atob(token.split(".")[1].replace(/-/g, "+").replace(/_/g, "/")));
+    return payload.exp * 1000 < Date.now() + TOKEN_EXPIRY_LEEWAY;
+  } catch (e) {
+    return true;
+  }
+}
 
 export default {
   namespaced: true,
   state: {
     token: "",
+    refreshToken: "",
     userdata: {},
     appURL: "",
     accounts: []
@@ -20,6 +38,13 @@ export default {
       Cookies.set(COOKIES_NAME, token, { expires });
       // document.cookie = `${COOKIES_NAME}=${token}; path=/; expires=${expires}`;
     },
+    setRefreshToken(state, token) {
+      // Refresh token lives as long as the session in SSO
+      const expires = new Date(Date.now() + 2592e6);
+
+      state.refreshToken = token;
+      Cookies.set(REFRESH_COOKIES_NAME, token, { expires });
+    },
     setUserdata(state, data) {
       state.userdata = data;
     },
@@ -31,9 +56,9 @@ export default {
     }
   },
   actions: {
-    deleteAccount(_, id) {
+    deleteAccount({ state }, id) {
       return new Promise((resolve, reject) => {
-        getAxios().delete("/sso/account?id="+id)
+        getAxios().delete("/sso/account?id="+id, { headers: { Authorization: `Bearer `+state.token } })
             .then((response) => {
               resolve(response);
             })
@@ -42,9 +67,9 @@ export default {
             });
       });
     },
-    accountsList({ commit }) {
+    accountsList({ commit, state }) {
       return new Promise((resolve, reject) => {
-        getAxios().get("/sso/accounts")
+        getAxios().get("/sso/accounts", { headers: { Authorization: `Bearer `+state.token } })
             .then((response) => {
               commit("setAccounts", response.data.accounts);
               resolve(response);
@@ -63,6 +88,7 @@ export default {
         })
             .then((response) => {
               commit("setToken", response.data.token);
+              commit("setRefreshToken", response.data.refreshToken);
               resolve(response);
             })
             .catch((error) => {
@@ -86,14 +112,47 @@ export default {
             });
       });
     },
-    logout({ commit }) {
+    refresh({ commit, state }) {
+      if (refreshing) {
+        return refreshing;
+      }
+      const refreshToken = state.refreshToken || Cookies.get(REFRESH_COOKIES_NAME);
+      if (!refreshToken) {
+        return Promise.reject("no refresh token");
+      }
+      refreshing = getAxios().post("/sso/refresh", { refresh_token: refreshToken })
+          .then((response) => {
+            commit("setToken", response.data.token);
+            commit("setRefreshToken", response.data.refreshToken);
+            return response.data.token;
+          })
+          .finally(() => {
+            refreshing = null;
+          });
+      return refreshing;
+    },
+    // ensureToken returns the token, refreshed if it is expired
+    ensureToken({ dispatch, state }) {
+      if (state.token && !tokenExpired(state.token)) {
+        return Promise.resolve(state.token);
+      }
+      return dispatch("refresh");
+    },
+    logout({ commit, state }) {
+      // Session is ended in SSO, access token stays valid until it expires, so it's dropped here
+      const refreshToken = state.refreshToken || Cookies.get(REFRESH_COOKIES_NAME);
+      if (refreshToken) {
+        getAxios().post("/sso/logout", { refresh_token: refreshToken }).catch(() => {});
+      }
       commit("setToken", "");
+      commit("setRefreshToken", "");
       commit("setUserdata", {});
       Cookies.remove(COOKIES_NAME);
+      Cookies.remove(REFRESH_COOKIES_NAME);
       router.push({ name: "Login" });
     },
 
-    fetchUserData({commit}, {token}) {
+    fetchUserData({commit, dispatch, state}, {token}) {
       return new Promise((resolve, reject) => {
           if (token === undefined || token === "") {
               token = Cookies.get(COOKIES_NAME);
@@ -104,12 +163,20 @@ export default {
                   return
               }
           }
-        let config = {
-          headers: {
-            Authorization: `Bearer `+token,
+          if (!state.refreshToken && Cookies.get(REFRESH_COOKIES_NAME)) {
+              commit("setRefreshToken", Cookies.get(REFRESH_COOKIES_NAME))
           }
-        }
-        getAxios().get(`/sso/account?token=${token}`, config)
+        // Expired token is refreshed before the account is requested with it
+        dispatch("ensureToken")
+            .catch(() => token)
+            .then((token) => {
+              let config = {
+                headers: {
+                  Authorization: `Bearer `+token,
+                }
+              }
+              return getAxios().get(`/sso/account?token=${token}`, config)
+            })
             .then((response) => {
               commit("setUserdata", response.data);
               resolve(response);
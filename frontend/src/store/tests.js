@@ -4,6 +4,7 @@ export default {
     namespaced: true,
     state: {
         tests: [],
+        testsPage: {page: 1, perPage: 12, total: 0},
         results: [],
         test: {}
     },
@@ -11,6 +12,9 @@ export default {
         setTests(state, data) {
             state.tests = data
         },
+        setTestsPage(state, {page, per_page, total}) {
+            state.testsPage = {page: page, perPage: per_page, total: total}
+        },
         setResults(state, data) {
             state.results = data;
         },
@@ -48,12 +52,19 @@ export default {
                     })
             });
         },
-        getTests({commit}, withAnswers) {
+        getTests({commit, state}, {withAnswers, page} = {}) {
             return new Promise((resolve, reject) => {
                 getAxios()
-                    .get("/api/tests?withAnswers=" + withAnswers)
+                    .get("/api/tests", {
+                        params: {
+                            withAnswers: withAnswers,
+                            page: page || state.testsPage.page,
+                            per_page: state.testsPage.perPage,
+                        },
+                    })
                     .then((response) => {
-                        commit("setTests", response.data.payload)
+                        commit("setTests", response.data.payload.items)
+                        commit("setTestsPage", response.data.payload)
                         resolve(response)
                     })
                     .catch((error) => {
@@ -64,9 +75,9 @@ export default {
         getResults({commit}) {
             return new Promise((resolve, reject) => {
                 getAxios()
-                    .get("/api/tests/results")
+                    .get("/api/results", getAuthConfig())
                     .then((response) => {
-                        commit("setResults", response.data.payload)
+                        commit("setResults", response.data.payload.items)
                         resolve(response)
                     })
                     .catch((error) => {
@@ -109,6 +120,12 @@ export default {
         tests(state) {
             return state.tests;
         },
+        testsPage(state) {
+            return state.testsPage;
+        },
+        testsPagesCount(state) {
+            return Math.max(1, Math.ceil(state.testsPage.total / state.testsPage.perPage));
+        },
         results(state) {
             return state.results;
         },